@@ -8,10 +8,10 @@ import (
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/atlassian-labs/cyclops/pkg/cloudprovider/builder"
-	cyclopsmanager "github.com/atlassian-labs/cyclops/pkg/manager"
 	cnrTransitioner "github.com/atlassian-labs/cyclops/pkg/controller/cyclenoderequest/transitioner"
 	cnsTransitioner "github.com/atlassian-labs/cyclops/pkg/controller/cyclenodestatus/transitioner"
 	nodecontroller "github.com/atlassian-labs/cyclops/pkg/controller/node"
+	cyclopsmanager "github.com/atlassian-labs/cyclops/pkg/manager"
 	"github.com/atlassian-labs/cyclops/pkg/notifications"
 	"github.com/atlassian-labs/cyclops/pkg/notifications/notifierbuilder"
 	"github.com/operator-framework/operator-lib/leader"
@@ -22,6 +22,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 var (
@@ -38,25 +39,39 @@ var (
 	addr      = app.Flag("address", "Address to listen on for /metrics").Default(":8080").String()
 	namespace = app.Flag("namespace", "Namespace to watch for cycle request objects").Default("kube-system").String()
 
-	healthCheckTimeout = app.Flag("health-check-timeout", "Timeout on health checks performed").Default("5s").Duration()
+	enableWebhooks = app.Flag("webhooks", "Serve the admission webhooks validating and defaulting CycleNodeRequests and NodeGroups, and the conversion webhook between API versions").Default("false").Bool()
+	webhookPort    = app.Flag("webhook-port", "Port to serve the admission webhooks on").Default("9443").Int()
+	webhookCertDir = app.Flag("webhook-cert-dir", "Directory holding the tls.crt and tls.key used to serve the admission webhooks").Default("/tmp/k8s-webhook-server/serving-certs").String()
+
+	healthCheckTimeout            = app.Flag("health-check-timeout", "Timeout on health checks performed").Default("5s").Duration()
+	healthCheckJobServiceAccounts = app.Flag("health-check-job-service-accounts", "Service accounts Job health checks are allowed to run as. Can be repeated").Strings()
+	healthCheckCredentialSecrets  = app.Flag("health-check-credential-secrets", "Secrets in the watched namespace which checks are allowed to read request headers and bearer tokens from. Can be repeated").Strings()
 
 	deleteCNR                        = app.Flag("delete-cnr", "Whether or not to automatically delete CNRs").Default("false").Bool()
 	deleteCNRExpiry                  = app.Flag("delete-cnr-expiry", "Delete the CNR this long after it was created and is successful").Default("168h").Duration()
 	deleteCNRRequeue                 = app.Flag("delete-cnr-requeue", "How often to check if a CNR can be deleted").Default("24h").Duration()
 	defaultCNScyclingExpiry          = app.Flag("default-cns-cycling-expiry", "Fail the CNS if it has been cycling for this long").Default("3h").Duration()
-	unhealthyPodTerminationThreshold = app.Flag("unhealthy-pod-termination-after", "How long to tolerate an un-evictable yet unhealthy pod before forcefully removing it").Default("5m").Duration()
+	unhealthyPodTerminationThreshold = app.Flag("unhealthy-pod-termination-after", "How long to tolerate an un-evictable yet unhealthy pod before forcefully removing it, unless overridden by the unhealthyPodPolicy of the CycleNodeRequest").Default("5m").Duration()
 
 	cnrScaleUpWait              = app.Flag("cnr-scale-up-wait", "Minimum time to wait after scaling up before checking if replacement nodes are Ready").Default("1m").Duration()
 	cnrScaleUpLimit             = app.Flag("cnr-scale-up-limit", "Maximum total time to wait for replacement nodes to come up before failing the CNR").Default("20m").Duration()
 	cnrNodeEquilibriumWaitLimit = app.Flag("cnr-node-equilibrium-wait-limit", "Maximum time to wait for the kube-node-set and cloud-provider-instance-set to converge during the Initialised phase").Default("5m").Duration()
 	cnrTransitionDuration       = app.Flag("cnr-transition-duration", "RequeueAfter used when moving the CNR between phases").Default("10s").Duration()
 	cnrRequeueDuration          = app.Flag("cnr-requeue-duration", "RequeueAfter used while the CNR is waiting on an external condition within a phase").Default("30s").Duration()
-
-	cnsTransitionDuration        = app.Flag("cns-transition-duration", "RequeueAfter used when moving the CNS between phases").Default("10s").Duration()
-	cnsWaitingPodsRequeue        = app.Flag("cns-waiting-pods-requeue", "RequeueAfter used while waiting for pods on the cycling node to finish naturally (Method=Wait)").Default("60s").Duration()
-	cnsRemovingLabelsPodsRequeue = app.Flag("cns-removing-labels-pods-requeue", "RequeueAfter used while removing labels from pods on the cycling node").Default("1s").Duration()
-	cnsDrainingRetryRequeue      = app.Flag("cns-draining-retry-requeue", "RequeueAfter used when the apiserver returns 429 TooManyRequests (PDB-blocked) during drain").Default("15s").Duration()
-	cnsDrainingPodsRequeue       = app.Flag("cns-draining-pods-requeue", "RequeueAfter used while waiting for the in-flight drain to finish").Default("30s").Duration()
+	cnrCapacityWaitLimit        = app.Flag("cnr-capacity-wait-limit", "Maximum time to wait for enough cluster capacity before cordoning nodes when checkCapacity is enabled").Default("20m").Duration()
+	cnrGlobalConcurrency        = app.Flag("cnr-global-concurrency", "Maximum number of nodes being cycled at once across all CNRs in the watched namespace. 0 for no limit").Default("0").Int64()
+
+	cnsTransitionDuration          = app.Flag("cns-transition-duration", "RequeueAfter used when moving the CNS between phases").Default("10s").Duration()
+	cnsWaitingPodsRequeue          = app.Flag("cns-waiting-pods-requeue", "RequeueAfter used while waiting for pods on the cycling node to finish naturally (Method=Wait or do-not-drain pods)").Default("60s").Duration()
+	cnsDoNotDisruptTimeout         = app.Flag("cns-do-not-disrupt-timeout", "How long the Drain method waits for do-not-drain pods before draining the node anyway, unless the CNS sets doNotDisruptTimeout. 0 waits until the CNS times out").Default("1h").Duration()
+	cnsRemovingLabelsPodsRequeue   = app.Flag("cns-removing-labels-pods-requeue", "RequeueAfter used while removing labels from pods on the cycling node").Default("1s").Duration()
+	cnsDrainingRetryRequeue        = app.Flag("cns-draining-retry-requeue", "RequeueAfter used when the apiserver returns 429 TooManyRequests (PDB-blocked) during drain").Default("15s").Duration()
+	cnsDrainBlockedNotifyThreshold = app.Flag("cns-drain-blocked-notify-threshold", "Send a notification once a pod has refused eviction for this long. 0 disables the notification").Default("15m").Duration()
+	cnsDrainingPodsRequeue         = app.Flag("cns-draining-pods-requeue", "RequeueAfter used while waiting for the in-flight drain to finish").Default("30s").Duration()
+	cnsDeregisteringNodeRequeue    = app.Flag("cns-deregistering-node-requeue", "RequeueAfter used while waiting for the node to be removed from load balancers").Default("15s").Duration()
+	cnsVolumeDetachTimeout         = app.Flag("cns-volume-detach-timeout", "How long to wait for volumes to be detached from a deleted node before terminating the instance anyway").Default("5m").Duration()
+	cnsDetachingVolumesRequeue     = app.Flag("cns-detaching-volumes-requeue", "RequeueAfter used while waiting for volumes to be detached from the node").Default("10s").Duration()
+	cnsLifecycleHookRequeue        = app.Flag("cns-lifecycle-hook-requeue", "RequeueAfter used while retrying blocking lifecycle hooks which haven't succeeded").Default("15s").Duration()
 
 	nodeControllerReconcileConcurrency = app.Flag("node-controller-reconcile-concurrency", "Maximum number of concurrent node controller reconciles").Default("1").Int()
 	nodeControllerRequeueAfter         = app.Flag("node-controller-requeue-after", "How often the node controller rechecks annotated nodes that are still covered by an active CNR").Default("5m").Duration()
@@ -91,6 +106,10 @@ func main() {
 		Metrics: metricsserver.Options{
 			BindAddress: *addr,
 		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    *webhookPort,
+			CertDir: *webhookCertDir,
+		}),
 	})
 	if err != nil {
 		log.Error(err, "Unable to create a new manager")
@@ -123,29 +142,40 @@ func main() {
 		Notifier:      notifier,
 		Namespace:     *namespace,
 		CNROptions: cnrTransitioner.Options{
-			DeleteCNR:                *deleteCNR,
-			DeleteCNRExpiry:          *deleteCNRExpiry,
-			DeleteCNRRequeue:         *deleteCNRRequeue,
-			HealthCheckTimeout:       *healthCheckTimeout,
-			ScaleUpWait:              *cnrScaleUpWait,
-			ScaleUpLimit:             *cnrScaleUpLimit,
-			NodeEquilibriumWaitLimit: *cnrNodeEquilibriumWaitLimit,
-			TransitionDuration:       *cnrTransitionDuration,
-			RequeueDuration:          *cnrRequeueDuration,
+			DeleteCNR:                     *deleteCNR,
+			DeleteCNRExpiry:               *deleteCNRExpiry,
+			DeleteCNRRequeue:              *deleteCNRRequeue,
+			HealthCheckTimeout:            *healthCheckTimeout,
+			HealthCheckJobServiceAccounts: *healthCheckJobServiceAccounts,
+			HealthCheckCredentialSecrets:  *healthCheckCredentialSecrets,
+			ScaleUpWait:                   *cnrScaleUpWait,
+			ScaleUpLimit:                  *cnrScaleUpLimit,
+			NodeEquilibriumWaitLimit:      *cnrNodeEquilibriumWaitLimit,
+			TransitionDuration:            *cnrTransitionDuration,
+			RequeueDuration:               *cnrRequeueDuration,
+			CapacityWaitLimit:             *cnrCapacityWaitLimit,
+			GlobalConcurrency:             *cnrGlobalConcurrency,
 		},
 		CNSOptions: cnsTransitioner.Options{
 			DefaultCNScyclingExpiry:          *defaultCNScyclingExpiry,
 			UnhealthyPodTerminationThreshold: *unhealthyPodTerminationThreshold,
 			TransitionDuration:               *cnsTransitionDuration,
 			WaitingPodsRequeue:               *cnsWaitingPodsRequeue,
+			DoNotDisruptTimeout:              *cnsDoNotDisruptTimeout,
 			RemovingLabelsPodsRequeue:        *cnsRemovingLabelsPodsRequeue,
 			DrainingRetryRequeue:             *cnsDrainingRetryRequeue,
 			DrainingPodsRequeue:              *cnsDrainingPodsRequeue,
+			DrainBlockedNotifyThreshold:      *cnsDrainBlockedNotifyThreshold,
+			DeregisteringNodeRequeue:         *cnsDeregisteringNodeRequeue,
+			VolumeDetachTimeout:              *cnsVolumeDetachTimeout,
+			DetachingVolumesRequeue:          *cnsDetachingVolumesRequeue,
+			LifecycleHookRequeue:             *cnsLifecycleHookRequeue,
 		},
 		NodeOptions: nodecontroller.Options{
 			ReconcileConcurrency: *nodeControllerReconcileConcurrency,
 			RequeueAfter:         *nodeControllerRequeueAfter,
 		},
+		EnableWebhooks: *enableWebhooks,
 	}); err != nil {
 		log.Error(err, "Manager exited non-zero")
 		os.Exit(1)
@@ -2,6 +2,7 @@ package v1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // CycleNodeRequestMethod is the method to use when cycling nodes.
@@ -17,6 +18,80 @@ const (
 	CycleNodeRequestMethodWait = "Wait"
 )
 
+// UnhealthyPodPolicyMode is when unhealthy pods refusing eviction are forcibly deleted
+type UnhealthyPodPolicyMode string
+
+const (
+	// UnhealthyPodPolicyAlways forcibly deletes any pod refusing eviction which has been unhealthy for longer
+	// than the unhealthyAfter threshold. This is the default mode.
+	UnhealthyPodPolicyAlways UnhealthyPodPolicyMode = "Always"
+
+	// UnhealthyPodPolicyNever never forcibly deletes pods from the node, unhealthy pods block the drain like healthy
+	// pods. The drainBlockedTimeout and podEvictionTimeout don't forcibly delete pods either.
+	UnhealthyPodPolicyNever UnhealthyPodPolicyMode = "Never"
+
+	// UnhealthyPodPolicySelected only forcibly deletes unhealthy pods in the selected namespaces or matching
+	// the selector.
+	UnhealthyPodPolicySelected UnhealthyPodPolicyMode = "Selected"
+)
+
+// LifecycleHookEvent is the point in cycling a node at which a lifecycle hook is called
+type LifecycleHookEvent string
+
+const (
+	// LifecycleHookPreCordon is called before a node is cordoned
+	LifecycleHookPreCordon LifecycleHookEvent = "PreCordon"
+
+	// LifecycleHookPostDrain is called after the pods have been drained from a node
+	LifecycleHookPostDrain LifecycleHookEvent = "PostDrain"
+
+	// LifecycleHookPostTerminate is called after the instance of a node has been terminated
+	LifecycleHookPostTerminate LifecycleHookEvent = "PostTerminate"
+)
+
+// HealthCheckType is the kind of probe performed by a health check
+type HealthCheckType string
+
+const (
+	// HealthCheckHTTP makes a http request to the endpoint of the health check
+	HealthCheckHTTP HealthCheckType = "HTTP"
+
+	// HealthCheckJob runs a Kubernetes Job on the node and passes when the Job succeeds
+	HealthCheckJob HealthCheckType = "Job"
+
+	// HealthCheckGRPC calls the gRPC health checking protocol of the endpoint and passes when it is serving
+	HealthCheckGRPC HealthCheckType = "GRPC"
+
+	// HealthCheckTCP passes when a tcp connection can be opened to the endpoint
+	HealthCheckTCP HealthCheckType = "TCP"
+
+	// HealthCheckNodeReadiness checks the state of the node in Kubernetes instead of calling an endpoint
+	HealthCheckNodeReadiness HealthCheckType = "NodeReadiness"
+
+	// HealthCheckPrometheus evaluates a PromQL query and passes when the result meets a threshold
+	HealthCheckPrometheus HealthCheckType = "Prometheus"
+)
+
+// PrometheusOperator compares the result of a Prometheus health check query to its threshold
+type PrometheusOperator string
+
+const (
+	// PrometheusLessThan passes when every sample is less than the threshold
+	PrometheusLessThan PrometheusOperator = "LessThan"
+
+	// PrometheusLessThanOrEqual passes when every sample is less than or equal to the threshold
+	PrometheusLessThanOrEqual PrometheusOperator = "LessThanOrEqual"
+
+	// PrometheusGreaterThan passes when every sample is greater than the threshold
+	PrometheusGreaterThan PrometheusOperator = "GreaterThan"
+
+	// PrometheusGreaterThanOrEqual passes when every sample is greater than or equal to the threshold
+	PrometheusGreaterThanOrEqual PrometheusOperator = "GreaterThanOrEqual"
+
+	// PrometheusEqual passes when every sample is equal to the threshold
+	PrometheusEqual PrometheusOperator = "Equal"
+)
+
 // CycleSettings are configuration options to control how nodes are cycled
 // +k8s:openapi-gen=true
 type CycleSettings struct {
@@ -46,13 +121,184 @@ type CycleSettings struct {
 	// in-progress CNS request timeout from the time it's worked on by the controller.
 	// If no cyclingTimeout is provided, CNS will use the default controller CNS cyclingTimeout.
 	CyclingTimeout *metav1.Duration `json:"cyclingTimeout,omitempty"`
+
+	// MaxFailedNodes is the number or percentage of nodes that are allowed to fail cycling
+	// before the CycleNodeRequest is failed. Nodes which fail within this budget are returned
+	// to service and skipped. Percentages are of the total number of nodes to terminate and are
+	// rounded down. Defaults to 0, failing the CycleNodeRequest on the first failed node.
+	// +kubebuilder:validation:XIntOrString
+	MaxFailedNodes *intstr.IntOrString `json:"maxFailedNodes,omitempty"`
+
+	// RetryPolicy configures retrying nodes which fail to cycle due to a transient error
+	// while draining or terminating. By default failed nodes are not retried.
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// CheckCapacity enables checking that the remaining schedulable nodes have enough allocatable
+	// cpu, memory and pods for the pods on a batch of nodes before cordoning them. If there isn't
+	// enough capacity the CycleNodeRequest waits rather than draining the nodes.
+	CheckCapacity bool `json:"checkCapacity,omitempty"`
+
+	// DrainBlockedTimeout is how long a pod may refuse eviction, usually because of a
+	// PodDisruptionBudget, before it is deleted from the node bypassing the PodDisruptionBudget.
+	// Only used by the Drain method. By default blocked pods are never deleted. Ignored if the
+	// unhealthyPodPolicy mode is Never.
+	DrainBlockedTimeout *metav1.Duration `json:"drainBlockedTimeout,omitempty"`
+
+	// DoNotDisruptTimeout is how long the Drain method waits for pods with the
+	// "cyclops.atlassian.com/do-not-drain=true" annotation to finish, or for the annotation to be
+	// removed, before draining the node anyway. Defaults to the controller's --cns-do-not-disrupt-timeout.
+	// 0 waits until the CycleNodeStatus reaches its cyclingTimeout.
+	DoNotDisruptTimeout *metav1.Duration `json:"doNotDisruptTimeout,omitempty"`
+
+	// DrainOrder configures evicting the pods on a node in waves rather than all at once. Each wave
+	// is only evicted once the pods in the previous waves have left the node. Only used by the Drain
+	// method. By default all pods are evicted at once.
+	DrainOrder *DrainOrder `json:"drainOrder,omitempty"`
+
+	// EvictionOptions configures how pods are evicted from nodes. Only used by the Drain method. By default
+	// pods are evicted using their own termination grace period.
+	EvictionOptions *EvictionOptions `json:"evictionOptions,omitempty"`
+
+	// UnhealthyPodPolicy controls when pods which refuse eviction and have been unhealthy for a while are
+	// forcibly deleted from the node. Only used by the Drain method. By default any pod which has been
+	// unhealthy for longer than the controller's --unhealthy-pod-termination-after is forcibly deleted.
+	UnhealthyPodPolicy *UnhealthyPodPolicy `json:"unhealthyPodPolicy,omitempty"`
+
+	// LifecycleHooks are http endpoints called before a node is cordoned, after it is drained and after
+	// its instance is terminated, e.g. to notify a service registry or CMDB.
+	LifecycleHooks []LifecycleHook `json:"lifecycleHooks,omitempty"`
+
+	// DrainTaint is a taint added to nodes when they are cordoned, before they are drained. This lets
+	// controllers and pods with matching tolerations react to the node being cycled. The taint is
+	// removed if the node is returned to service.
+	DrainTaint *DrainTaint `json:"drainTaint,omitempty"`
+
+	// VolumeDetachTimeout is how long to wait for the volumes attached to a node to be detached after it
+	// is deleted, before terminating the instance anyway. Defaults to the controller's
+	// --cns-volume-detach-timeout.
+	VolumeDetachTimeout *metav1.Duration `json:"volumeDetachTimeout,omitempty"`
+
+	// LoadBalancerDeregistration excludes nodes from service load balancers after they are drained and
+	// waits for connections to drain before the node is deleted and the instance terminated. By default
+	// nodes are not excluded from load balancers before being terminated.
+	LoadBalancerDeregistration *LoadBalancerDeregistration `json:"loadBalancerDeregistration,omitempty"`
+}
+
+// LoadBalancerDeregistration defines how to wait for a node to be removed from service load balancers
+// +k8s:openapi-gen=true
+type LoadBalancerDeregistration struct {
+	// DrainingDelay is how long to wait after excluding the node from load balancers for in-flight
+	// connections to finish.
+	DrainingDelay *metav1.Duration `json:"drainingDelay,omitempty"`
+
+	// WaitForTargets waits until the cloud provider reports the instance has been deregistered from the
+	// load balancers it was registered with, e.g. AWS target groups. Waits for the DrainingDelay as well.
+	WaitForTargets bool `json:"waitForTargets,omitempty"`
+
+	// Timeout is how long to wait for the instance to be deregistered before terminating it anyway.
+	// Defaults to 10m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// DrainTaint defines a taint to add to nodes before draining them
+// +k8s:openapi-gen=true
+type DrainTaint struct {
+	// Key is the taint key, e.g. cyclops.atlassian.com/cycling
+	Key string `json:"key"`
+
+	// Value is the taint value
+	Value string `json:"value,omitempty"`
+
+	// Effect is the taint effect. With NoExecute the node is only tainted NoSchedule while it is drained, the
+	// NoExecute taint is added once the drain has finished to evict the pods left which don't tolerate it.
+	// +kubebuilder:validation:Enum=NoSchedule;NoExecute
+	Effect string `json:"effect"`
+}
+
+// EvictionOptions defines the delete options used when evicting pods from a node
+// +k8s:openapi-gen=true
+type EvictionOptions struct {
+	// GracePeriodSeconds overrides the terminationGracePeriodSeconds of the pods being evicted. Shorter
+	// grace periods speed up cycling, longer ones give pods more time to shut down than their spec.
+	// +kubebuilder:validation:Minimum=0
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+
+	// PodEvictionTimeout is how long an evicted pod may take to terminate before it is forcibly deleted
+	// from the node. By default evicted pods are waited for until the cyclingTimeout. Ignored if the
+	// unhealthyPodPolicy mode is Never.
+	PodEvictionTimeout *metav1.Duration `json:"podEvictionTimeout,omitempty"`
+
+	// PropagationPolicy is how the garbage collector deletes the dependents of the evicted pods.
+	// +kubebuilder:validation:Enum=Orphan;Background;Foreground
+	PropagationPolicy string `json:"propagationPolicy,omitempty"`
+}
+
+// UnhealthyPodPolicy defines when unhealthy pods refusing eviction are forcibly deleted from a node
+// +k8s:openapi-gen=true
+type UnhealthyPodPolicy struct {
+	// Mode is when unhealthy pods are forcibly deleted: Always, Never or Selected. Defaults to Always.
+	// +optional
+	// +kubebuilder:default=Always
+	// +kubebuilder:validation:Enum=Always;Never;Selected
+	Mode UnhealthyPodPolicyMode `json:"mode,omitempty"`
+
+	// UnhealthyAfter is how long a pod must have been unhealthy before it can be forcibly deleted.
+	// Defaults to the controller's --unhealthy-pod-termination-after.
+	UnhealthyAfter *metav1.Duration `json:"unhealthyAfter,omitempty"`
+
+	// Namespaces are the namespaces of the pods which can be forcibly deleted with the Selected mode.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Selector selects the pods which can be forcibly deleted with the Selected mode.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// DrainOrder defines the order pods are evicted from a node in
+// +k8s:openapi-gen=true
+type DrainOrder struct {
+	// Waves are label selectors for pods to evict after all other pods have left the node, in the
+	// order they are listed. Pods are placed in the first wave they match.
+	Waves []metav1.LabelSelector `json:"waves,omitempty"`
+
+	// ByPriority evicts pods in order of ascending priority, so pods with the highest PriorityClass
+	// are evicted last. When used with Waves, pods are ordered by priority within each wave.
+	ByPriority bool `json:"byPriority,omitempty"`
+}
+
+// RetryPolicy defines how cycling a node is retried after it fails due to a transient error
+// +k8s:openapi-gen=true
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times cycling a node is attempted, including the first attempt.
+	MaxAttempts int64 `json:"maxAttempts"`
+
+	// Backoff is the time to wait before retrying a node for the first time. The wait doubles
+	// with each subsequent attempt. Defaults to retrying immediately.
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
 }
 
 // HealthCheck defines the health check configuration for the NodeGroup
 // +k8s:openapi-gen=true
 type HealthCheck struct {
+	// Type is the kind of health check: HTTP, Job, GRPC, TCP, NodeReadiness or Prometheus. Defaults to HTTP.
+	// +kubebuilder:validation:Enum=HTTP;Job;GRPC;TCP;NodeReadiness;Prometheus
+	Type HealthCheckType `json:"type,omitempty"`
+
 	// Endpoint url of the health check. Optional: {{ .NodeIP }} gets replaced by the private IP of the node being scaled up.
-	Endpoint string `json:"endpoint"`
+	// Required for HTTP, GRPC, TCP and Prometheus health checks. GRPC and TCP health checks take a host:port to connect to,
+	// Prometheus health checks take the url of a Prometheus compatible api, e.g. http://prometheus.monitoring:9090.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// GRPCService is the name of the service checked by GRPC health checks. Defaults to the overall health of the server.
+	GRPCService string `json:"grpcService,omitempty"`
+
+	// Job is the Kubernetes Job run on the node by Job health checks.
+	Job *JobHealthCheck `json:"job,omitempty"`
+
+	// NodeReadiness is the state the node must reach for NodeReadiness health checks to pass.
+	NodeReadiness *NodeReadinessHealthCheck `json:"nodeReadiness,omitempty"`
+
+	// Prometheus is the query evaluated by Prometheus health checks.
+	Prometheus *PrometheusHealthCheck `json:"prometheus,omitempty"`
 
 	// WaitPeriod is the time allowed for the health check to pass before considering the
 	// service unhealthy and failing the CycleNodeRequest.
@@ -65,11 +311,87 @@ type HealthCheck struct {
 	// RegexMatch specifies a regex string the body of the http result to should. By default no matching is done.
 	RegexMatch string `json:"regexMatch,omitempty"`
 
+	// JSONPathMatch asserts on a value in the json body of the http result. By default no matching is done.
+	JSONPathMatch *JSONPathAssertion `json:"jsonPathMatch,omitempty"`
+
+	// Request configures the method, headers, body and auth of the http request. Defaults to a GET request.
+	HTTPRequest `json:"request,omitempty"`
+
 	// TLS configuration for the http client to make requests. Can either make standard https requests
-	// or optionally forward certs signed by the root CA for mTLS.
+	// or optionally forward certs signed by the root CA for mTLS. GRPC and TCP health checks only use tls
+	// when it is configured.
 	TLSConfig `json:"tls,omitempty"`
 }
 
+// JobHealthCheck defines a Kubernetes Job run on a node to check it is healthy. The Job is created in the namespace
+// of the CycleNodeRequest, is pinned to the node and tolerates all of its taints. The health check passes when the
+// Job succeeds.
+// +k8s:openapi-gen=true
+type JobHealthCheck struct {
+	// Image is the container image run by the Job.
+	Image string `json:"image"`
+
+	// Command is the entrypoint of the container. Defaults to the entrypoint of the image.
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments to the entrypoint.
+	Args []string `json:"args,omitempty"`
+
+	// ServiceAccountName is the service account the Job runs as, which must be one the manager allows.
+	// Jobs without one run without a service account token.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// BackoffLimit is the number of times the Job is retried before the health check fails. Defaults to 0.
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// NodeReadinessHealthCheck defines the state a new node must reach before it is considered healthy. All of the
+// configured requirements must be met for the health check to pass.
+// +k8s:openapi-gen=true
+type NodeReadinessHealthCheck struct {
+	// DaemonSetPods waits until every DaemonSet which should run on the node has a Ready pod on it.
+	DaemonSetPods bool `json:"daemonSetPods,omitempty"`
+
+	// Conditions are the node conditions which must have the given status, e.g. NetworkUnavailable=False.
+	Conditions []NodeConditionRequirement `json:"conditions,omitempty"`
+
+	// Labels must be present on the node. An empty value only requires the label to be present.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// AbsentTaints are the keys of taints which must have been removed from the node, e.g. a CNI not ready taint.
+	AbsentTaints []string `json:"absentTaints,omitempty"`
+}
+
+// PrometheusHealthCheck defines a PromQL query evaluated against the endpoint of the health check. The health check
+// passes when every sample returned by the query compares to the threshold with the operator.
+// +k8s:openapi-gen=true
+type PrometheusHealthCheck struct {
+	// Query is the PromQL expression to evaluate. Optional: {{ .NodeName }} and {{ .NodeIP }} get replaced by the name
+	// and private IP of the node being checked.
+	Query string `json:"query"`
+
+	// Operator compares each sample returned by the query to the threshold.
+	// +kubebuilder:validation:Enum=LessThan;LessThanOrEqual;GreaterThan;GreaterThanOrEqual;Equal
+	Operator PrometheusOperator `json:"operator"`
+
+	// Threshold is the decimal value the samples are compared to, e.g. "0.01".
+	Threshold string `json:"threshold"`
+
+	// AllowEmptyResult passes the health check when the query returns no samples. By default an empty result fails.
+	AllowEmptyResult bool `json:"allowEmptyResult,omitempty"`
+}
+
+// NodeConditionRequirement is a node condition which must have the given status
+// +k8s:openapi-gen=true
+type NodeConditionRequirement struct {
+	// Type of the node condition.
+	Type string `json:"type"`
+
+	// Status the node condition must have: True, False or Unknown.
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status string `json:"status"`
+}
+
 // PreTerminationCheck defines the configuration for the check done before terminating an instance. The trigger can be
 // considered a http sigterm and the subsequent check to know when the process has completed it's triggered action.
 // +k8s:openapi-gen=true
@@ -85,11 +407,115 @@ type PreTerminationCheck struct {
 	// exact same way as health check on new nodes.
 	HealthCheck `json:"healthCheck"`
 
+	// Request configures the method, headers, body and auth of the trigger request. Defaults to a POST request.
+	HTTPRequest `json:"request,omitempty"`
+
+	// TLS configuration for the http client to make requests. Can either make standard https requests
+	// or optionally forward certs signed by the root CA for mTLS.
+	TLSConfig `json:"tls,omitempty"`
+}
+
+// LifecycleHook defines a http endpoint which is called at a point in cycling each node. The endpoint is sent a
+// POST request with a json body describing the event and the node.
+// +k8s:openapi-gen=true
+type LifecycleHook struct {
+	// Name identifies the hook. It must be unique within the lifecycle hooks.
+	Name string `json:"name"`
+
+	// Event is when the hook is called: PreCordon, PostDrain or PostTerminate.
+	// +kubebuilder:validation:Enum=PreCordon;PostDrain;PostTerminate
+	Event LifecycleHookEvent `json:"event"`
+
+	// Endpoint url of the hook. Optional: {{ .NodeIP }} gets replaced by the private IP of the node being cycled.
+	Endpoint string `json:"endpoint"`
+
+	// ValidStatusCodes keeps track of the list of possible status codes returned by
+	// the endpoint denoting the hook succeeded. Defaults to [200].
+	ValidStatusCodes []uint `json:"validStatusCodes,omitempty"`
+
+	// Blocking waits for the hook to succeed before cycling the node any further, retrying it until the
+	// timeout. By default the hook is only called once and a failure is reported without stopping cycling.
+	Blocking bool `json:"blocking,omitempty"`
+
+	// Timeout is how long to retry a blocking hook before failing to cycle the node. Defaults to 10m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
 	// TLS configuration for the http client to make requests. Can either make standard https requests
 	// or optionally forward certs signed by the root CA for mTLS.
 	TLSConfig `json:"tls,omitempty"`
 }
 
+// LifecycleHookStatus keeps track of calling a lifecycle hook for a node
+// +k8s:openapi-gen=true
+type LifecycleHookStatus struct {
+	// Name is the name of the hook
+	Name string `json:"name"`
+
+	// Attempts is the number of times the hook has been called
+	Attempts int64 `json:"attempts,omitempty"`
+
+	// FirstAttempt is when the hook was first called
+	FirstAttempt *metav1.Time `json:"firstAttempt,omitempty"`
+
+	// Succeeded is when the hook returned a valid status code
+	Succeeded *metav1.Time `json:"succeeded,omitempty"`
+
+	// Message describes the last failure of the hook
+	Message string `json:"message,omitempty"`
+}
+
+// HTTPRequest configures the http request made by a check
+// +k8s:openapi-gen=true
+type HTTPRequest struct {
+	// Method is the http method of the request.
+	// +kubebuilder:validation:Enum=GET;HEAD;POST;PUT;PATCH;DELETE
+	Method string `json:"method,omitempty"`
+
+	// Headers are added to the request.
+	Headers []HTTPHeader `json:"headers,omitempty"`
+
+	// Body is sent with the request. Optional: {{ .NodeName }} and {{ .NodeIP }} get replaced by the name and
+	// private IP of the node being checked.
+	Body string `json:"body,omitempty"`
+
+	// BearerTokenSecretRef references a token which is sent in the Authorization header of the request.
+	BearerTokenSecretRef *SecretKeyReference `json:"bearerTokenSecretRef,omitempty"`
+}
+
+// HTTPHeader is a header added to a http request. The value is either set inline or read from a Secret.
+// +k8s:openapi-gen=true
+type HTTPHeader struct {
+	// Name of the header.
+	Name string `json:"name"`
+
+	// Value of the header.
+	Value string `json:"value,omitempty"`
+
+	// ValueFrom references a Secret holding the value of the header. It takes precedence over Value.
+	ValueFrom *SecretKeyReference `json:"valueFrom,omitempty"`
+}
+
+// SecretKeyReference references a key of a Secret in the namespace of the CycleNodeRequest. The Secret is read
+// when the request is made.
+// +k8s:openapi-gen=true
+type SecretKeyReference struct {
+	// Name of the Secret.
+	Name string `json:"name"`
+
+	// Key in the Secret.
+	Key string `json:"key"`
+}
+
+// JSONPathAssertion asserts on a value in a json response body
+// +k8s:openapi-gen=true
+type JSONPathAssertion struct {
+	// Path is a JSONPath template evaluated against the body, e.g. {.status}.
+	Path string `json:"path"`
+
+	// Value is the expected result of the path. When empty the path only needs to be found in the body.
+	Value string `json:"value,omitempty"`
+}
+
 // TLSConfig defined the tls configuration for the http client to make a request.
 // +k8s:openapi-gen=true
 type TLSConfig struct {
@@ -103,6 +529,40 @@ type TLSConfig struct {
 	// Key is the private key which forms a pair with the certificate. It is
 	// sent as part of the request to the upstream host for mTLS.
 	Key string `json:"key,omitempty"`
+
+	// SecretRef references a Secret in the namespace of the CycleNodeRequest holding the tls material. It is
+	// read when the request is made and takes precedence over the environment variables.
+	SecretRef *TLSSecretReference `json:"secretRef,omitempty"`
+
+	// ConfigMapRef references a ConfigMap in the namespace of the CycleNodeRequest holding the root CA. It is
+	// read when the request is made and takes precedence over the environment variable and SecretRef.
+	ConfigMapRef *TLSConfigMapReference `json:"configMapRef,omitempty"`
+}
+
+// TLSSecretReference references the tls material in a Secret
+// +k8s:openapi-gen=true
+type TLSSecretReference struct {
+	// Name of the Secret.
+	Name string `json:"name"`
+
+	// RootCAKey is the key of the root CA in the Secret. Defaults to ca.crt.
+	RootCAKey string `json:"rootCAKey,omitempty"`
+
+	// CertificateKey is the key of the crt in the Secret. Defaults to tls.crt.
+	CertificateKey string `json:"crtKey,omitempty"`
+
+	// KeyKey is the key of the private key in the Secret. Defaults to tls.key.
+	KeyKey string `json:"keyKey,omitempty"`
+}
+
+// TLSConfigMapReference references a root CA in a ConfigMap
+// +k8s:openapi-gen=true
+type TLSConfigMapReference struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// RootCAKey is the key of the root CA in the ConfigMap. Defaults to ca.crt.
+	RootCAKey string `json:"rootCAKey,omitempty"`
 }
 
 // ValidationOptions stores the settings to use for validating state of nodegroups
@@ -112,4 +572,9 @@ type ValidationOptions struct {
 	// exist and be valid nodes before cycling can begin. If set to true named nodes which don't exist
 	// will be ignored rather than transitioning the CNR to the failed phase.
 	SkipMissingNamedNodes bool `json:"skipMissingNamedNodes,omitempty"`
+
+	// FailOnBlockingPodDisruptionBudgets is a boolean which determines whether cycling should fail before
+	// it begins if any PodDisruptionBudgets covering pods on the selected nodes can never allow a pod to be
+	// evicted. By default these PodDisruptionBudgets are only reported in events.
+	FailOnBlockingPodDisruptionBudgets bool `json:"failOnBlockingPodDisruptionBudgets,omitempty"`
 }
@@ -0,0 +1,12 @@
+package v1
+
+// v1 is the storage version and the hub the other API versions are converted to and from
+
+// Hub marks CycleNodeRequest as a conversion hub
+func (*CycleNodeRequest) Hub() {}
+
+// Hub marks CycleNodeStatus as a conversion hub
+func (*CycleNodeStatus) Hub() {}
+
+// Hub marks NodeGroup as a conversion hub
+func (*NodeGroup) Hub() {}
@@ -1,10 +1,19 @@
 package v1
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// CycleNodeRequestRetryAnnotation is set on a Failed CycleNodeRequest to ask the controller
+// to resume cycling the nodes which have not yet been cycled.
+const CycleNodeRequestRetryAnnotation = "cyclops.atlassian.com/retry"
+
 // NodeLabelSelector converts a metav1.LabelSelector to a labels.Selector
 func (in *CycleNodeRequest) NodeLabelSelector() (labels.Selector, error) {
 	return metaV1.LabelSelectorAsSelector(&in.Spec.Selector)
@@ -39,5 +48,63 @@ func (in *CycleNodeRequest) IsFromSameNodeGroup(cnr CycleNodeRequest) bool {
 
 // IsTerminal returns true when the CycleNodeRequest lifecycle has ended.
 func (in *CycleNodeRequest) IsTerminal() bool {
-	return in.Status.Phase == CycleNodeRequestSuccessful || in.Status.Phase == CycleNodeRequestFailed
+	return in.IsSuccessful() || in.Status.Phase == CycleNodeRequestFailed
+}
+
+// IsSuccessful returns true when the CycleNodeRequest finished cycling, including when
+// some nodes were skipped within the failure budget.
+func (in *CycleNodeRequest) IsSuccessful() bool {
+	return in.Status.Phase == CycleNodeRequestSuccessful || in.Status.Phase == CycleNodeRequestPartiallySuccessful
+}
+
+// PendingHealthChecks describes each health check which has been attempted against a node but hasn't passed
+// yet, one line per check ordered by node. It explains why cycling is waiting on, or failed on, health checks.
+func (in *CycleNodeRequest) PendingHealthChecks() []string {
+	nodes := make([]string, 0, len(in.Status.HealthChecks))
+	for node := range in.Status.HealthChecks {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var pending []string
+	for _, node := range nodes {
+		for i, result := range in.Status.HealthChecks[node].Results {
+			if result.Passed != nil || result.Attempts == 0 || i >= len(in.Spec.HealthChecks) {
+				continue
+			}
+
+			pending = append(pending, fmt.Sprintf("%s %s: %s", node, in.Spec.HealthChecks[i].Name(), result.Summary()))
+		}
+	}
+
+	return pending
+}
+
+// Name returns how the health check is referred to in summaries, which is the endpoint for the health
+// check types which have one and the type otherwise
+func (in HealthCheck) Name() string {
+	if in.Endpoint != "" {
+		return in.Endpoint
+	}
+	return string(in.Type)
+}
+
+// Summary describes the attempts recorded in the HealthCheckResult in a single line
+func (in HealthCheckResult) Summary() string {
+	parts := []string{fmt.Sprintf("%d attempts", in.Attempts)}
+
+	if in.Passed != nil {
+		parts = append(parts, fmt.Sprintf("passed at %s", in.Passed.UTC().Format(time.RFC3339)))
+	}
+	if in.LastStatusCode != 0 {
+		parts = append(parts, fmt.Sprintf("last status code %d", in.LastStatusCode))
+	}
+	if in.LastLatency != nil {
+		parts = append(parts, fmt.Sprintf("last latency %s", in.LastLatency.Duration))
+	}
+	if in.LastError != "" {
+		parts = append(parts, fmt.Sprintf("last error: %s", in.LastError))
+	}
+
+	return strings.Join(parts, ", ")
 }
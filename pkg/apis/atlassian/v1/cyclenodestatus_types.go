@@ -10,6 +10,10 @@ type CycleNodeStatusSpec struct {
 	// NodeName is the name of the node object in Kubernetes that will be drained and terminated.
 	NodeName string `json:"nodeName"`
 
+	// ProviderID is the cloud provider ID of the node. It is only set when retrying a node, so the
+	// instance can still be terminated if the node has already been removed from Kubernetes.
+	ProviderID string `json:"providerId,omitempty"`
+
 	// CycleSettings stores the settings to use for cycling the node.
 	CycleSettings CycleSettings `json:"cycleSettings"`
 }
@@ -31,6 +35,77 @@ type CycleNodeStatusStatus struct {
 
 	// TimeoutTimestamp stores the timestamp of when this CNS will timeout
 	TimeoutTimestamp *metav1.Time `json:"timeoutTimestamp,omitempty"`
+
+	// FailedPhase stores the phase the CycleNodeStatus was in when it failed
+	FailedPhase CycleNodeStatusPhase `json:"failedPhase,omitempty"`
+
+	// Retryable denotes that the CycleNodeStatus failed due to a transient error and cycling
+	// the node can be retried
+	Retryable bool `json:"retryable,omitempty"`
+
+	// WaitingPodsStarted stores when the CycleNodeStatus started waiting for pods on the node to finish
+	WaitingPodsStarted *metav1.Time `json:"waitingPodsStarted,omitempty"`
+
+	// BlockedPods stores the pods which are currently refusing eviction while draining the node
+	BlockedPods []BlockedPod `json:"blockedPods,omitempty"`
+
+	// ForceDeletedPods stores the pods which were forcibly deleted from the node while draining it
+	ForceDeletedPods []ForceDeletedPod `json:"forceDeletedPods,omitempty"`
+
+	// LifecycleHooks keeps track of the PostDrain and PostTerminate lifecycle hooks called for the node
+	LifecycleHooks []LifecycleHookStatus `json:"lifecycleHooks,omitempty"`
+
+	// DrainBlockedNotified denotes that a notification has been sent about the pods blocking the drain
+	DrainBlockedNotified bool `json:"drainBlockedNotified,omitempty"`
+
+	// DeregisteringStarted stores when the node was excluded from service load balancers
+	DeregisteringStarted *metav1.Time `json:"deregisteringStarted,omitempty"`
+
+	// TargetsUnchecked denotes that waitForTargets is set but the cloud provider can't check whether the node has
+	// been deregistered from load balancers, so only the drainingDelay was waited for
+	TargetsUnchecked bool `json:"targetsUnchecked,omitempty"`
+
+	// DetachingVolumesStarted stores when the CycleNodeStatus started waiting for volumes to be detached
+	// from the node
+	DetachingVolumesStarted *metav1.Time `json:"detachingVolumesStarted,omitempty"`
+}
+
+// BlockedPod describes a pod which is refusing eviction while draining a node
+// +k8s:openapi-gen=true
+type BlockedPod struct {
+	// Name is the name of the pod
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the pod
+	Namespace string `json:"namespace"`
+
+	// PodDisruptionBudgets are the names of the PodDisruptionBudgets covering the pod
+	PodDisruptionBudgets []string `json:"podDisruptionBudgets,omitempty"`
+
+	// BlockedSince is when the pod first refused eviction
+	BlockedSince metav1.Time `json:"blockedSince"`
+
+	// BlockedFor is how long the pod had been refusing eviction at the last drain attempt
+	BlockedFor metav1.Duration `json:"blockedFor"`
+}
+
+// ForceDeletedPod describes a pod which was forcibly deleted from a node rather than evicted
+// +k8s:openapi-gen=true
+type ForceDeletedPod struct {
+	// Name is the name of the pod
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the pod
+	Namespace string `json:"namespace"`
+
+	// Reason is why the pod was forcibly deleted, e.g. Unhealthy
+	Reason string `json:"reason"`
+
+	// Message is a human readable description of why the pod was forcibly deleted
+	Message string `json:"message,omitempty"`
+
+	// DeletedAt is when the pod was forcibly deleted
+	DeletedAt metav1.Time `json:"deletedAt"`
 }
 
 // CycleNodeStatusPhase is the phase that the cycleNodeStatus is in
@@ -55,9 +130,16 @@ const (
 	// CycleNodeStatusDrainingPods is for cycleNodeStatuses that are draining pods from the node
 	CycleNodeStatusDrainingPods CycleNodeStatusPhase = "DrainingPods"
 
+	// CycleNodeStatusDeregisteringNode is for cycleNodeStatuses that are waiting for the node to be removed from
+	// service load balancers
+	CycleNodeStatusDeregisteringNode CycleNodeStatusPhase = "DeregisteringNode"
+
 	// CycleNodeStatusDeletingNode is for cycleNodeStatuses that are deleting the node out of the Kubernetes API
 	CycleNodeStatusDeletingNode CycleNodeStatusPhase = "DeletingNode"
 
+	// CycleNodeStatusDetachingVolumes is for cycleNodeStatuses that are waiting for volumes to be detached from the node
+	CycleNodeStatusDetachingVolumes CycleNodeStatusPhase = "DetachingVolumes"
+
 	// CycleNodeStatusTerminatingNode is for cyclenodeStatuses that are terminating the node from AWS
 	CycleNodeStatusTerminatingNode CycleNodeStatusPhase = "TerminatingNode"
 
@@ -70,6 +152,7 @@ const (
 // CycleNodeStatus is the Schema for the cyclenodestatus API
 // +k8s:openapi-gen=true
 // +kubebuilder:resource:path=cyclenodestatuses,shortName=cns,scope=Namespaced
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".status.currentNode.name",description="The name of the node"
 // +kubebuilder:printcolumn:name="Provider ID",type="string",JSONPath=".status.currentNode.providerId",description="The provider ID of the node"
 // +kubebuilder:printcolumn:name="Method",type="string",JSONPath=".spec.cycleSettings.method",description="The method being used for the cycle operation"
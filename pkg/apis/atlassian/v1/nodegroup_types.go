@@ -30,6 +30,10 @@ type NodeGroupSpec struct {
 	// Healthchecks stores the settings to configure instance custom health checks
 	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
 
+	// ClusterHealthChecks are checked before each batch of nodes is selected for cycling, rather than against a node.
+	// Cycling pauses while any of them fail and the CycleNodeRequest fails if they don't pass within their wait period.
+	ClusterHealthChecks []HealthCheck `json:"clusterHealthChecks,omitempty"`
+
 	// PreTerminationChecks stores the settings to configure instance pre-termination checks
 	PreTerminationChecks []PreTerminationCheck `json:"preTerminationChecks,omitempty"`
 
@@ -42,7 +46,7 @@ type NodeGroupSpec struct {
 
 	// Priority controls the ordering of CNR creation for this NodeGroup.
 	// Lower values are higher priority. Examples: -10 runs before 0; then 10, 20, ...
-    Priority int32 `json:"priority,omitempty"`
+	Priority int32 `json:"priority,omitempty"`
 }
 
 // NodeGroupStatus defines the observed state of NodeGroup
@@ -56,6 +60,7 @@ type NodeGroupStatus struct {
 // +k8s:openapi-gen=true
 // +genclient:nonNamespaced
 // +kubebuilder:resource:path=nodegroups,shortName=ng,scope=Cluster
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Node Group Name",type="string",JSONPath=".spec.nodeGroupName",description="The name of the node group in the cloud provider"
 // +kubebuilder:printcolumn:name="Method",type="string",JSONPath=".spec.cycleSettings.method",description="The method to use when cycling nodes"
 // +kubebuilder:printcolumn:name="Concurrency",type="integer",JSONPath=".spec.cycleSettings.concurrency",description="The number of nodes to cycle in parallel"
@@ -7,8 +7,73 @@ package v1
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockedPod) DeepCopyInto(out *BlockedPod) {
+	*out = *in
+	if in.PodDisruptionBudgets != nil {
+		in, out := &in.PodDisruptionBudgets, &out.PodDisruptionBudgets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.BlockedSince.DeepCopyInto(&out.BlockedSince)
+	out.BlockedFor = in.BlockedFor
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockedPod.
+func (in *BlockedPod) DeepCopy() *BlockedPod {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockedPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CycleNodeAttempt) DeepCopyInto(out *CycleNodeAttempt) {
+	*out = *in
+	in.FailedTimestamp.DeepCopyInto(&out.FailedTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CycleNodeAttempt.
+func (in *CycleNodeAttempt) DeepCopy() *CycleNodeAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(CycleNodeAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CycleNodeAttemptStatus) DeepCopyInto(out *CycleNodeAttemptStatus) {
+	*out = *in
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]CycleNodeAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextAttempt != nil {
+		in, out := &in.NextAttempt, &out.NextAttempt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CycleNodeAttemptStatus.
+func (in *CycleNodeAttemptStatus) DeepCopy() *CycleNodeAttemptStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CycleNodeAttemptStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CycleNodeRequest) DeepCopyInto(out *CycleNodeRequest) {
 	*out = *in
@@ -83,6 +148,22 @@ func (in *CycleNodeRequestNode) DeepCopy() *CycleNodeRequestNode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CycleNodeRequestSkippedNode) DeepCopyInto(out *CycleNodeRequestSkippedNode) {
+	*out = *in
+	out.CycleNodeRequestNode = in.CycleNodeRequestNode
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CycleNodeRequestSkippedNode.
+func (in *CycleNodeRequestSkippedNode) DeepCopy() *CycleNodeRequestSkippedNode {
+	if in == nil {
+		return nil
+	}
+	out := new(CycleNodeRequestSkippedNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CycleNodeRequestSpec) DeepCopyInto(out *CycleNodeRequestSpec) {
 	*out = *in
@@ -106,6 +187,13 @@ func (in *CycleNodeRequestSpec) DeepCopyInto(out *CycleNodeRequestSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ClusterHealthChecks != nil {
+		in, out := &in.ClusterHealthChecks, &out.ClusterHealthChecks
+		*out = make([]HealthCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PreTerminationChecks != nil {
 		in, out := &in.PreTerminationChecks, &out.PreTerminationChecks
 		*out = make([]PreTerminationCheck, len(*in))
@@ -146,6 +234,14 @@ func (in *CycleNodeRequestStatus) DeepCopyInto(out *CycleNodeRequestStatus) {
 		in, out := &in.EquilibriumWaitStarted, &out.EquilibriumWaitStarted
 		*out = (*in).DeepCopy()
 	}
+	if in.CapacityWaitStarted != nil {
+		in, out := &in.CapacityWaitStarted, &out.CapacityWaitStarted
+		*out = (*in).DeepCopy()
+	}
+	if in.ClusterHealthCheckStarted != nil {
+		in, out := &in.ClusterHealthCheckStarted, &out.ClusterHealthCheckStarted
+		*out = (*in).DeepCopy()
+	}
 	if in.SelectedNodes != nil {
 		in, out := &in.SelectedNodes, &out.SelectedNodes
 		*out = make(map[string]bool, len(*in))
@@ -172,11 +268,42 @@ func (in *CycleNodeRequestStatus) DeepCopyInto(out *CycleNodeRequestStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.PreCordonHooks != nil {
+		in, out := &in.PreCordonHooks, &out.PreCordonHooks
+		*out = make(map[string]LifecycleHookStatusList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.AnnotatedNodes != nil {
 		in, out := &in.AnnotatedNodes, &out.AnnotatedNodes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TaintedNodes != nil {
+		in, out := &in.TaintedNodes, &out.TaintedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedNodes != nil {
+		in, out := &in.SkippedNodes, &out.SkippedNodes
+		*out = make([]CycleNodeRequestSkippedNode, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeAttempts != nil {
+		in, out := &in.NodeAttempts, &out.NodeAttempts
+		*out = make(map[string]CycleNodeAttemptStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CycleNodeRequestStatus.
@@ -276,6 +403,39 @@ func (in *CycleNodeStatusStatus) DeepCopyInto(out *CycleNodeStatusStatus) {
 		in, out := &in.TimeoutTimestamp, &out.TimeoutTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.WaitingPodsStarted != nil {
+		in, out := &in.WaitingPodsStarted, &out.WaitingPodsStarted
+		*out = (*in).DeepCopy()
+	}
+	if in.BlockedPods != nil {
+		in, out := &in.BlockedPods, &out.BlockedPods
+		*out = make([]BlockedPod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ForceDeletedPods != nil {
+		in, out := &in.ForceDeletedPods, &out.ForceDeletedPods
+		*out = make([]ForceDeletedPod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LifecycleHooks != nil {
+		in, out := &in.LifecycleHooks, &out.LifecycleHooks
+		*out = make([]LifecycleHookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeregisteringStarted != nil {
+		in, out := &in.DeregisteringStarted, &out.DeregisteringStarted
+		*out = (*in).DeepCopy()
+	}
+	if in.DetachingVolumesStarted != nil {
+		in, out := &in.DetachingVolumesStarted, &out.DetachingVolumesStarted
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CycleNodeStatusStatus.
@@ -322,6 +482,63 @@ func (in *CycleSettings) DeepCopyInto(out *CycleSettings) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.MaxFailedNodes != nil {
+		in, out := &in.MaxFailedNodes, &out.MaxFailedNodes
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainBlockedTimeout != nil {
+		in, out := &in.DrainBlockedTimeout, &out.DrainBlockedTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DoNotDisruptTimeout != nil {
+		in, out := &in.DoNotDisruptTimeout, &out.DoNotDisruptTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DrainOrder != nil {
+		in, out := &in.DrainOrder, &out.DrainOrder
+		*out = new(DrainOrder)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictionOptions != nil {
+		in, out := &in.EvictionOptions, &out.EvictionOptions
+		*out = new(EvictionOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnhealthyPodPolicy != nil {
+		in, out := &in.UnhealthyPodPolicy, &out.UnhealthyPodPolicy
+		*out = new(UnhealthyPodPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LifecycleHooks != nil {
+		in, out := &in.LifecycleHooks, &out.LifecycleHooks
+		*out = make([]LifecycleHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DrainTaint != nil {
+		in, out := &in.DrainTaint, &out.DrainTaint
+		*out = new(DrainTaint)
+		**out = **in
+	}
+	if in.VolumeDetachTimeout != nil {
+		in, out := &in.VolumeDetachTimeout, &out.VolumeDetachTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LoadBalancerDeregistration != nil {
+		in, out := &in.LoadBalancerDeregistration, &out.LoadBalancerDeregistration
+		*out = new(LoadBalancerDeregistration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CycleSettings.
@@ -334,9 +551,149 @@ func (in *CycleSettings) DeepCopy() *CycleSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainOrder) DeepCopyInto(out *DrainOrder) {
+	*out = *in
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]metav1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainOrder.
+func (in *DrainOrder) DeepCopy() *DrainOrder {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainOrder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainTaint) DeepCopyInto(out *DrainTaint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainTaint.
+func (in *DrainTaint) DeepCopy() *DrainTaint {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainTaint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionOptions) DeepCopyInto(out *EvictionOptions) {
+	*out = *in
+	if in.GracePeriodSeconds != nil {
+		in, out := &in.GracePeriodSeconds, &out.GracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PodEvictionTimeout != nil {
+		in, out := &in.PodEvictionTimeout, &out.PodEvictionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionOptions.
+func (in *EvictionOptions) DeepCopy() *EvictionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForceDeletedPod) DeepCopyInto(out *ForceDeletedPod) {
+	*out = *in
+	in.DeletedAt.DeepCopyInto(&out.DeletedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForceDeletedPod.
+func (in *ForceDeletedPod) DeepCopy() *ForceDeletedPod {
+	if in == nil {
+		return nil
+	}
+	out := new(ForceDeletedPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPHeader) DeepCopyInto(out *HTTPHeader) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPHeader.
+func (in *HTTPHeader) DeepCopy() *HTTPHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPRequest) DeepCopyInto(out *HTTPRequest) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]HTTPHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BearerTokenSecretRef != nil {
+		in, out := &in.BearerTokenSecretRef, &out.BearerTokenSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPRequest.
+func (in *HTTPRequest) DeepCopy() *HTTPRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 	*out = *in
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeReadiness != nil {
+		in, out := &in.NodeReadiness, &out.NodeReadiness
+		*out = new(NodeReadinessHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(PrometheusHealthCheck)
+		**out = **in
+	}
 	if in.WaitPeriod != nil {
 		in, out := &in.WaitPeriod, &out.WaitPeriod
 		*out = new(metav1.Duration)
@@ -347,7 +704,13 @@ func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 		*out = make([]uint, len(*in))
 		copy(*out, *in)
 	}
-	out.TLSConfig = in.TLSConfig
+	if in.JSONPathMatch != nil {
+		in, out := &in.JSONPathMatch, &out.JSONPathMatch
+		*out = new(JSONPathAssertion)
+		**out = **in
+	}
+	in.HTTPRequest.DeepCopyInto(&out.HTTPRequest)
+	in.TLSConfig.DeepCopyInto(&out.TLSConfig)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
@@ -360,6 +723,34 @@ func (in *HealthCheck) DeepCopy() *HealthCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthCheckResult) DeepCopyInto(out *HealthCheckResult) {
+	*out = *in
+	if in.LastAttempt != nil {
+		in, out := &in.LastAttempt, &out.LastAttempt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastLatency != nil {
+		in, out := &in.LastLatency, &out.LastLatency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Passed != nil {
+		in, out := &in.Passed, &out.Passed
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckResult.
+func (in *HealthCheckResult) DeepCopy() *HealthCheckResult {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthCheckResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheckStatus) DeepCopyInto(out *HealthCheckStatus) {
 	*out = *in
@@ -372,6 +763,13 @@ func (in *HealthCheckStatus) DeepCopyInto(out *HealthCheckStatus) {
 		*out = make([]bool, len(*in))
 		copy(*out, *in)
 	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]HealthCheckResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheckStatus.
@@ -384,6 +782,162 @@ func (in *HealthCheckStatus) DeepCopy() *HealthCheckStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JSONPathAssertion) DeepCopyInto(out *JSONPathAssertion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JSONPathAssertion.
+func (in *JSONPathAssertion) DeepCopy() *JSONPathAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(JSONPathAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobHealthCheck) DeepCopyInto(out *JobHealthCheck) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobHealthCheck.
+func (in *JobHealthCheck) DeepCopy() *JobHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(JobHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleHook) DeepCopyInto(out *LifecycleHook) {
+	*out = *in
+	if in.ValidStatusCodes != nil {
+		in, out := &in.ValidStatusCodes, &out.ValidStatusCodes
+		*out = make([]uint, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	in.TLSConfig.DeepCopyInto(&out.TLSConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHook.
+func (in *LifecycleHook) DeepCopy() *LifecycleHook {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleHookStatus) DeepCopyInto(out *LifecycleHookStatus) {
+	*out = *in
+	if in.FirstAttempt != nil {
+		in, out := &in.FirstAttempt, &out.FirstAttempt
+		*out = (*in).DeepCopy()
+	}
+	if in.Succeeded != nil {
+		in, out := &in.Succeeded, &out.Succeeded
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHookStatus.
+func (in *LifecycleHookStatus) DeepCopy() *LifecycleHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleHookStatusList) DeepCopyInto(out *LifecycleHookStatusList) {
+	*out = *in
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]LifecycleHookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHookStatusList.
+func (in *LifecycleHookStatusList) DeepCopy() *LifecycleHookStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleHookStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerDeregistration) DeepCopyInto(out *LoadBalancerDeregistration) {
+	*out = *in
+	if in.DrainingDelay != nil {
+		in, out := &in.DrainingDelay, &out.DrainingDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerDeregistration.
+func (in *LoadBalancerDeregistration) DeepCopy() *LoadBalancerDeregistration {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerDeregistration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeConditionRequirement) DeepCopyInto(out *NodeConditionRequirement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeConditionRequirement.
+func (in *NodeConditionRequirement) DeepCopy() *NodeConditionRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeConditionRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeGroup) DeepCopyInto(out *NodeGroup) {
 	*out = *in
@@ -461,6 +1015,13 @@ func (in *NodeGroupSpec) DeepCopyInto(out *NodeGroupSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ClusterHealthChecks != nil {
+		in, out := &in.ClusterHealthChecks, &out.ClusterHealthChecks
+		*out = make([]HealthCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PreTerminationChecks != nil {
 		in, out := &in.PreTerminationChecks, &out.PreTerminationChecks
 		*out = make([]PreTerminationCheck, len(*in))
@@ -495,6 +1056,38 @@ func (in *NodeGroupStatus) DeepCopy() *NodeGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeReadinessHealthCheck) DeepCopyInto(out *NodeReadinessHealthCheck) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeConditionRequirement, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AbsentTaints != nil {
+		in, out := &in.AbsentTaints, &out.AbsentTaints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeReadinessHealthCheck.
+func (in *NodeReadinessHealthCheck) DeepCopy() *NodeReadinessHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeReadinessHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreTerminationCheck) DeepCopyInto(out *PreTerminationCheck) {
 	*out = *in
@@ -504,7 +1097,8 @@ func (in *PreTerminationCheck) DeepCopyInto(out *PreTerminationCheck) {
 		copy(*out, *in)
 	}
 	in.HealthCheck.DeepCopyInto(&out.HealthCheck)
-	out.TLSConfig = in.TLSConfig
+	in.HTTPRequest.DeepCopyInto(&out.HTTPRequest)
+	in.TLSConfig.DeepCopyInto(&out.TLSConfig)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreTerminationCheck.
@@ -558,9 +1152,69 @@ func (in *PreTerminationCheckStatusList) DeepCopy() *PreTerminationCheckStatusLi
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusHealthCheck) DeepCopyInto(out *PrometheusHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusHealthCheck.
+func (in *PrometheusHealthCheck) DeepCopy() *PrometheusHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(TLSSecretReference)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(TLSConfigMapReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
@@ -573,6 +1227,66 @@ func (in *TLSConfig) DeepCopy() *TLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfigMapReference) DeepCopyInto(out *TLSConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfigMapReference.
+func (in *TLSConfigMapReference) DeepCopy() *TLSConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSSecretReference) DeepCopyInto(out *TLSSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSSecretReference.
+func (in *TLSSecretReference) DeepCopy() *TLSSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyPodPolicy) DeepCopyInto(out *UnhealthyPodPolicy) {
+	*out = *in
+	if in.UnhealthyAfter != nil {
+		in, out := &in.UnhealthyAfter, &out.UnhealthyAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyPodPolicy.
+func (in *UnhealthyPodPolicy) DeepCopy() *UnhealthyPodPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyPodPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValidationOptions) DeepCopyInto(out *ValidationOptions) {
 	*out = *in
@@ -0,0 +1,991 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+// NodeGroupsConversionAnnotation preserves how the node groups of a v1 object were listed, when listing them the
+// same way can't be worked out from nodeGroupNames. It's only set on v2 objects, so converting v1 to v2 and back
+// to v1 is lossless. It's ignored if nodeGroupNames has been changed since.
+const NodeGroupsConversionAnnotation = "cyclops.atlassian.com/v1-node-groups"
+
+// v1NodeGroups is the value of the NodeGroupsConversionAnnotation
+type v1NodeGroups struct {
+	NodeGroupName  string   `json:"nodeGroupName"`
+	NodeGroupsList []string `json:"nodeGroupsList"`
+}
+
+// ConvertTo converts the CycleNodeRequest to the v1 hub
+func (in *CycleNodeRequest) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1.CycleNodeRequest)
+	if !ok {
+		return fmt.Errorf("expected a v1 CycleNodeRequest but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = in.ObjectMeta
+	dst.ClusterName = in.Spec.ClusterName
+
+	nodeGroupName, nodeGroupsList, err := nodeGroupsToV1(&dst.ObjectMeta.Annotations, in.Spec.NodeGroupNames)
+	if err != nil {
+		return err
+	}
+
+	dst.Spec = v1.CycleNodeRequestSpec{
+		NodeGroupName:            nodeGroupName,
+		NodeGroupsList:           nodeGroupsList,
+		Selector:                 in.Spec.Selector,
+		NodeNames:                in.Spec.NodeNames,
+		ValidationOptions:        v1.ValidationOptions(in.Spec.ValidationOptions),
+		HealthChecks:             convertSlice(in.Spec.HealthChecks, convertHealthCheckToV1),
+		ClusterHealthChecks:      convertSlice(in.Spec.ClusterHealthChecks, convertHealthCheckToV1),
+		PreTerminationChecks:     convertSlice(in.Spec.PreTerminationChecks, convertPreTerminationCheckToV1),
+		SkipInitialHealthChecks:  in.Spec.SkipInitialHealthChecks,
+		SkipPreTerminationChecks: in.Spec.SkipPreTerminationChecks,
+	}
+	convertCycleSettingsToV1(&in.Spec.CycleSettings, &dst.Spec.CycleSettings)
+
+	dst.Status = v1.CycleNodeRequestStatus{
+		Phase:                     v1.CycleNodeRequestPhase(in.Status.Phase),
+		Message:                   in.Status.Message,
+		CurrentNodes:              convertSlice(in.Status.CurrentNodes, convertCycleNodeRequestNodeToV1),
+		NodesToTerminate:          convertSlice(in.Status.NodesToTerminate, convertCycleNodeRequestNodeToV1),
+		ScaleUpStarted:            in.Status.ScaleUpStarted,
+		EquilibriumWaitStarted:    in.Status.EquilibriumWaitStarted,
+		CapacityWaitStarted:       in.Status.CapacityWaitStarted,
+		ClusterHealthCheckStarted: in.Status.ClusterHealthCheckStarted,
+		ActiveChildren:            in.Status.ActiveChildren,
+		ThreadTimestamp:           in.Status.ThreadTimestamp,
+		SelectedNodes:             in.Status.SelectedNodes,
+		NumNodesCycled:            in.Status.NumNodesCycled,
+		NodesAvailable:            convertSlice(in.Status.NodesAvailable, convertCycleNodeRequestNodeToV1),
+		HealthChecks:              convertMap(in.Status.HealthChecks, convertHealthCheckStatusToV1),
+		PreTerminationChecks:      convertMap(in.Status.PreTerminationChecks, convertPreTerminationCheckStatusListToV1),
+		PreCordonHooks:            convertMap(in.Status.PreCordonHooks, convertLifecycleHookStatusListToV1),
+		AnnotatedNodes:            in.Status.AnnotatedNodes,
+		TaintedNodes:              in.Status.TaintedNodes,
+		SkippedNodes:              convertSlice(in.Status.SkippedNodes, convertCycleNodeRequestSkippedNodeToV1),
+		NodeAttempts:              convertMap(in.Status.NodeAttempts, convertCycleNodeAttemptStatusToV1),
+		Conditions:                in.Status.Conditions,
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the v1 hub to the CycleNodeRequest
+func (in *CycleNodeRequest) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1.CycleNodeRequest)
+	if !ok {
+		return fmt.Errorf("expected a v1 CycleNodeRequest but got %T", srcRaw)
+	}
+
+	in.ObjectMeta = src.ObjectMeta
+
+	nodeGroupNames, err := nodeGroupsFromV1(&in.ObjectMeta.Annotations, src.Spec.NodeGroupName, src.Spec.NodeGroupsList)
+	if err != nil {
+		return err
+	}
+
+	in.Spec = CycleNodeRequestSpec{
+		NodeGroupNames:           nodeGroupNames,
+		ClusterName:              src.ClusterName,
+		Selector:                 src.Spec.Selector,
+		NodeNames:                src.Spec.NodeNames,
+		ValidationOptions:        ValidationOptions(src.Spec.ValidationOptions),
+		HealthChecks:             convertSlice(src.Spec.HealthChecks, convertHealthCheckFromV1),
+		ClusterHealthChecks:      convertSlice(src.Spec.ClusterHealthChecks, convertHealthCheckFromV1),
+		PreTerminationChecks:     convertSlice(src.Spec.PreTerminationChecks, convertPreTerminationCheckFromV1),
+		SkipInitialHealthChecks:  src.Spec.SkipInitialHealthChecks,
+		SkipPreTerminationChecks: src.Spec.SkipPreTerminationChecks,
+	}
+	convertCycleSettingsFromV1(&src.Spec.CycleSettings, &in.Spec.CycleSettings)
+
+	in.Status = CycleNodeRequestStatus{
+		Phase:                     CycleNodeRequestPhase(src.Status.Phase),
+		Message:                   src.Status.Message,
+		CurrentNodes:              convertSlice(src.Status.CurrentNodes, convertCycleNodeRequestNodeFromV1),
+		NodesToTerminate:          convertSlice(src.Status.NodesToTerminate, convertCycleNodeRequestNodeFromV1),
+		ScaleUpStarted:            src.Status.ScaleUpStarted,
+		EquilibriumWaitStarted:    src.Status.EquilibriumWaitStarted,
+		CapacityWaitStarted:       src.Status.CapacityWaitStarted,
+		ClusterHealthCheckStarted: src.Status.ClusterHealthCheckStarted,
+		ActiveChildren:            src.Status.ActiveChildren,
+		ThreadTimestamp:           src.Status.ThreadTimestamp,
+		SelectedNodes:             src.Status.SelectedNodes,
+		NumNodesCycled:            src.Status.NumNodesCycled,
+		NodesAvailable:            convertSlice(src.Status.NodesAvailable, convertCycleNodeRequestNodeFromV1),
+		HealthChecks:              convertMap(src.Status.HealthChecks, convertHealthCheckStatusFromV1),
+		PreTerminationChecks:      convertMap(src.Status.PreTerminationChecks, convertPreTerminationCheckStatusListFromV1),
+		PreCordonHooks:            convertMap(src.Status.PreCordonHooks, convertLifecycleHookStatusListFromV1),
+		AnnotatedNodes:            src.Status.AnnotatedNodes,
+		TaintedNodes:              src.Status.TaintedNodes,
+		SkippedNodes:              convertSlice(src.Status.SkippedNodes, convertCycleNodeRequestSkippedNodeFromV1),
+		NodeAttempts:              convertMap(src.Status.NodeAttempts, convertCycleNodeAttemptStatusFromV1),
+		Conditions:                src.Status.Conditions,
+	}
+
+	return nil
+}
+
+// ConvertTo converts the CycleNodeStatus to the v1 hub
+func (in *CycleNodeStatus) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1.CycleNodeStatus)
+	if !ok {
+		return fmt.Errorf("expected a v1 CycleNodeStatus but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = in.ObjectMeta
+
+	dst.Spec = v1.CycleNodeStatusSpec{
+		NodeName:   in.Spec.NodeName,
+		ProviderID: in.Spec.ProviderID,
+	}
+	convertCycleSettingsToV1(&in.Spec.CycleSettings, &dst.Spec.CycleSettings)
+
+	dst.Status = v1.CycleNodeStatusStatus{
+		Phase:                   v1.CycleNodeStatusPhase(in.Status.Phase),
+		Message:                 in.Status.Message,
+		CurrentNode:             v1.CycleNodeRequestNode(in.Status.CurrentNode),
+		StartedTimestamp:        in.Status.StartedTimestamp,
+		TimeoutTimestamp:        in.Status.TimeoutTimestamp,
+		FailedPhase:             v1.CycleNodeStatusPhase(in.Status.FailedPhase),
+		Retryable:               in.Status.Retryable,
+		WaitingPodsStarted:      in.Status.WaitingPodsStarted,
+		BlockedPods:             convertSlice(in.Status.BlockedPods, convertBlockedPodToV1),
+		ForceDeletedPods:        convertSlice(in.Status.ForceDeletedPods, convertForceDeletedPodToV1),
+		LifecycleHooks:          convertSlice(in.Status.LifecycleHooks, convertLifecycleHookStatusToV1),
+		DrainBlockedNotified:    in.Status.DrainBlockedNotified,
+		DeregisteringStarted:    in.Status.DeregisteringStarted,
+		TargetsUnchecked:        in.Status.TargetsUnchecked,
+		DetachingVolumesStarted: in.Status.DetachingVolumesStarted,
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the v1 hub to the CycleNodeStatus
+func (in *CycleNodeStatus) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1.CycleNodeStatus)
+	if !ok {
+		return fmt.Errorf("expected a v1 CycleNodeStatus but got %T", srcRaw)
+	}
+
+	in.ObjectMeta = src.ObjectMeta
+
+	in.Spec = CycleNodeStatusSpec{
+		NodeName:   src.Spec.NodeName,
+		ProviderID: src.Spec.ProviderID,
+	}
+	convertCycleSettingsFromV1(&src.Spec.CycleSettings, &in.Spec.CycleSettings)
+
+	in.Status = CycleNodeStatusStatus{
+		Phase:                   CycleNodeStatusPhase(src.Status.Phase),
+		Message:                 src.Status.Message,
+		CurrentNode:             CycleNodeRequestNode(src.Status.CurrentNode),
+		StartedTimestamp:        src.Status.StartedTimestamp,
+		TimeoutTimestamp:        src.Status.TimeoutTimestamp,
+		FailedPhase:             CycleNodeStatusPhase(src.Status.FailedPhase),
+		Retryable:               src.Status.Retryable,
+		WaitingPodsStarted:      src.Status.WaitingPodsStarted,
+		BlockedPods:             convertSlice(src.Status.BlockedPods, convertBlockedPodFromV1),
+		ForceDeletedPods:        convertSlice(src.Status.ForceDeletedPods, convertForceDeletedPodFromV1),
+		LifecycleHooks:          convertSlice(src.Status.LifecycleHooks, convertLifecycleHookStatusFromV1),
+		DrainBlockedNotified:    src.Status.DrainBlockedNotified,
+		DeregisteringStarted:    src.Status.DeregisteringStarted,
+		TargetsUnchecked:        src.Status.TargetsUnchecked,
+		DetachingVolumesStarted: src.Status.DetachingVolumesStarted,
+	}
+
+	return nil
+}
+
+// ConvertTo converts the NodeGroup to the v1 hub
+func (in *NodeGroup) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1.NodeGroup)
+	if !ok {
+		return fmt.Errorf("expected a v1 NodeGroup but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = in.ObjectMeta
+
+	nodeGroupName, nodeGroupsList, err := nodeGroupsToV1(&dst.ObjectMeta.Annotations, in.Spec.NodeGroupNames)
+	if err != nil {
+		return err
+	}
+
+	dst.Spec = v1.NodeGroupSpec{
+		NodeGroupName:              nodeGroupName,
+		NodeGroupsList:             nodeGroupsList,
+		NodeSelector:               in.Spec.NodeSelector,
+		MaxFailedCycleNodeRequests: in.Spec.MaxFailedCycleNodeRequests,
+		ValidationOptions:          v1.ValidationOptions(in.Spec.ValidationOptions),
+		HealthChecks:               convertSlice(in.Spec.HealthChecks, convertHealthCheckToV1),
+		ClusterHealthChecks:        convertSlice(in.Spec.ClusterHealthChecks, convertHealthCheckToV1),
+		PreTerminationChecks:       convertSlice(in.Spec.PreTerminationChecks, convertPreTerminationCheckToV1),
+		SkipInitialHealthChecks:    in.Spec.SkipInitialHealthChecks,
+		SkipPreTerminationChecks:   in.Spec.SkipPreTerminationChecks,
+		Priority:                   in.Spec.Priority,
+	}
+	convertCycleSettingsToV1(&in.Spec.CycleSettings, &dst.Spec.CycleSettings)
+
+	dst.Status = v1.NodeGroupStatus(in.Status)
+	return nil
+}
+
+// ConvertFrom converts the v1 hub to the NodeGroup
+func (in *NodeGroup) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1.NodeGroup)
+	if !ok {
+		return fmt.Errorf("expected a v1 NodeGroup but got %T", srcRaw)
+	}
+
+	in.ObjectMeta = src.ObjectMeta
+
+	nodeGroupNames, err := nodeGroupsFromV1(&in.ObjectMeta.Annotations, src.Spec.NodeGroupName, src.Spec.NodeGroupsList)
+	if err != nil {
+		return err
+	}
+
+	in.Spec = NodeGroupSpec{
+		NodeGroupNames:             nodeGroupNames,
+		NodeSelector:               src.Spec.NodeSelector,
+		MaxFailedCycleNodeRequests: src.Spec.MaxFailedCycleNodeRequests,
+		ValidationOptions:          ValidationOptions(src.Spec.ValidationOptions),
+		HealthChecks:               convertSlice(src.Spec.HealthChecks, convertHealthCheckFromV1),
+		ClusterHealthChecks:        convertSlice(src.Spec.ClusterHealthChecks, convertHealthCheckFromV1),
+		PreTerminationChecks:       convertSlice(src.Spec.PreTerminationChecks, convertPreTerminationCheckFromV1),
+		SkipInitialHealthChecks:    src.Spec.SkipInitialHealthChecks,
+		SkipPreTerminationChecks:   src.Spec.SkipPreTerminationChecks,
+		Priority:                   src.Spec.Priority,
+	}
+	convertCycleSettingsFromV1(&src.Spec.CycleSettings, &in.Spec.CycleSettings)
+
+	in.Status = NodeGroupStatus(src.Status)
+	return nil
+}
+
+// nodeGroupNamesFromV1 lists the node groups of a v1 object: nodeGroupName first, unless it's empty or already in
+// nodeGroupsList, followed by nodeGroupsList
+func nodeGroupNamesFromV1(nodeGroupName string, nodeGroupsList []string) []string {
+	if nodeGroupName == "" || slices.Contains(nodeGroupsList, nodeGroupName) {
+		return slices.Clone(nodeGroupsList)
+	}
+
+	return append([]string{nodeGroupName}, nodeGroupsList...)
+}
+
+// nodeGroupNamesToV1 lists the node groups the way v1 does, the first as nodeGroupName and the rest in
+// nodeGroupsList. It's the inverse of nodeGroupNamesFromV1.
+func nodeGroupNamesToV1(nodeGroupNames []string) (string, []string) {
+	if len(nodeGroupNames) == 0 || nodeGroupNames[0] == "" || slices.Contains(nodeGroupNames[1:], nodeGroupNames[0]) {
+		return "", slices.Clone(nodeGroupNames)
+	}
+
+	if len(nodeGroupNames) == 1 {
+		return nodeGroupNames[0], nil
+	}
+
+	return nodeGroupNames[0], slices.Clone(nodeGroupNames[1:])
+}
+
+// nodeGroupsFromV1 converts the node groups of a v1 object, recording them in the NodeGroupsConversionAnnotation
+// if converting back to v1 wouldn't list them the same way
+func nodeGroupsFromV1(annotations *map[string]string, nodeGroupName string, nodeGroupsList []string) ([]string, error) {
+	nodeGroupNames := nodeGroupNamesFromV1(nodeGroupName, nodeGroupsList)
+
+	// The annotations are shared with the v1 object, so they're copied before they're changed
+	*annotations = maps.Clone(*annotations)
+	delete(*annotations, NodeGroupsConversionAnnotation)
+
+	convertedName, convertedList := nodeGroupNamesToV1(nodeGroupNames)
+	if convertedName == nodeGroupName && reflect.DeepEqual(convertedList, nodeGroupsList) {
+		if len(*annotations) == 0 {
+			*annotations = nil
+		}
+
+		return nodeGroupNames, nil
+	}
+
+	data, err := json.Marshal(v1NodeGroups{NodeGroupName: nodeGroupName, NodeGroupsList: nodeGroupsList})
+	if err != nil {
+		return nil, err
+	}
+
+	if *annotations == nil {
+		*annotations = make(map[string]string)
+	}
+
+	(*annotations)[NodeGroupsConversionAnnotation] = string(data)
+	return nodeGroupNames, nil
+}
+
+// nodeGroupsToV1 converts the node groups to the v1 fields. They're listed as they were in the
+// NodeGroupsConversionAnnotation, unless nodeGroupNames has been changed since it was recorded.
+func nodeGroupsToV1(annotations *map[string]string, nodeGroupNames []string) (string, []string, error) {
+	data, ok := (*annotations)[NodeGroupsConversionAnnotation]
+	if !ok {
+		nodeGroupName, nodeGroupsList := nodeGroupNamesToV1(nodeGroupNames)
+		return nodeGroupName, nodeGroupsList, nil
+	}
+
+	// The annotations are shared with the v2 object, so they're copied before they're changed
+	*annotations = maps.Clone(*annotations)
+	delete(*annotations, NodeGroupsConversionAnnotation)
+	if len(*annotations) == 0 {
+		*annotations = nil
+	}
+
+	var recorded v1NodeGroups
+	if err := json.Unmarshal([]byte(data), &recorded); err != nil {
+		return "", nil, fmt.Errorf("invalid %s annotation: %w", NodeGroupsConversionAnnotation, err)
+	}
+
+	if !slices.Equal(nodeGroupNamesFromV1(recorded.NodeGroupName, recorded.NodeGroupsList), nodeGroupNames) {
+		nodeGroupName, nodeGroupsList := nodeGroupNamesToV1(nodeGroupNames)
+		return nodeGroupName, nodeGroupsList, nil
+	}
+
+	return recorded.NodeGroupName, recorded.NodeGroupsList, nil
+}
+
+func convertCycleSettingsFromV1(in *v1.CycleSettings, out *CycleSettings) {
+	*out = CycleSettings{
+		Method:                     CycleNodeRequestMethod(in.Method),
+		Concurrency:                in.Concurrency,
+		LabelsToRemove:             in.LabelsToRemove,
+		IgnorePodsLabels:           in.IgnorePodsLabels,
+		IgnoreNamespaces:           in.IgnoreNamespaces,
+		CyclingTimeout:             in.CyclingTimeout,
+		MaxFailedNodes:             in.MaxFailedNodes,
+		RetryPolicy:                convertPointer(in.RetryPolicy, convertRetryPolicyFromV1),
+		CheckCapacity:              in.CheckCapacity,
+		DrainBlockedTimeout:        in.DrainBlockedTimeout,
+		DoNotDisruptTimeout:        in.DoNotDisruptTimeout,
+		DrainOrder:                 convertPointer(in.DrainOrder, convertDrainOrderFromV1),
+		EvictionOptions:            convertPointer(in.EvictionOptions, convertEvictionOptionsFromV1),
+		UnhealthyPodPolicy:         convertPointer(in.UnhealthyPodPolicy, convertUnhealthyPodPolicyFromV1),
+		LifecycleHooks:             convertSlice(in.LifecycleHooks, convertLifecycleHookFromV1),
+		DrainTaint:                 convertPointer(in.DrainTaint, convertDrainTaintFromV1),
+		VolumeDetachTimeout:        in.VolumeDetachTimeout,
+		LoadBalancerDeregistration: convertPointer(in.LoadBalancerDeregistration, convertLoadBalancerDeregistrationFromV1),
+	}
+}
+
+func convertCycleSettingsToV1(in *CycleSettings, out *v1.CycleSettings) {
+	*out = v1.CycleSettings{
+		Method:                     v1.CycleNodeRequestMethod(in.Method),
+		Concurrency:                in.Concurrency,
+		LabelsToRemove:             in.LabelsToRemove,
+		IgnorePodsLabels:           in.IgnorePodsLabels,
+		IgnoreNamespaces:           in.IgnoreNamespaces,
+		CyclingTimeout:             in.CyclingTimeout,
+		MaxFailedNodes:             in.MaxFailedNodes,
+		RetryPolicy:                convertPointer(in.RetryPolicy, convertRetryPolicyToV1),
+		CheckCapacity:              in.CheckCapacity,
+		DrainBlockedTimeout:        in.DrainBlockedTimeout,
+		DoNotDisruptTimeout:        in.DoNotDisruptTimeout,
+		DrainOrder:                 convertPointer(in.DrainOrder, convertDrainOrderToV1),
+		EvictionOptions:            convertPointer(in.EvictionOptions, convertEvictionOptionsToV1),
+		UnhealthyPodPolicy:         convertPointer(in.UnhealthyPodPolicy, convertUnhealthyPodPolicyToV1),
+		LifecycleHooks:             convertSlice(in.LifecycleHooks, convertLifecycleHookToV1),
+		DrainTaint:                 convertPointer(in.DrainTaint, convertDrainTaintToV1),
+		VolumeDetachTimeout:        in.VolumeDetachTimeout,
+		LoadBalancerDeregistration: convertPointer(in.LoadBalancerDeregistration, convertLoadBalancerDeregistrationToV1),
+	}
+}
+
+func convertUnhealthyPodPolicyFromV1(in *v1.UnhealthyPodPolicy, out *UnhealthyPodPolicy) {
+	*out = UnhealthyPodPolicy{
+		Mode:           UnhealthyPodPolicyMode(in.Mode),
+		UnhealthyAfter: in.UnhealthyAfter,
+		Namespaces:     in.Namespaces,
+		Selector:       in.Selector,
+	}
+}
+
+func convertUnhealthyPodPolicyToV1(in *UnhealthyPodPolicy, out *v1.UnhealthyPodPolicy) {
+	*out = v1.UnhealthyPodPolicy{
+		Mode:           v1.UnhealthyPodPolicyMode(in.Mode),
+		UnhealthyAfter: in.UnhealthyAfter,
+		Namespaces:     in.Namespaces,
+		Selector:       in.Selector,
+	}
+}
+
+func convertLifecycleHookFromV1(in *v1.LifecycleHook, out *LifecycleHook) {
+	*out = LifecycleHook{
+		Name:             in.Name,
+		Event:            LifecycleHookEvent(in.Event),
+		Endpoint:         in.Endpoint,
+		ValidStatusCodes: in.ValidStatusCodes,
+		Blocking:         in.Blocking,
+		Timeout:          in.Timeout,
+	}
+	convertTLSConfigFromV1(&in.TLSConfig, &out.TLS)
+}
+
+func convertLifecycleHookToV1(in *LifecycleHook, out *v1.LifecycleHook) {
+	*out = v1.LifecycleHook{
+		Name:             in.Name,
+		Event:            v1.LifecycleHookEvent(in.Event),
+		Endpoint:         in.Endpoint,
+		ValidStatusCodes: in.ValidStatusCodes,
+		Blocking:         in.Blocking,
+		Timeout:          in.Timeout,
+	}
+	convertTLSConfigToV1(&in.TLS, &out.TLSConfig)
+}
+
+func convertHealthCheckFromV1(in *v1.HealthCheck, out *HealthCheck) {
+	*out = HealthCheck{
+		Type:             HealthCheckType(in.Type),
+		Endpoint:         in.Endpoint,
+		GRPCService:      in.GRPCService,
+		Job:              convertPointer(in.Job, convertJobHealthCheckFromV1),
+		NodeReadiness:    convertPointer(in.NodeReadiness, convertNodeReadinessHealthCheckFromV1),
+		Prometheus:       convertPointer(in.Prometheus, convertPrometheusHealthCheckFromV1),
+		WaitPeriod:       in.WaitPeriod,
+		ValidStatusCodes: in.ValidStatusCodes,
+		RegexMatch:       in.RegexMatch,
+		JSONPathMatch:    convertPointer(in.JSONPathMatch, convertJSONPathAssertionFromV1),
+	}
+	convertHTTPRequestFromV1(&in.HTTPRequest, &out.Request)
+	convertTLSConfigFromV1(&in.TLSConfig, &out.TLS)
+}
+
+func convertHealthCheckToV1(in *HealthCheck, out *v1.HealthCheck) {
+	*out = v1.HealthCheck{
+		Type:             v1.HealthCheckType(in.Type),
+		Endpoint:         in.Endpoint,
+		GRPCService:      in.GRPCService,
+		Job:              convertPointer(in.Job, convertJobHealthCheckToV1),
+		NodeReadiness:    convertPointer(in.NodeReadiness, convertNodeReadinessHealthCheckToV1),
+		Prometheus:       convertPointer(in.Prometheus, convertPrometheusHealthCheckToV1),
+		WaitPeriod:       in.WaitPeriod,
+		ValidStatusCodes: in.ValidStatusCodes,
+		RegexMatch:       in.RegexMatch,
+		JSONPathMatch:    convertPointer(in.JSONPathMatch, convertJSONPathAssertionToV1),
+	}
+	convertHTTPRequestToV1(&in.Request, &out.HTTPRequest)
+	convertTLSConfigToV1(&in.TLS, &out.TLSConfig)
+}
+
+func convertNodeReadinessHealthCheckFromV1(in *v1.NodeReadinessHealthCheck, out *NodeReadinessHealthCheck) {
+	*out = NodeReadinessHealthCheck{
+		DaemonSetPods: in.DaemonSetPods,
+		Conditions:    convertSlice(in.Conditions, convertNodeConditionRequirementFromV1),
+		Labels:        in.Labels,
+		AbsentTaints:  in.AbsentTaints,
+	}
+}
+
+func convertNodeReadinessHealthCheckToV1(in *NodeReadinessHealthCheck, out *v1.NodeReadinessHealthCheck) {
+	*out = v1.NodeReadinessHealthCheck{
+		DaemonSetPods: in.DaemonSetPods,
+		Conditions:    convertSlice(in.Conditions, convertNodeConditionRequirementToV1),
+		Labels:        in.Labels,
+		AbsentTaints:  in.AbsentTaints,
+	}
+}
+
+func convertPrometheusHealthCheckFromV1(in *v1.PrometheusHealthCheck, out *PrometheusHealthCheck) {
+	*out = PrometheusHealthCheck{
+		Query:            in.Query,
+		Operator:         PrometheusOperator(in.Operator),
+		Threshold:        in.Threshold,
+		AllowEmptyResult: in.AllowEmptyResult,
+	}
+}
+
+func convertPrometheusHealthCheckToV1(in *PrometheusHealthCheck, out *v1.PrometheusHealthCheck) {
+	*out = v1.PrometheusHealthCheck{
+		Query:            in.Query,
+		Operator:         v1.PrometheusOperator(in.Operator),
+		Threshold:        in.Threshold,
+		AllowEmptyResult: in.AllowEmptyResult,
+	}
+}
+
+func convertPreTerminationCheckFromV1(in *v1.PreTerminationCheck, out *PreTerminationCheck) {
+	*out = PreTerminationCheck{
+		TriggerEndpoint:  in.Endpoint,
+		ValidStatusCodes: in.ValidStatusCodes,
+	}
+	convertHealthCheckFromV1(&in.HealthCheck, &out.HealthCheck)
+	convertHTTPRequestFromV1(&in.HTTPRequest, &out.Request)
+	convertTLSConfigFromV1(&in.TLSConfig, &out.TLS)
+}
+
+func convertPreTerminationCheckToV1(in *PreTerminationCheck, out *v1.PreTerminationCheck) {
+	*out = v1.PreTerminationCheck{
+		Endpoint:         in.TriggerEndpoint,
+		ValidStatusCodes: in.ValidStatusCodes,
+	}
+	convertHealthCheckToV1(&in.HealthCheck, &out.HealthCheck)
+	convertHTTPRequestToV1(&in.Request, &out.HTTPRequest)
+	convertTLSConfigToV1(&in.TLS, &out.TLSConfig)
+}
+
+func convertHTTPRequestFromV1(in *v1.HTTPRequest, out *HTTPRequest) {
+	*out = HTTPRequest{
+		Method:               in.Method,
+		Headers:              convertSlice(in.Headers, convertHTTPHeaderFromV1),
+		Body:                 in.Body,
+		BearerTokenSecretRef: convertPointer(in.BearerTokenSecretRef, convertSecretKeyReferenceFromV1),
+	}
+}
+
+func convertHTTPRequestToV1(in *HTTPRequest, out *v1.HTTPRequest) {
+	*out = v1.HTTPRequest{
+		Method:               in.Method,
+		Headers:              convertSlice(in.Headers, convertHTTPHeaderToV1),
+		Body:                 in.Body,
+		BearerTokenSecretRef: convertPointer(in.BearerTokenSecretRef, convertSecretKeyReferenceToV1),
+	}
+}
+
+func convertHTTPHeaderFromV1(in *v1.HTTPHeader, out *HTTPHeader) {
+	*out = HTTPHeader{
+		Name:      in.Name,
+		Value:     in.Value,
+		ValueFrom: convertPointer(in.ValueFrom, convertSecretKeyReferenceFromV1),
+	}
+}
+
+func convertHTTPHeaderToV1(in *HTTPHeader, out *v1.HTTPHeader) {
+	*out = v1.HTTPHeader{
+		Name:      in.Name,
+		Value:     in.Value,
+		ValueFrom: convertPointer(in.ValueFrom, convertSecretKeyReferenceToV1),
+	}
+}
+
+func convertTLSConfigFromV1(in *v1.TLSConfig, out *TLSConfig) {
+	*out = TLSConfig{
+		RootCA:       in.RootCA,
+		Certificate:  in.Certificate,
+		Key:          in.Key,
+		SecretRef:    convertPointer(in.SecretRef, convertTLSSecretReferenceFromV1),
+		ConfigMapRef: convertPointer(in.ConfigMapRef, convertTLSConfigMapReferenceFromV1),
+	}
+}
+
+func convertTLSConfigToV1(in *TLSConfig, out *v1.TLSConfig) {
+	*out = v1.TLSConfig{
+		RootCA:       in.RootCA,
+		Certificate:  in.Certificate,
+		Key:          in.Key,
+		SecretRef:    convertPointer(in.SecretRef, convertTLSSecretReferenceToV1),
+		ConfigMapRef: convertPointer(in.ConfigMapRef, convertTLSConfigMapReferenceToV1),
+	}
+}
+
+func convertCycleNodeRequestNodeFromV1(in *v1.CycleNodeRequestNode, out *CycleNodeRequestNode) {
+	*out = CycleNodeRequestNode(*in)
+}
+
+func convertCycleNodeRequestNodeToV1(in *CycleNodeRequestNode, out *v1.CycleNodeRequestNode) {
+	*out = v1.CycleNodeRequestNode(*in)
+}
+
+func convertCycleNodeRequestSkippedNodeFromV1(in *v1.CycleNodeRequestSkippedNode, out *CycleNodeRequestSkippedNode) {
+	*out = CycleNodeRequestSkippedNode{
+		CycleNodeRequestNode: CycleNodeRequestNode(in.CycleNodeRequestNode),
+		Reason:               in.Reason,
+	}
+}
+
+func convertCycleNodeRequestSkippedNodeToV1(in *CycleNodeRequestSkippedNode, out *v1.CycleNodeRequestSkippedNode) {
+	*out = v1.CycleNodeRequestSkippedNode{
+		CycleNodeRequestNode: v1.CycleNodeRequestNode(in.CycleNodeRequestNode),
+		Reason:               in.Reason,
+	}
+}
+
+func convertCycleNodeAttemptStatusFromV1(in *v1.CycleNodeAttemptStatus, out *CycleNodeAttemptStatus) {
+	*out = CycleNodeAttemptStatus{
+		Attempts:    convertSlice(in.Attempts, convertCycleNodeAttemptFromV1),
+		NextAttempt: in.NextAttempt,
+	}
+}
+
+func convertCycleNodeAttemptStatusToV1(in *CycleNodeAttemptStatus, out *v1.CycleNodeAttemptStatus) {
+	*out = v1.CycleNodeAttemptStatus{
+		Attempts:    convertSlice(in.Attempts, convertCycleNodeAttemptToV1),
+		NextAttempt: in.NextAttempt,
+	}
+}
+
+func convertCycleNodeAttemptFromV1(in *v1.CycleNodeAttempt, out *CycleNodeAttempt) {
+	*out = CycleNodeAttempt{
+		Phase:           CycleNodeStatusPhase(in.Phase),
+		Message:         in.Message,
+		Retryable:       in.Retryable,
+		FailedTimestamp: in.FailedTimestamp,
+	}
+}
+
+func convertCycleNodeAttemptToV1(in *CycleNodeAttempt, out *v1.CycleNodeAttempt) {
+	*out = v1.CycleNodeAttempt{
+		Phase:           v1.CycleNodeStatusPhase(in.Phase),
+		Message:         in.Message,
+		Retryable:       in.Retryable,
+		FailedTimestamp: in.FailedTimestamp,
+	}
+}
+
+func convertHealthCheckStatusFromV1(in *v1.HealthCheckStatus, out *HealthCheckStatus) {
+	*out = HealthCheckStatus{
+		NodeReady: in.NodeReady,
+		Checks:    in.Checks,
+		Results:   convertSlice(in.Results, convertHealthCheckResultFromV1),
+		Skip:      in.Skip,
+	}
+}
+
+func convertHealthCheckStatusToV1(in *HealthCheckStatus, out *v1.HealthCheckStatus) {
+	*out = v1.HealthCheckStatus{
+		NodeReady: in.NodeReady,
+		Checks:    in.Checks,
+		Results:   convertSlice(in.Results, convertHealthCheckResultToV1),
+		Skip:      in.Skip,
+	}
+}
+
+func convertPreTerminationCheckStatusListFromV1(in *v1.PreTerminationCheckStatusList, out *PreTerminationCheckStatusList) {
+	*out = PreTerminationCheckStatusList{
+		Checks: convertSlice(in.Checks, convertPreTerminationCheckStatusFromV1),
+	}
+}
+
+func convertPreTerminationCheckStatusListToV1(in *PreTerminationCheckStatusList, out *v1.PreTerminationCheckStatusList) {
+	*out = v1.PreTerminationCheckStatusList{
+		Checks: convertSlice(in.Checks, convertPreTerminationCheckStatusToV1),
+	}
+}
+
+func convertLifecycleHookStatusListFromV1(in *v1.LifecycleHookStatusList, out *LifecycleHookStatusList) {
+	*out = LifecycleHookStatusList{
+		Hooks: convertSlice(in.Hooks, convertLifecycleHookStatusFromV1),
+	}
+}
+
+func convertLifecycleHookStatusListToV1(in *LifecycleHookStatusList, out *v1.LifecycleHookStatusList) {
+	*out = v1.LifecycleHookStatusList{
+		Hooks: convertSlice(in.Hooks, convertLifecycleHookStatusToV1),
+	}
+}
+
+func convertRetryPolicyFromV1(in *v1.RetryPolicy, out *RetryPolicy) {
+	*out = RetryPolicy{
+		MaxAttempts: in.MaxAttempts,
+		Backoff:     in.Backoff,
+	}
+}
+
+func convertRetryPolicyToV1(in *RetryPolicy, out *v1.RetryPolicy) {
+	*out = v1.RetryPolicy{
+		MaxAttempts: in.MaxAttempts,
+		Backoff:     in.Backoff,
+	}
+}
+
+func convertDrainOrderFromV1(in *v1.DrainOrder, out *DrainOrder) {
+	*out = DrainOrder{
+		Waves:      in.Waves,
+		ByPriority: in.ByPriority,
+	}
+}
+
+func convertDrainOrderToV1(in *DrainOrder, out *v1.DrainOrder) {
+	*out = v1.DrainOrder{
+		Waves:      in.Waves,
+		ByPriority: in.ByPriority,
+	}
+}
+
+func convertEvictionOptionsFromV1(in *v1.EvictionOptions, out *EvictionOptions) {
+	*out = EvictionOptions{
+		GracePeriodSeconds: in.GracePeriodSeconds,
+		PodEvictionTimeout: in.PodEvictionTimeout,
+		PropagationPolicy:  in.PropagationPolicy,
+	}
+}
+
+func convertEvictionOptionsToV1(in *EvictionOptions, out *v1.EvictionOptions) {
+	*out = v1.EvictionOptions{
+		GracePeriodSeconds: in.GracePeriodSeconds,
+		PodEvictionTimeout: in.PodEvictionTimeout,
+		PropagationPolicy:  in.PropagationPolicy,
+	}
+}
+
+func convertDrainTaintFromV1(in *v1.DrainTaint, out *DrainTaint) {
+	*out = DrainTaint{
+		Key:    in.Key,
+		Value:  in.Value,
+		Effect: in.Effect,
+	}
+}
+
+func convertDrainTaintToV1(in *DrainTaint, out *v1.DrainTaint) {
+	*out = v1.DrainTaint{
+		Key:    in.Key,
+		Value:  in.Value,
+		Effect: in.Effect,
+	}
+}
+
+func convertLoadBalancerDeregistrationFromV1(in *v1.LoadBalancerDeregistration, out *LoadBalancerDeregistration) {
+	*out = LoadBalancerDeregistration{
+		DrainingDelay:  in.DrainingDelay,
+		WaitForTargets: in.WaitForTargets,
+		Timeout:        in.Timeout,
+	}
+}
+
+func convertLoadBalancerDeregistrationToV1(in *LoadBalancerDeregistration, out *v1.LoadBalancerDeregistration) {
+	*out = v1.LoadBalancerDeregistration{
+		DrainingDelay:  in.DrainingDelay,
+		WaitForTargets: in.WaitForTargets,
+		Timeout:        in.Timeout,
+	}
+}
+
+func convertJobHealthCheckFromV1(in *v1.JobHealthCheck, out *JobHealthCheck) {
+	*out = JobHealthCheck{
+		Image:              in.Image,
+		Command:            in.Command,
+		Args:               in.Args,
+		ServiceAccountName: in.ServiceAccountName,
+		BackoffLimit:       in.BackoffLimit,
+	}
+}
+
+func convertJobHealthCheckToV1(in *JobHealthCheck, out *v1.JobHealthCheck) {
+	*out = v1.JobHealthCheck{
+		Image:              in.Image,
+		Command:            in.Command,
+		Args:               in.Args,
+		ServiceAccountName: in.ServiceAccountName,
+		BackoffLimit:       in.BackoffLimit,
+	}
+}
+
+func convertJSONPathAssertionFromV1(in *v1.JSONPathAssertion, out *JSONPathAssertion) {
+	*out = JSONPathAssertion{
+		Path:  in.Path,
+		Value: in.Value,
+	}
+}
+
+func convertJSONPathAssertionToV1(in *JSONPathAssertion, out *v1.JSONPathAssertion) {
+	*out = v1.JSONPathAssertion{
+		Path:  in.Path,
+		Value: in.Value,
+	}
+}
+
+func convertNodeConditionRequirementFromV1(in *v1.NodeConditionRequirement, out *NodeConditionRequirement) {
+	*out = NodeConditionRequirement{
+		Type:   in.Type,
+		Status: in.Status,
+	}
+}
+
+func convertNodeConditionRequirementToV1(in *NodeConditionRequirement, out *v1.NodeConditionRequirement) {
+	*out = v1.NodeConditionRequirement{
+		Type:   in.Type,
+		Status: in.Status,
+	}
+}
+
+func convertSecretKeyReferenceFromV1(in *v1.SecretKeyReference, out *SecretKeyReference) {
+	*out = SecretKeyReference{
+		Name: in.Name,
+		Key:  in.Key,
+	}
+}
+
+func convertSecretKeyReferenceToV1(in *SecretKeyReference, out *v1.SecretKeyReference) {
+	*out = v1.SecretKeyReference{
+		Name: in.Name,
+		Key:  in.Key,
+	}
+}
+
+func convertTLSSecretReferenceFromV1(in *v1.TLSSecretReference, out *TLSSecretReference) {
+	*out = TLSSecretReference{
+		Name:           in.Name,
+		RootCAKey:      in.RootCAKey,
+		CertificateKey: in.CertificateKey,
+		KeyKey:         in.KeyKey,
+	}
+}
+
+func convertTLSSecretReferenceToV1(in *TLSSecretReference, out *v1.TLSSecretReference) {
+	*out = v1.TLSSecretReference{
+		Name:           in.Name,
+		RootCAKey:      in.RootCAKey,
+		CertificateKey: in.CertificateKey,
+		KeyKey:         in.KeyKey,
+	}
+}
+
+func convertTLSConfigMapReferenceFromV1(in *v1.TLSConfigMapReference, out *TLSConfigMapReference) {
+	*out = TLSConfigMapReference{
+		Name:      in.Name,
+		RootCAKey: in.RootCAKey,
+	}
+}
+
+func convertTLSConfigMapReferenceToV1(in *TLSConfigMapReference, out *v1.TLSConfigMapReference) {
+	*out = v1.TLSConfigMapReference{
+		Name:      in.Name,
+		RootCAKey: in.RootCAKey,
+	}
+}
+
+func convertBlockedPodFromV1(in *v1.BlockedPod, out *BlockedPod) {
+	*out = BlockedPod{
+		Name:                 in.Name,
+		Namespace:            in.Namespace,
+		PodDisruptionBudgets: in.PodDisruptionBudgets,
+		BlockedSince:         in.BlockedSince,
+		BlockedFor:           in.BlockedFor,
+	}
+}
+
+func convertBlockedPodToV1(in *BlockedPod, out *v1.BlockedPod) {
+	*out = v1.BlockedPod{
+		Name:                 in.Name,
+		Namespace:            in.Namespace,
+		PodDisruptionBudgets: in.PodDisruptionBudgets,
+		BlockedSince:         in.BlockedSince,
+		BlockedFor:           in.BlockedFor,
+	}
+}
+
+func convertForceDeletedPodFromV1(in *v1.ForceDeletedPod, out *ForceDeletedPod) {
+	*out = ForceDeletedPod{
+		Name:      in.Name,
+		Namespace: in.Namespace,
+		Reason:    in.Reason,
+		Message:   in.Message,
+		DeletedAt: in.DeletedAt,
+	}
+}
+
+func convertForceDeletedPodToV1(in *ForceDeletedPod, out *v1.ForceDeletedPod) {
+	*out = v1.ForceDeletedPod{
+		Name:      in.Name,
+		Namespace: in.Namespace,
+		Reason:    in.Reason,
+		Message:   in.Message,
+		DeletedAt: in.DeletedAt,
+	}
+}
+
+func convertLifecycleHookStatusFromV1(in *v1.LifecycleHookStatus, out *LifecycleHookStatus) {
+	*out = LifecycleHookStatus{
+		Name:         in.Name,
+		Attempts:     in.Attempts,
+		FirstAttempt: in.FirstAttempt,
+		Succeeded:    in.Succeeded,
+		Message:      in.Message,
+	}
+}
+
+func convertLifecycleHookStatusToV1(in *LifecycleHookStatus, out *v1.LifecycleHookStatus) {
+	*out = v1.LifecycleHookStatus{
+		Name:         in.Name,
+		Attempts:     in.Attempts,
+		FirstAttempt: in.FirstAttempt,
+		Succeeded:    in.Succeeded,
+		Message:      in.Message,
+	}
+}
+
+func convertHealthCheckResultFromV1(in *v1.HealthCheckResult, out *HealthCheckResult) {
+	*out = HealthCheckResult{
+		Attempts:       in.Attempts,
+		LastAttempt:    in.LastAttempt,
+		LastStatusCode: in.LastStatusCode,
+		LastLatency:    in.LastLatency,
+		LastError:      in.LastError,
+		Passed:         in.Passed,
+	}
+}
+
+func convertHealthCheckResultToV1(in *HealthCheckResult, out *v1.HealthCheckResult) {
+	*out = v1.HealthCheckResult{
+		Attempts:       in.Attempts,
+		LastAttempt:    in.LastAttempt,
+		LastStatusCode: in.LastStatusCode,
+		LastLatency:    in.LastLatency,
+		LastError:      in.LastError,
+		Passed:         in.Passed,
+	}
+}
+
+func convertPreTerminationCheckStatusFromV1(in *v1.PreTerminationCheckStatus, out *PreTerminationCheckStatus) {
+	*out = PreTerminationCheckStatus{
+		Trigger: in.Trigger,
+		Check:   in.Check,
+	}
+}
+
+func convertPreTerminationCheckStatusToV1(in *PreTerminationCheckStatus, out *v1.PreTerminationCheckStatus) {
+	*out = v1.PreTerminationCheckStatus{
+		Trigger: in.Trigger,
+		Check:   in.Check,
+	}
+}
+
+// convertPointer converts the value of a pointer, keeping nil pointers nil
+func convertPointer[In, Out any](in *In, convert func(*In, *Out)) *Out {
+	if in == nil {
+		return nil
+	}
+
+	out := new(Out)
+	convert(in, out)
+	return out
+}
+
+// convertSlice converts each item of a slice, keeping nil slices nil
+func convertSlice[In, Out any](in []In, convert func(*In, *Out)) []Out {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]Out, len(in))
+	for i := range in {
+		convert(&in[i], &out[i])
+	}
+
+	return out
+}
+
+// convertMap converts each value of a map, keeping nil maps nil
+func convertMap[In, Out any](in map[string]In, convert func(*In, *Out)) map[string]Out {
+	if in == nil {
+		return nil
+	}
+
+	out := make(map[string]Out, len(in))
+	for key, value := range in {
+		var converted Out
+		convert(&value, &converted)
+		out[key] = converted
+	}
+
+	return out
+}
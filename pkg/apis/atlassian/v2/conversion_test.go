@@ -0,0 +1,196 @@
+package v2
+
+import (
+	"math/rand"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeserializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+const fuzzIterations = 1000
+
+// newFuzzer fuzzes objects in either version. Node group names are picked from a small set so that duplicated and
+// shared node groups, which v1 can list in more than one way, come up often.
+func newFuzzer(t *testing.T, seed int64) *fuzz.Fuzzer {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, SchemeBuilder.AddToScheme(scheme))
+
+	nodeGroupNames := []string{"", "ng-a", "ng-b"}
+	fuzzNodeGroupName := func(c fuzz.Continue) string {
+		return nodeGroupNames[c.Intn(len(nodeGroupNames))]
+	}
+	fuzzNodeGroupNames := func(c fuzz.Continue) []string {
+		if c.RandBool() {
+			return nil
+		}
+
+		names := make([]string, c.Intn(4))
+		for i := range names {
+			names[i] = fuzzNodeGroupName(c)
+		}
+
+		return names
+	}
+
+	customFuncs := func(runtimeserializer.CodecFactory) []interface{} {
+		return []interface{}{
+			func(spec *v1.CycleNodeRequestSpec, c fuzz.Continue) {
+				c.FuzzNoCustom(spec)
+				spec.NodeGroupName = fuzzNodeGroupName(c)
+				spec.NodeGroupsList = fuzzNodeGroupNames(c)
+			},
+			func(spec *v1.NodeGroupSpec, c fuzz.Continue) {
+				c.FuzzNoCustom(spec)
+				spec.NodeGroupName = fuzzNodeGroupName(c)
+				spec.NodeGroupsList = fuzzNodeGroupNames(c)
+			},
+			func(spec *CycleNodeRequestSpec, c fuzz.Continue) {
+				c.FuzzNoCustom(spec)
+				spec.NodeGroupNames = fuzzNodeGroupNames(c)
+			},
+			func(spec *NodeGroupSpec, c fuzz.Continue) {
+				c.FuzzNoCustom(spec)
+				spec.NodeGroupNames = fuzzNodeGroupNames(c)
+			},
+		}
+	}
+
+	return fuzzer.FuzzerFor(
+		fuzzer.MergeFuzzerFuncs(metafuzzer.Funcs, customFuncs),
+		rand.NewSource(seed),
+		runtimeserializer.NewCodecFactory(scheme),
+	)
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		hub   func() conversion.Hub
+		spoke func() conversion.Convertible
+	}{
+		{
+			"CycleNodeRequest",
+			func() conversion.Hub { return &v1.CycleNodeRequest{} },
+			func() conversion.Convertible { return &CycleNodeRequest{} },
+		},
+		{
+			"CycleNodeStatus",
+			func() conversion.Hub { return &v1.CycleNodeStatus{} },
+			func() conversion.Convertible { return &CycleNodeStatus{} },
+		},
+		{
+			"NodeGroup",
+			func() conversion.Hub { return &v1.NodeGroup{} },
+			func() conversion.Convertible { return &NodeGroup{} },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" v1 to v2 to v1", func(t *testing.T) {
+			f := newFuzzer(t, 1)
+
+			for i := 0; i < fuzzIterations; i++ {
+				hub := test.hub()
+				f.Fuzz(hub)
+
+				spoke := test.spoke()
+				require.NoError(t, spoke.ConvertFrom(hub.DeepCopyObject().(conversion.Hub)))
+
+				roundTripped := test.hub()
+				require.NoError(t, spoke.ConvertTo(roundTripped))
+
+				if !apiequality.Semantic.DeepEqual(hub, roundTripped) {
+					t.Fatalf("round trip changed the object:\n%#v\n%#v", hub, roundTripped)
+				}
+			}
+		})
+
+		t.Run(test.name+" v2 to v1 to v2", func(t *testing.T) {
+			f := newFuzzer(t, 2)
+
+			for i := 0; i < fuzzIterations; i++ {
+				spoke := test.spoke()
+				f.Fuzz(spoke)
+
+				hub := test.hub()
+				require.NoError(t, spoke.DeepCopyObject().(conversion.Convertible).ConvertTo(hub))
+
+				roundTripped := test.spoke()
+				require.NoError(t, roundTripped.ConvertFrom(hub))
+
+				if !apiequality.Semantic.DeepEqual(spoke, roundTripped) {
+					t.Fatalf("round trip changed the object:\n%#v\n%#v", spoke, roundTripped)
+				}
+			}
+		})
+	}
+}
+
+func TestCycleNodeRequestConversion(t *testing.T) {
+	hub := &v1.CycleNodeRequest{
+		ObjectMeta:  metav1.ObjectMeta{Name: "example"},
+		ClusterName: "cluster",
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupName:  "ng-a",
+			NodeGroupsList: []string{"ng-b", "ng-c"},
+			PreTerminationChecks: []v1.PreTerminationCheck{{
+				Endpoint:    "http://trigger",
+				HealthCheck: v1.HealthCheck{Endpoint: "http://health"},
+				TLSConfig:   v1.TLSConfig{RootCA: "ca"},
+			}},
+		},
+	}
+
+	var spoke CycleNodeRequest
+	require.NoError(t, spoke.ConvertFrom(hub))
+
+	assert.Equal(t, []string{"ng-a", "ng-b", "ng-c"}, spoke.Spec.NodeGroupNames)
+	assert.Equal(t, "cluster", spoke.Spec.ClusterName)
+	assert.Equal(t, "http://trigger", spoke.Spec.PreTerminationChecks[0].TriggerEndpoint)
+	assert.Equal(t, "http://health", spoke.Spec.PreTerminationChecks[0].HealthCheck.Endpoint)
+	assert.Equal(t, "ca", spoke.Spec.PreTerminationChecks[0].TLS.RootCA)
+	assert.Empty(t, spoke.Annotations)
+}
+
+func TestNodeGroupsConversionAnnotation(t *testing.T) {
+	hub := &v1.NodeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Annotations: map[string]string{"other": "annotation"}},
+		Spec: v1.NodeGroupSpec{
+			NodeGroupName:  "ng-b",
+			NodeGroupsList: []string{"ng-a", "ng-b"},
+		},
+	}
+
+	var spoke NodeGroup
+	require.NoError(t, spoke.ConvertFrom(hub))
+
+	// The v1 object lists ng-b twice, which is recorded to be restored when converting back
+	assert.Equal(t, []string{"ng-a", "ng-b"}, spoke.Spec.NodeGroupNames)
+	assert.Contains(t, spoke.Annotations, NodeGroupsConversionAnnotation)
+	assert.Equal(t, map[string]string{"other": "annotation"}, hub.Annotations)
+
+	var roundTripped v1.NodeGroup
+	require.NoError(t, spoke.ConvertTo(&roundTripped))
+	assert.Equal(t, hub.Spec.NodeGroupName, roundTripped.Spec.NodeGroupName)
+	assert.Equal(t, hub.Spec.NodeGroupsList, roundTripped.Spec.NodeGroupsList)
+	assert.Equal(t, hub.Annotations, roundTripped.Annotations)
+
+	// Once the node groups are changed in v2 the recorded listing no longer applies
+	spoke.Spec.NodeGroupNames = []string{"ng-c"}
+	require.NoError(t, spoke.ConvertTo(&roundTripped))
+	assert.Equal(t, "ng-c", roundTripped.Spec.NodeGroupName)
+	assert.Empty(t, roundTripped.Spec.NodeGroupsList)
+	assert.Equal(t, hub.Annotations, roundTripped.Annotations)
+}
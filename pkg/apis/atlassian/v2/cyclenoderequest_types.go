@@ -0,0 +1,331 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CycleNodeRequestSpec defines the desired state of CycleNodeRequest
+// +k8s:openapi-gen=true
+type CycleNodeRequestSpec struct {
+	// NodeGroupNames are the names of the node groups in the cloud provider which include the nodes selected by
+	// the selector. They're increased to bring up replacement nodes.
+	NodeGroupNames []string `json:"nodeGroupNames"`
+
+	// ClusterName is the name of the cluster the nodes belong to.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// Selector is the label selector used to select the nodes that are to be terminated
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// NodeNames is an optional list of the names of nodes to rotate. This is used to only
+	// rotate specific nodes belonging to the NodeGroup, rather than every node in the group.
+	// If no node names are provided in NodeNames then the entire node group will be rotated.
+	NodeNames []string `json:"nodeNames,omitempty"`
+
+	// CycleSettings stores the settings to use for cycling the nodes.
+	CycleSettings CycleSettings `json:"cycleSettings"`
+
+	// ValidationOptions stores the settings to use for validating state of nodegroups
+	// in kube and the cloud provider for cycling the nodes.
+	ValidationOptions ValidationOptions `json:"validationOptions,omitempty"`
+
+	// HealthChecks stores the settings to configure instance custom health checks
+	HealthChecks []HealthCheck `json:"healthChecks,omitempty"`
+
+	// ClusterHealthChecks are checked before each batch of nodes is selected for cycling, rather than against a node.
+	// Cycling pauses while any of them fail and the CycleNodeRequest fails if they don't pass within their wait period.
+	ClusterHealthChecks []HealthCheck `json:"clusterHealthChecks,omitempty"`
+
+	// PreTerminationChecks stores the settings to configure instance pre-termination checks
+	PreTerminationChecks []PreTerminationCheck `json:"preTerminationChecks,omitempty"`
+
+	// SkipInitialHealthChecks is an optional flag to skip the initial set of node health checks before cycling begins
+	// This does not affect the health checks performed as part of the pre-termination checks.
+	SkipInitialHealthChecks bool `json:"skipInitialHealthChecks,omitempty"`
+
+	// SkipPreTerminationChecks is an optional flag to skip pre-termination checks during cycling
+	SkipPreTerminationChecks bool `json:"skipPreTerminationChecks,omitempty"`
+}
+
+// CycleNodeRequestStatus defines the observed state of CycleNodeRequest
+// +k8s:openapi-gen=true
+type CycleNodeRequestStatus struct {
+	// Phase stores the current phase of the CycleNodeRequest
+	Phase CycleNodeRequestPhase `json:"phase"`
+
+	// A human readable message indicating details about why the CycleNodeRequest is in this condition.
+	Message string `json:"message"`
+
+	// CurrentNodes stores the current nodes that are being "worked on". Used to batch operations
+	// against the node group in the cloud provider. Once a node is passed off to a CycleNodeStatus
+	// CRD, it is no longer listed here.
+	CurrentNodes []CycleNodeRequestNode `json:"currentNodes,omitempty"`
+
+	// NodesToTerminate stores the old nodes that will be terminated.
+	// The cycling of nodes is considered successful when all of these nodes no longer exist in the cluster.
+	NodesToTerminate []CycleNodeRequestNode `json:"nodesToTerminate,omitempty"`
+
+	// ScaleUpStarted stores the time when the scale up started
+	// This is used to track the time limit of the scale up. If we breach the time limit
+	// we fail the request.
+	ScaleUpStarted *metav1.Time `json:"scaleUpStarted,omitempty"`
+
+	// EquilibriumWaitStarted stores the time when we started waiting for equilibrium of Kube nodes and node group instances.
+	// This is used to give some leeway if we start a request at the same time as a cluster scaling event.
+	// If we breach the time limit we fail the request.
+	EquilibriumWaitStarted *metav1.Time `json:"equilibriumWaitStarted,omitempty"`
+
+	// CapacityWaitStarted stores the time when we started waiting for the cluster to have enough capacity
+	// for the pods on the nodes about to be drained. If we breach the time limit we fail the request.
+	CapacityWaitStarted *metav1.Time `json:"capacityWaitStarted,omitempty"`
+
+	// ClusterHealthCheckStarted stores the time when the cluster health checks started failing before selecting the
+	// next batch of nodes. It is cleared once they pass. If we breach their wait period we fail the request.
+	ClusterHealthCheckStarted *metav1.Time `json:"clusterHealthCheckStarted,omitempty"`
+
+	// ActiveChildren is the active number of CycleNodeStatuses that this CycleNodeRequest was aware of
+	// when it last checked for progress in the cycle operation.
+	ActiveChildren int64 `json:"activeChildren,omitempty"`
+
+	// ThreadTimestamp is the timestamp of the thread in the messaging provider
+	ThreadTimestamp string `json:"threadTimestamp,omitempty"`
+
+	// SelectedNodes stores all selected nodes so that new nodes which are selected are only posted in a notification once
+	SelectedNodes map[string]bool `json:"selectedNodes,omitempty"`
+
+	// NumNodesCycled counts how many nodes have finished being cycled
+	NumNodesCycled int `json:"numNodesCycled,omitempty"`
+
+	// NodesAvailable stores the nodes still available to pick up for cycling from the list of nodes to terminate
+	NodesAvailable []CycleNodeRequestNode `json:"nodesAvailable,omitempty"`
+
+	// HealthChecks keeps track of instance health check information
+	HealthChecks map[string]HealthCheckStatus `json:"healthChecks,omitempty"`
+
+	// PreTerminationChecks keeps track of the instance pre termination check information
+	PreTerminationChecks map[string]PreTerminationCheckStatusList `json:"preTerminationChecks,omitempty"`
+
+	// PreCordonHooks keeps track of the PreCordon lifecycle hooks called for each node
+	PreCordonHooks map[string]LifecycleHookStatusList `json:"preCordonHooks,omitempty"`
+
+	// AnnotatedNodes tracks the names of nodes that Cyclops added the
+	// scale-down-disabled annotation to during cycling. Used for deterministic
+	// cleanup such that only these nodes have their annotations removed during the
+	// Successful or Healing phase. Cleared after cleanup completes.
+	AnnotatedNodes []string `json:"annotatedNodes,omitempty"`
+
+	// TaintedNodes tracks the names of nodes that Cyclops added the drainTaint to during
+	// cycling, so the taint is only removed from these nodes when they are returned to service.
+	TaintedNodes []string `json:"taintedNodes,omitempty"`
+
+	// SkippedNodes stores the nodes which failed to cycle within the MaxFailedNodes budget.
+	// These nodes have been returned to service and will not be retried by this CycleNodeRequest.
+	SkippedNodes []CycleNodeRequestSkippedNode `json:"skippedNodes,omitempty"`
+
+	// NodeAttempts keeps track of the failed attempts at cycling each node, keyed by node name.
+	// Only populated when a RetryPolicy is configured.
+	NodeAttempts map[string]CycleNodeAttemptStatus `json:"nodeAttempts,omitempty"`
+
+	// Conditions describe why the CycleNodeRequest is being held from progressing, e.g. while another
+	// CycleNodeRequest is cycling some of the same nodes, and problems found which could stop it, e.g.
+	// PodDisruptionBudgets which will never allow the nodes to be drained
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CycleNodeRequestNode stores a current node that is being worked on
+type CycleNodeRequestNode struct {
+	// Name of the node
+	Name string `json:"name"`
+
+	// Cloud Provider ID of the node
+	ProviderID string `json:"providerId"`
+
+	// NodeGroupName stores current cloud provider node group name
+	// which this node belongs to
+	NodeGroupName string `json:"nodeGroupName"`
+
+	// Private ip of the instance
+	PrivateIP string `json:"privateIp,omitempty"`
+}
+
+// CycleNodeRequestSkippedNode stores a node which failed to cycle and was returned to service
+type CycleNodeRequestSkippedNode struct {
+	CycleNodeRequestNode `json:",inline"`
+
+	// Reason is the message from the failed CycleNodeStatus for the node
+	Reason string `json:"reason,omitempty"`
+}
+
+// CycleNodeAttemptStatus groups the retry information for a node
+type CycleNodeAttemptStatus struct {
+	// Attempts is the history of failed attempts at cycling the node
+	Attempts []CycleNodeAttempt `json:"attempts,omitempty"`
+
+	// NextAttempt is the time after which a new CycleNodeStatus is created for the node.
+	// It is cleared once the retry has started.
+	NextAttempt *metav1.Time `json:"nextAttempt,omitempty"`
+}
+
+// CycleNodeAttempt records a failed attempt at cycling a node
+type CycleNodeAttempt struct {
+	// Phase is the CycleNodeStatus phase the attempt failed in
+	Phase CycleNodeStatusPhase `json:"phase,omitempty"`
+
+	// Message is the message from the failed CycleNodeStatus
+	Message string `json:"message,omitempty"`
+
+	// Retryable denotes whether the attempt failed due to a transient error
+	Retryable bool `json:"retryable,omitempty"`
+
+	// FailedTimestamp is the time the failed attempt was reaped
+	FailedTimestamp metav1.Time `json:"failedTimestamp"`
+}
+
+// HealthCheckStatus groups all health checks status information for a node
+type HealthCheckStatus struct {
+	// Ready keeps track of the first timestamp at which the node status was reported as "ready"
+	NodeReady *metav1.Time `json:"ready,omitempty"`
+
+	// Checks keeps track of the list of health checks performed on the node and which have already passed
+	Checks []bool `json:"checks,omitempty"`
+
+	// Results records the attempts of each health check performed on the node, in the same order as the
+	// health checks in the spec. This explains why a node's health checks didn't pass.
+	Results []HealthCheckResult `json:"results,omitempty"`
+
+	// Skip denotes whether a node is part of a nodegroup before cycling has begun. If this is the case,
+	// health checks on the instance are skipped, like this only new instances are checked.
+	Skip bool `json:"skip,omitempty"`
+}
+
+// HealthCheckResult records the attempts of a single health check performed on a node
+type HealthCheckResult struct {
+	// Attempts is the number of times the health check has been performed on the node
+	Attempts int64 `json:"attempts,omitempty"`
+
+	// LastAttempt is the timestamp of the most recent attempt
+	LastAttempt *metav1.Time `json:"lastAttempt,omitempty"`
+
+	// LastStatusCode is the status code of the response to the most recent attempt. It is only set
+	// for http and Prometheus health checks.
+	LastStatusCode uint `json:"lastStatusCode,omitempty"`
+
+	// LastLatency is how long the most recent attempt took to complete
+	LastLatency *metav1.Duration `json:"lastLatency,omitempty"`
+
+	// LastError is the error from the most recent attempt. It is cleared when the health check passes.
+	LastError string `json:"lastError,omitempty"`
+
+	// Passed marks the timestamp at which the health check first passed
+	Passed *metav1.Time `json:"passed,omitempty"`
+}
+
+// LifecycleHookStatusList groups all the LifecycleHookStatus for a node
+type LifecycleHookStatusList struct {
+	Hooks []LifecycleHookStatus `json:"hooks,omitempty"`
+}
+
+// PreTerminationCheckStatusList groups all the PreTerminationCheckStatus for a node
+type PreTerminationCheckStatusList struct {
+	Checks []PreTerminationCheckStatus `json:"checks,omitempty"`
+}
+
+// PreTerminationCheckStatus groups all status information for the pre-termination trigger
+// and ensuing heath checks
+type PreTerminationCheckStatus struct {
+	// Trigger marks the timestamp at which the trigger is sent.
+	Trigger *metav1.Time `json:"trigger,omitempty"`
+
+	// Check keeps track of health check result performed on the node
+	Check bool `json:"check,omitempty"`
+}
+
+// CycleNodeRequestPhase is the phase that the cycleNodeRequest is in
+type CycleNodeRequestPhase string
+
+const (
+	// CycleNodeRequestUndefined is for cycleNodeRequests that aren't in any phase
+	CycleNodeRequestUndefined CycleNodeRequestPhase = ""
+
+	// CycleNodeRequestPending is for pending cycleNodeRequests
+	CycleNodeRequestPending CycleNodeRequestPhase = "Pending"
+
+	// CycleNodeRequestFailed is for failed cycleNodeRequests
+	CycleNodeRequestFailed CycleNodeRequestPhase = "Failed"
+
+	// CycleNodeRequestInitialised is for initialised cycleNodeRequests
+	CycleNodeRequestInitialised CycleNodeRequestPhase = "Initialised"
+
+	// CycleNodeRequestScalingUp is for cycleNodeRequests that are scaling up
+	CycleNodeRequestScalingUp CycleNodeRequestPhase = "ScalingUp"
+
+	// CycleNodeRequestCordoningNode is for cycleNodeRequests that are cordoning the current node
+	CycleNodeRequestCordoningNode CycleNodeRequestPhase = "CordoningNode"
+
+	// CycleNodeRequestWaitingTermination is for cycleNodeRequests that are waiting for a current batch of nodes to terminate
+	CycleNodeRequestWaitingTermination CycleNodeRequestPhase = "WaitingTermination"
+
+	// CycleNodeRequestSuccessful is for successful cycleNodeRequests
+	CycleNodeRequestSuccessful CycleNodeRequestPhase = "Successful"
+
+	// CycleNodeRequestPartiallySuccessful is for cycleNodeRequests that finished cycling but skipped
+	// some nodes which failed within the MaxFailedNodes budget
+	CycleNodeRequestPartiallySuccessful CycleNodeRequestPhase = "PartiallySuccessful"
+
+	// CycleNodeRequestHealing is for the state before Failing where cyclops will try to put the cluster back in a consistent state
+	CycleNodeRequestHealing CycleNodeRequestPhase = "Healing"
+)
+
+const (
+	// CycleNodeRequestConditionConflicting is True while the cycleNodeRequest is held in the Pending phase
+	// because another cycleNodeRequest is cycling some of the same nodes
+	CycleNodeRequestConditionConflicting = "Conflicting"
+
+	// CycleNodeRequestReasonOverlappingRequest is the reason the cycleNodeRequest is conflicting
+	CycleNodeRequestReasonOverlappingRequest = "OverlappingCycleNodeRequest"
+
+	// CycleNodeRequestReasonNoOverlap is the reason the cycleNodeRequest is no longer conflicting
+	CycleNodeRequestReasonNoOverlap = "NoOverlap"
+
+	// CycleNodeRequestConditionBlockingPodDisruptionBudgets is True when PodDisruptionBudgets were found in
+	// the Pending phase which will never allow the pods on the nodes to be evicted
+	CycleNodeRequestConditionBlockingPodDisruptionBudgets = "BlockingPodDisruptionBudgets"
+
+	// CycleNodeRequestReasonEvictionNeverAllowed is the reason PodDisruptionBudgets are blocking the nodes
+	CycleNodeRequestReasonEvictionNeverAllowed = "EvictionNeverAllowed"
+
+	// CycleNodeRequestReasonEvictionAllowed is the reason PodDisruptionBudgets are no longer blocking the nodes
+	CycleNodeRequestReasonEvictionAllowed = "EvictionAllowed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CycleNodeRequest is the Schema for the cyclenoderequests API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=cyclenoderequests,shortName=cnr,scope=Namespaced
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Node Groups",type="string",JSONPath=".spec.nodeGroupNames",description="The node groups being cycled"
+// +kubebuilder:printcolumn:name="Method",type="string",JSONPath=".spec.cycleSettings.method",description="The method being used for the cycle operation"
+// +kubebuilder:printcolumn:name="Concurrency",type="integer",JSONPath=".spec.cycleSettings.concurrency",description="Max nodes the request is cycling at once"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase",description="The status of the request"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Age of the request"
+type CycleNodeRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CycleNodeRequestSpec   `json:"spec,omitempty"`
+	Status CycleNodeRequestStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CycleNodeRequestList contains a list of CycleNodeRequest
+type CycleNodeRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CycleNodeRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CycleNodeRequest{}, &CycleNodeRequestList{})
+}
@@ -0,0 +1,7 @@
+// Package v2 contains API Schema definitions for the atlassian v2 API group. It cleans up the v1 schema: node
+// groups are only listed in nodeGroupNames, the cluster name is part of the CycleNodeRequest spec and the request
+// and tls configuration of checks are named fields. v1 remains the storage version, objects are converted between
+// the versions by the conversion webhook served by the manager.
+// +k8s:deepcopy-gen=package,register
+// +groupName=atlassian.com
+package v2
@@ -22,6 +22,9 @@ import (
 const (
 	// separator to use for visually separating cli output
 	separator = "«─»"
+
+	// defaultCyclopsNamespace is the namespace CNRs are created in when --namespace isn't given
+	defaultCyclopsNamespace = "kube-system"
 )
 
 // replaced by ldflags at buildtime
@@ -53,6 +56,15 @@ func NewCycle(version string) kubeplug.Application {
 	}
 }
 
+// Commands returns the subcommands of the cycle plugin
+func (c *cycle) Commands() []kubeplug.Application {
+	return []kubeplug.Application{
+		newRetry(),
+		newPreflight(),
+		newStatus(),
+	}
+}
+
 // Usage returns the cli name and usage template for the help message
 func (*cycle) Usage() string {
 	return `kubectl-cycle --name "cnr-name" <nodegroup names> or`
@@ -86,19 +98,25 @@ kubectl cycle --name example-123 --all -o yaml
 
 # cycle system node group without the initial health checks
 kubectl cycle --name example-123 system --skip-initial-health-checks
+
+# resume a failed CNR from where it stopped
+kubectl cycle retry example-123-system
+
+# check for PodDisruptionBudgets which will block cycling the system node group
+kubectl cycle preflight system
 `
 }
 
 // AddFlags implements adding the extra flags for this kubeplug plugin
 func (c *cycle) AddFlags(cmd *cobra.Command) {
-	c.selectAllFlag = cmd.PersistentFlags().Bool("all", false, "option to allow cycling of all nodegroups")
-	c.dryModeFlag = cmd.PersistentFlags().Bool("dry", false, "option to enable dry mode for applying CNRs")
-	c.cnrNameFlag = cmd.PersistentFlags().String("name", "", "option to specify name prefix of generated CNRs")
-	c.nodesFlag = cmd.PersistentFlags().StringSlice("nodes", nil, "option to specify which nodes of the nodegroup to cycle. Leave empty for all")
-	c.concurrencyOverrideFlag = cmd.PersistentFlags().Int64("concurrency", -1, "option to override concurrency of all CNRs. Set for 0 to skip. -1 or not specified will use values from NodeGroup definition")
-	c.cyclingTimeout = cmd.PersistentFlags().Duration("cycling-timeout", 0*time.Second, "option to set timeout for cycling. Default to controller defined timeout")
-	c.skipInitialHealthChecksFlag = cmd.PersistentFlags().Bool("skip-initial-health-checks", false, "option to skip the initial set of health checks before cycling.")
-	c.skipPreTerminationChecksFlag = cmd.PersistentFlags().Bool("skip-pre-termination-checks", false, "option to skip pre-termination checks during cycling.")
+	c.selectAllFlag = cmd.Flags().Bool("all", false, "option to allow cycling of all nodegroups")
+	c.dryModeFlag = cmd.Flags().Bool("dry", false, "option to enable dry mode for applying CNRs")
+	c.cnrNameFlag = cmd.Flags().String("name", "", "option to specify name prefix of generated CNRs")
+	c.nodesFlag = cmd.Flags().StringSlice("nodes", nil, "option to specify which nodes of the nodegroup to cycle. Leave empty for all")
+	c.concurrencyOverrideFlag = cmd.Flags().Int64("concurrency", -1, "option to override concurrency of all CNRs. Set for 0 to skip. -1 or not specified will use values from NodeGroup definition")
+	c.cyclingTimeout = cmd.Flags().Duration("cycling-timeout", 0*time.Second, "option to set timeout for cycling. Default to controller defined timeout")
+	c.skipInitialHealthChecksFlag = cmd.Flags().Bool("skip-initial-health-checks", false, "option to skip the initial set of health checks before cycling.")
+	c.skipPreTerminationChecksFlag = cmd.Flags().Bool("skip-pre-termination-checks", false, "option to skip pre-termination checks during cycling.")
 }
 
 // Run function called by cobra with args and client ready
@@ -133,53 +151,12 @@ func (c *cycle) Run(plug *kubeplug.Plug) {
 
 // validateListOptions returns if a valid combination of flags and arguments is supplied
 func (c *cycle) validateListOptions() (bool, string) {
-	hasLabelSelector := c.labelSelector() != ""
-	hasArgs := len(c.plug.Args) > 0
-
-	// if --all then no other args should be given
-	if c.selectAll() {
-		return !hasLabelSelector && !hasArgs, "cannot use label selector or cnr names with --all specified"
-	}
-
-	// if not --all, then make sure we have either labels or by name
-	if !hasLabelSelector && !hasArgs {
-		return false, "no selection arguments given. use --all to select all nodegroups"
-	}
-
-	if hasLabelSelector {
-		return !hasArgs, "cannot use both --selector and named arguments at the same time"
-	}
-
-	return true, ""
+	return validateListOptions(c.plug, c.selectAll())
 }
 
 // listNodeGroupsWithOptions lists or gets the nodegroups based on the options in the cli
 func (c *cycle) listNodeGroupsWithOptions() (*atlassianv1.NodeGroupList, error) {
-	// get: by arguments
-	if len(c.plug.Args) > 0 {
-		nodeGroupList, err := generation.GetNodeGroups(c.plug.Client, c.plug.Args...)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to get node groups")
-		}
-		return nodeGroupList, nil
-	}
-
-	// list: by selector - empty selector will get all
-	labelSelector, err := labels.Parse(c.labelSelector())
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse list options for node groups")
-	}
-
-	listOptions := &client.ListOptions{
-		LabelSelector: labelSelector,
-	}
-
-	nodeGroupList, err := generation.ListNodeGroups(c.plug.Client, listOptions)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list node groups")
-	}
-
-	return nodeGroupList, nil
+	return listNodeGroupsWithOptions(c.plug)
 }
 
 // generateCNRs creates the CNRs from the nodegroups and only returns valid ones based on generation.ValidateCNR
@@ -286,20 +263,9 @@ func (c *cycle) cnrName() string {
 	return strings.ToLower(*c.cnrNameFlag)
 }
 
-// labelSelector safely returns the --selector flag
-func (c *cycle) labelSelector() string {
-	if c.plug.ResourceFlags.LabelSelector == nil {
-		return ""
-	}
-	return *c.plug.ResourceFlags.LabelSelector
-}
-
 // cyclopsNamespace safely returns the select --namespace flag with default of kube-system
 func (c *cycle) cyclopsNamespace() string {
-	if c.plug.Namespace == "" {
-		return "kube-system"
-	}
-	return c.plug.Namespace
+	return cyclopsNamespace(c.plug)
 }
 
 // concurrencyOverride safely returns if the user wants to override the concurrency
@@ -335,3 +301,70 @@ func (c *cycle) skipInitialHealthChecks() bool {
 func (c *cycle) skipPreTerminationChecks() bool {
 	return c.skipPreTerminationChecksFlag != nil && *c.skipPreTerminationChecksFlag
 }
+
+// cyclopsNamespace returns the --namespace flag from the plug with default of kube-system
+func cyclopsNamespace(plug *kubeplug.Plug) string {
+	if plug.Namespace == "" {
+		return defaultCyclopsNamespace
+	}
+	return plug.Namespace
+}
+
+// validateListOptions returns if a valid combination of flags and arguments is supplied
+func validateListOptions(plug *kubeplug.Plug, selectAll bool) (bool, string) {
+	hasLabelSelector := labelSelector(plug) != ""
+	hasArgs := len(plug.Args) > 0
+
+	// if --all then no other args should be given
+	if selectAll {
+		return !hasLabelSelector && !hasArgs, "cannot use label selector or cnr names with --all specified"
+	}
+
+	// if not --all, then make sure we have either labels or by name
+	if !hasLabelSelector && !hasArgs {
+		return false, "no selection arguments given. use --all to select all nodegroups"
+	}
+
+	if hasLabelSelector {
+		return !hasArgs, "cannot use both --selector and named arguments at the same time"
+	}
+
+	return true, ""
+}
+
+// listNodeGroupsWithOptions lists or gets the nodegroups based on the options in the cli
+func listNodeGroupsWithOptions(plug *kubeplug.Plug) (*atlassianv1.NodeGroupList, error) {
+	// get: by arguments
+	if len(plug.Args) > 0 {
+		nodeGroupList, err := generation.GetNodeGroups(plug.Client, plug.Args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get node groups")
+		}
+		return nodeGroupList, nil
+	}
+
+	// list: by selector - empty selector will get all
+	selector, err := labels.Parse(labelSelector(plug))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse list options for node groups")
+	}
+
+	listOptions := &client.ListOptions{
+		LabelSelector: selector,
+	}
+
+	nodeGroupList, err := generation.ListNodeGroups(plug.Client, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list node groups")
+	}
+
+	return nodeGroupList, nil
+}
+
+// labelSelector safely returns the --selector flag from the plug
+func labelSelector(plug *kubeplug.Plug) string {
+	if plug.ResourceFlags.LabelSelector == nil {
+		return ""
+	}
+	return *plug.ResourceFlags.LabelSelector
+}
@@ -24,8 +24,20 @@ type Describer interface {
 	Example() string
 }
 
+// ShortDescriber optionally gives a one line description of a subcommand for the root help message
+type ShortDescriber interface {
+	Short() string
+}
+
+// Subcommand describes a child command added to the root command
+type Subcommand struct {
+	Describer
+	Run      func(*cobra.Command, []string)
+	Flaggers []CmdFlagger
+}
+
 // RunOrDie runs the cobra command or panics
-func RunOrDie(usage, version, example string, run func(*cobra.Command, []string), ff []FlagFlagger, cf []CmdFlagger) {
+func RunOrDie(usage, version, example string, run func(*cobra.Command, []string), ff []FlagFlagger, cf []CmdFlagger, subcommands ...Subcommand) {
 	cmd := &cobra.Command{
 		Use:     usage,
 		Version: version,
@@ -36,6 +48,16 @@ func RunOrDie(usage, version, example string, run func(*cobra.Command, []string)
 		},
 	}
 
+	// cobra treats arguments to a root command with subcommands as unknown commands by default
+	if len(subcommands) > 0 {
+		cmd.Args = cobra.ArbitraryArgs
+		cmd.CompletionOptions.DisableDefaultCmd = true
+	}
+
+	for _, subcommand := range subcommands {
+		cmd.AddCommand(newSubcommand(subcommand))
+	}
+
 	flags := cmd.PersistentFlags()
 
 	for _, flagger := range ff {
@@ -50,3 +72,26 @@ func RunOrDie(usage, version, example string, run func(*cobra.Command, []string)
 		os.Exit(1)
 	}
 }
+
+// newSubcommand creates the cobra command for a Subcommand
+func newSubcommand(subcommand Subcommand) *cobra.Command {
+	run := subcommand.Run
+	cmd := &cobra.Command{
+		Use:     subcommand.Usage(),
+		Example: subcommand.Example(),
+
+		Run: func(cmd *cobra.Command, args []string) {
+			run(cmd, args)
+		},
+	}
+
+	if describer, ok := subcommand.Describer.(ShortDescriber); ok {
+		cmd.Short = describer.Short()
+	}
+
+	for _, flagger := range subcommand.Flaggers {
+		flagger.AddFlags(cmd)
+	}
+
+	return cmd
+}
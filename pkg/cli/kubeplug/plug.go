@@ -63,10 +63,16 @@ func App(app Application) {
 	Do(app, app, app)
 }
 
+// Commander defines an interface for a Plugger that also has subcommands, e.g. kubectl cycle retry
+type Commander interface {
+	Commands() []Application
+}
+
 // Do sets up everything for a Describer, Plugger and Flaggers, and parses the cobra command
 // The plugger is then run with the setup Plug
 // Use this method when you want separate components for part or want more than one flagger
 // If your struct implements all interfaces in one place, use App()
+// If the plugger implements Commander, each of its Commands is added as a subcommand sharing the same Plug
 func Do(description command.Describer, plugger Plugger, moreFlags ...command.CmdFlagger) {
 	plug := &Plug{}
 
@@ -78,36 +84,51 @@ func Do(description command.Describer, plugger Plugger, moreFlags ...command.Cmd
 		WithScheme(scheme.Scheme).
 		WithLabelSelector(labels.Everything().String())
 
-	runCmd := func(cmd *cobra.Command, args []string) {
-		plug.Client = k8s.NewCLIClientOrDie(plug.ConfigFlags)
-		plug.Namespace = k8s.NamespaceFlag(cmd)
-		plug.Args = args
+	var subcommands []command.Subcommand
+	if commander, ok := plugger.(Commander); ok {
+		for _, app := range commander.Commands() {
+			subcommands = append(subcommands, command.Subcommand{
+				Describer: app,
+				Run:       plug.runFor(app),
+				Flaggers:  []command.CmdFlagger{app},
+			})
+		}
+	}
+
+	command.RunOrDie(
+		description.Usage(),
+		description.Version(),
+		description.Example(),
+		plug.runFor(plugger),
+		[]command.FlagFlagger{plug.ConfigFlags, plug.ResourceFlags},
+		append([]command.CmdFlagger{plug.PrintFlags}, moreFlags...),
+		subcommands...,
+	)
+}
+
+// runFor returns the cobra run function which sets up the Plug and runs the plugger with it
+func (p *Plug) runFor(plugger Plugger) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		p.Client = k8s.NewCLIClientOrDie(p.ConfigFlags)
+		p.Namespace = k8s.NamespaceFlag(cmd)
+		p.Args = args
 
-		printer, err := plug.PrintFlags.ToPrinter()
+		printer, err := p.PrintFlags.ToPrinter()
 		if err != nil {
 			panic(err)
 		}
-		plug.Printer = printer
+		p.Printer = printer
 
-		plug.CLI = aurora.NewAurora(isatty.IsTerminal(os.Stderr.Fd()))
+		p.CLI = aurora.NewAurora(isatty.IsTerminal(os.Stderr.Fd()))
 
-		plug.IO = genericclioptions.IOStreams{
+		p.IO = genericclioptions.IOStreams{
 			In:     os.Stdin,
 			Out:    os.Stdout,
 			ErrOut: os.Stderr,
 		}
 
-		plugger.Run(plug)
+		plugger.Run(p)
 	}
-
-	command.RunOrDie(
-		description.Usage(),
-		description.Version(),
-		description.Example(),
-		runCmd,
-		[]command.FlagFlagger{plug.ConfigFlags, plug.ResourceFlags},
-		append([]command.CmdFlagger{plug.PrintFlags}, moreFlags...),
-	)
 }
 
 // ResourceVisitor returns a cli-runtime Visitor for listing resources in a functional way
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/atlassian-labs/cyclops/pkg/cli/kubeplug"
+	"github.com/atlassian-labs/cyclops/pkg/generation"
+)
+
+// preflight contains the logic and state to run as a kubectl plugin subcommand to check nodegroups can be cycled
+type preflight struct {
+	plug *kubeplug.Plug
+
+	selectAllFlag *bool
+	nodesFlag     *[]string
+}
+
+// newPreflight returns a new preflight CLI application that implements all the interfaces needed for kubeplug
+func newPreflight() kubeplug.Application {
+	return &preflight{}
+}
+
+// Usage returns the subcommand name and usage template for the help message
+func (*preflight) Usage() string {
+	return "preflight <nodegroup names>"
+}
+
+// Short returns the one line description shown in the root help message
+func (*preflight) Short() string {
+	return "Report PodDisruptionBudgets which will block cycling nodegroups"
+}
+
+// Version returns the version of this plugin, which is shown on the root command
+func (*preflight) Version() string {
+	return ""
+}
+
+// Example returns the detailed examples to display in the help message
+func (*preflight) Example() string {
+	return `
+# check the system node group can be cycled
+kubectl cycle preflight system
+
+# check specific nodes of the system node group can be cycled
+kubectl cycle preflight system --nodes node-1,node-2
+
+# check node groups by labels
+kubectl cycle preflight -l type=default
+
+# check all nodegroups
+kubectl cycle preflight --all
+`
+}
+
+// AddFlags implements adding the extra flags for this kubeplug plugin
+func (p *preflight) AddFlags(cmd *cobra.Command) {
+	p.selectAllFlag = cmd.Flags().Bool("all", false, "option to allow checking of all nodegroups")
+	p.nodesFlag = cmd.Flags().StringSlice("nodes", nil, "option to specify which nodes of the nodegroup to check. Leave empty for all")
+}
+
+// Run function called by cobra with args and client ready
+func (p *preflight) Run(plug *kubeplug.Plug) {
+	p.plug = plug
+
+	if valid, reason := validateListOptions(p.plug, p.selectAll()); !valid {
+		p.plug.MessageFail(fmt.Sprint("invalid list options.. Reason: ", reason))
+	}
+
+	p.plug.MessageLn("fetching nodegroups...")
+	nodeGroupList, err := listNodeGroupsWithOptions(p.plug)
+	if err != nil {
+		p.plug.MessageFail(fmt.Sprint("failed to get all specified node groups: ", err))
+	}
+
+	p.plug.DecorateLn(separator)
+
+	var blockedCount int
+	for _, nodeGroup := range nodeGroupList.Items {
+		cnr := generation.GenerateCNR(nodeGroup, p.nodes(), "", cyclopsNamespace(p.plug))
+
+		blockingPDBs, err := generation.PreflightCNR(p.plug.Client, cnr)
+		if err != nil {
+			p.plug.MessageFail(fmt.Sprint("failed to check ", nodeGroup.Name, ": ", err))
+		}
+
+		if len(blockingPDBs) == 0 {
+			p.plug.Message(fmt.Sprint(p.plug.CLI.Cyan("[checking]"), " ", p.plug.CLI.Yellow(nodeGroup.Name)))
+			p.plug.MessageGreenLn(" OK")
+			continue
+		}
+
+		blockedCount++
+		p.plug.Message(fmt.Sprint(p.plug.CLI.Cyan("[checking]"), " ", p.plug.CLI.Yellow(nodeGroup.Name), " "))
+		p.plug.MessageRedLn("[ blocked ]")
+
+		for _, pdb := range blockingPDBs {
+			p.plug.MessageLn(fmt.Sprint("  PodDisruptionBudget ", p.plug.CLI.Yellow(pdb.Namespace+"/"+pdb.Name), " ", pdb.Reason))
+			p.plug.MessageLn(fmt.Sprint("    blocking pods: ", pdb.Pods))
+		}
+	}
+
+	p.plug.DecorateLn(separator)
+
+	if blockedCount > 0 {
+		p.plug.MessageFail(fmt.Sprintf("%d nodegroups have PodDisruptionBudgets which will never allow their nodes to be drained", blockedCount))
+	}
+
+	p.plug.MessageGreenLn(fmt.Sprintf("DONE! %d nodegroups can be drained", len(nodeGroupList.Items)))
+}
+
+// selectAll safely returns the --all flag
+func (p *preflight) selectAll() bool {
+	return p.selectAllFlag != nil && *p.selectAllFlag
+}
+
+// nodes safely returns the --nodes flag
+func (p *preflight) nodes() []string {
+	if p.nodesFlag == nil || len(*p.nodesFlag) == 0 {
+		return nil
+	}
+	return *p.nodesFlag
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/cli/kubeplug"
+	"github.com/atlassian-labs/cyclops/pkg/generation"
+)
+
+// retry contains the logic and state to run as a kubectl plugin subcommand to resume Failed CNRs
+type retry struct {
+	plug *kubeplug.Plug
+
+	dryModeFlag *bool
+}
+
+// newRetry returns a new retry CLI application that implements all the interfaces needed for kubeplug
+func newRetry() kubeplug.Application {
+	return &retry{}
+}
+
+// Usage returns the subcommand name and usage template for the help message
+func (*retry) Usage() string {
+	return "retry <cnr names>"
+}
+
+// Short returns the one line description shown in the root help message
+func (*retry) Short() string {
+	return "Resume Failed CNRs from where they stopped"
+}
+
+// Version returns the version of this plugin, which is shown on the root command
+func (*retry) Version() string {
+	return ""
+}
+
+// Example returns the detailed examples to display in the help message
+func (*retry) Example() string {
+	return `
+# resume a failed CNR from where it stopped
+kubectl cycle retry example-123-system
+
+# resume failed CNRs in another namespace
+kubectl cycle retry -n cyclops example-123-system example-123-ingress
+
+# test resuming a failed CNR with dry mode
+kubectl cycle retry example-123-system --dry
+`
+}
+
+// AddFlags implements adding the extra flags for this kubeplug plugin
+func (r *retry) AddFlags(cmd *cobra.Command) {
+	r.dryModeFlag = cmd.Flags().Bool("dry", false, "option to enable dry mode for retrying CNRs")
+}
+
+// Run function called by cobra with args and client ready
+func (r *retry) Run(plug *kubeplug.Plug) {
+	r.plug = plug
+
+	if len(r.plug.Args) == 0 {
+		r.plug.MessageFail("no CNR names given to retry")
+	}
+
+	action := "[retrying]"
+	if r.dryMode() {
+		action = "[dry mode]"
+	}
+
+	var successCount int
+	for _, name := range r.plug.Args {
+		r.plug.Message(fmt.Sprint(r.plug.CLI.Cyan(action), " "))
+		r.plug.Message(r.plug.CLI.Yellow(name))
+
+		if err := r.retryCNR(name); err != nil {
+			r.plug.MessageLn("")
+			r.plug.MessageRed("[ failed ] ")
+			r.plug.MessageLn(fmt.Sprint("to retry ", r.plug.CLI.Yellow(name), " because ", err))
+			continue
+		}
+
+		r.plug.MessageGreenLn(" OK")
+		successCount++
+	}
+
+	r.plug.DecorateLn(separator)
+	r.plug.MessageGreenLn(fmt.Sprintf("DONE! Retried %d CNRs successfully", successCount))
+
+	if successCount != len(r.plug.Args) {
+		r.plug.MessageFail(fmt.Sprintf("%d CNRs failed", len(r.plug.Args)-successCount))
+	}
+}
+
+// retryCNR gets the named CNR and asks the controller to resume it
+func (r *retry) retryCNR(name string) error {
+	var cnr atlassianv1.CycleNodeRequest
+	key := client.ObjectKey{Namespace: cyclopsNamespace(r.plug), Name: name}
+
+	if err := r.plug.Client.Get(context.TODO(), key, &cnr); err != nil {
+		return err
+	}
+
+	return generation.RetryCNR(r.plug.Client, r.dryMode(), &cnr)
+}
+
+// dryMode safely returns the --dry flag
+func (r *retry) dryMode() bool {
+	return r.dryModeFlag != nil && *r.dryModeFlag
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/cli/kubeplug"
+)
+
+// status contains the logic and state to run as a kubectl plugin subcommand to describe the progress of CNRs
+type status struct {
+	plug *kubeplug.Plug
+}
+
+// newStatus returns a new status CLI application that implements all the interfaces needed for kubeplug
+func newStatus() kubeplug.Application {
+	return &status{}
+}
+
+// Usage returns the subcommand name and usage template for the help message
+func (*status) Usage() string {
+	return "status <cnr names>"
+}
+
+// Short returns the one line description shown in the root help message
+func (*status) Short() string {
+	return "Show the progress of CNRs and the results of their health checks"
+}
+
+// Version returns the version of this plugin, which is shown on the root command
+func (*status) Version() string {
+	return ""
+}
+
+// Example returns the detailed examples to display in the help message
+func (*status) Example() string {
+	return `
+# show the progress of a CNR and why its health checks haven't passed
+kubectl cycle status example-123-system
+
+# show the progress of CNRs in another namespace
+kubectl cycle status -n cyclops example-123-system example-123-ingress
+`
+}
+
+// AddFlags implements adding the extra flags for this kubeplug plugin, status has no extra flags
+func (*status) AddFlags(*cobra.Command) {}
+
+// Run function called by cobra with args and client ready
+func (s *status) Run(plug *kubeplug.Plug) {
+	s.plug = plug
+
+	if len(s.plug.Args) == 0 {
+		s.plug.MessageFail("no CNR names given to show the status of")
+	}
+
+	var failedCount int
+	for _, name := range s.plug.Args {
+		var cnr atlassianv1.CycleNodeRequest
+		key := client.ObjectKey{Namespace: cyclopsNamespace(s.plug), Name: name}
+
+		s.plug.DecorateLn(separator)
+
+		if err := s.plug.Client.Get(context.TODO(), key, &cnr); err != nil {
+			s.plug.MessageRed("[ failed ] ")
+			s.plug.MessageLn(fmt.Sprint("to get ", s.plug.CLI.Yellow(name), " because ", err))
+			failedCount++
+			continue
+		}
+
+		s.printCNR(cnr)
+	}
+
+	s.plug.DecorateLn(separator)
+
+	if failedCount > 0 {
+		s.plug.MessageFail(fmt.Sprintf("%d CNRs failed", failedCount))
+	}
+}
+
+// printCNR prints the phase and progress of the CNR, followed by the result of each health check performed
+// on the new nodes
+func (s *status) printCNR(cnr atlassianv1.CycleNodeRequest) {
+	s.plug.MessageLn(fmt.Sprint(s.plug.CLI.Cyan("[name]"), " ", s.plug.CLI.Yellow(cnr.Name)))
+	s.plug.MessageLn(fmt.Sprint(s.plug.CLI.Cyan("[phase]"), " ", cnr.Status.Phase))
+	s.plug.MessageLn(fmt.Sprint(s.plug.CLI.Cyan("[cycled]"), " ", fmt.Sprintf("%d/%d", cnr.Status.NumNodesCycled, len(cnr.Status.NodesToTerminate))))
+
+	if cnr.Status.Message != "" {
+		s.plug.MessageLn(fmt.Sprint(s.plug.CLI.Cyan("[message]"), " ", cnr.Status.Message))
+	}
+
+	if conflicting := meta.FindStatusCondition(cnr.Status.Conditions, atlassianv1.CycleNodeRequestConditionConflicting); conflicting != nil && conflicting.Status == metav1.ConditionTrue {
+		s.plug.MessageLn(fmt.Sprint(s.plug.CLI.Cyan("[held]"), " ", conflicting.Message))
+	}
+
+	nodes := make([]string, 0, len(cnr.Status.HealthChecks))
+	for node, healthChecksStatus := range cnr.Status.HealthChecks {
+		if len(healthChecksStatus.Results) > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+
+	if len(nodes) == 0 {
+		return
+	}
+
+	s.plug.MessageLn(s.plug.CLI.Cyan("[health checks]"))
+
+	for _, node := range nodes {
+		s.plug.MessageLn(fmt.Sprint("  ", s.plug.CLI.Yellow(node)))
+
+		for i, result := range cnr.Status.HealthChecks[node].Results {
+			if i >= len(cnr.Spec.HealthChecks) {
+				break
+			}
+
+			s.plug.Message(fmt.Sprint("    ", cnr.Spec.HealthChecks[i].Name(), " "))
+
+			switch {
+			case result.Passed != nil:
+				s.plug.MessageGreen("[ passed ] ")
+			case result.Attempts == 0:
+				s.plug.Message("[ waiting ] ")
+			default:
+				s.plug.MessageRed("[ failing ] ")
+			}
+
+			s.plug.MessageLn(result.Summary())
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 )
@@ -70,6 +72,7 @@ func verifyIfErrorOccurredWithDefaults(apiErr error, expectedMessage string) (bo
 type provider struct {
 	autoScalingService autoscalingiface.AutoScalingAPI
 	ec2Service         ec2iface.EC2API
+	elbv2Service       elbv2iface.ELBV2API
 	logger             logr.Logger
 }
 
@@ -166,6 +169,59 @@ func (p *provider) TerminateInstance(providerID string) error {
 	return err
 }
 
+// InstanceDeregisteredFromLoadBalancers returns whether the instance has been deregistered from all of the
+// instance target groups of application and network load balancers. Targets which are still draining
+// connections are treated as registered.
+func (p *provider) InstanceDeregisteredFromLoadBalancers(providerID string) (bool, error) {
+	if p.elbv2Service == nil {
+		return false, fmt.Errorf("elbv2 client is not configured")
+	}
+
+	instanceID, err := providerIDToInstanceID(providerID)
+	if err != nil {
+		return false, err
+	}
+
+	var targetGroups []*elbv2.TargetGroup
+	err = p.elbv2Service.DescribeTargetGroupsPages(&elbv2.DescribeTargetGroupsInput{},
+		func(output *elbv2.DescribeTargetGroupsOutput, lastPage bool) bool {
+			targetGroups = append(targetGroups, output.TargetGroups...)
+			return true
+		})
+	if err != nil {
+		return false, err
+	}
+
+	for _, targetGroup := range targetGroups {
+		// Only instance targets refer to the instance directly, ip targets are the pods or the node's address
+		if aws.StringValue(targetGroup.TargetType) != elbv2.TargetTypeEnumInstance {
+			continue
+		}
+
+		output, err := p.elbv2Service.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: targetGroup.TargetGroupArn,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, target := range output.TargetHealthDescriptions {
+			if target.Target == nil || aws.StringValue(target.Target.Id) != instanceID {
+				continue
+			}
+			if target.TargetHealth != nil && aws.StringValue(target.TargetHealth.State) == elbv2.TargetHealthStateEnumUnused {
+				continue
+			}
+
+			p.logger.Info("instance is still registered with target group",
+				"instanceID", instanceID, "targetGroup", aws.StringValue(targetGroup.TargetGroupName))
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 // Instances returns a map of all instances in the Autoscaling group
 // with providerID as key and cloudprovider.Instance as value
 func (a *autoscalingGroups) Instances() map[string]cloudprovider.Instance {
@@ -10,6 +10,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
 )
 
 type mockedEC2 struct {
@@ -22,6 +24,22 @@ func (m mockedEC2) DescribeLaunchTemplateVersions(in *ec2.DescribeLaunchTemplate
 	return &m.Resp, nil
 }
 
+type mockedELBV2 struct {
+	elbv2iface.ELBV2API
+	TargetGroups []*elbv2.TargetGroup
+	// TargetHealth maps target group ARNs to the health of the targets registered with them
+	TargetHealth map[string][]*elbv2.TargetHealthDescription
+}
+
+func (m mockedELBV2) DescribeTargetGroupsPages(in *elbv2.DescribeTargetGroupsInput, fn func(*elbv2.DescribeTargetGroupsOutput, bool) bool) error {
+	fn(&elbv2.DescribeTargetGroupsOutput{TargetGroups: m.TargetGroups}, true)
+	return nil
+}
+
+func (m mockedELBV2) DescribeTargetHealth(in *elbv2.DescribeTargetHealthInput) (*elbv2.DescribeTargetHealthOutput, error) {
+	return &elbv2.DescribeTargetHealthOutput{TargetHealthDescriptions: m.TargetHealth[aws.StringValue(in.TargetGroupArn)]}, nil
+}
+
 // Test_providerIDToInstanceID is checking that the regex used is correctly matching the providerID to instanceID format
 // rather than ensuring the correct instanceID format exactly
 func Test_providerIDToInstanceID(t *testing.T) {
@@ -493,3 +511,64 @@ func buildLTInstance(instanceID, configVersion *string) *autoscaling.Instance {
 		},
 	}
 }
+
+func TestProvider_InstanceDeregisteredFromLoadBalancers(t *testing.T) {
+	providerID, instanceID := "aws:///us-west-2b/i-0bdf741206dd9793c", "i-0bdf741206dd9793c"
+
+	targetGroups := []*elbv2.TargetGroup{
+		{TargetGroupArn: aws.String("instance-tg"), TargetGroupName: aws.String("instance-tg"), TargetType: aws.String(elbv2.TargetTypeEnumInstance)},
+		{TargetGroupArn: aws.String("ip-tg"), TargetGroupName: aws.String("ip-tg"), TargetType: aws.String(elbv2.TargetTypeEnumIp)},
+	}
+
+	target := func(id, state string) *elbv2.TargetHealthDescription {
+		return &elbv2.TargetHealthDescription{
+			Target:       &elbv2.TargetDescription{Id: aws.String(id)},
+			TargetHealth: &elbv2.TargetHealth{State: aws.String(state)},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		targetHealth map[string][]*elbv2.TargetHealthDescription
+		expect       bool
+	}{
+		{
+			"registered and healthy",
+			map[string][]*elbv2.TargetHealthDescription{"instance-tg": {target(instanceID, elbv2.TargetHealthStateEnumHealthy)}},
+			false,
+		},
+		{
+			"draining connections",
+			map[string][]*elbv2.TargetHealthDescription{"instance-tg": {target(instanceID, elbv2.TargetHealthStateEnumDraining)}},
+			false,
+		},
+		{
+			"deregistered",
+			map[string][]*elbv2.TargetHealthDescription{"instance-tg": {target("i-anotheridfortest", elbv2.TargetHealthStateEnumHealthy)}},
+			true,
+		},
+		{
+			"unused target",
+			map[string][]*elbv2.TargetHealthDescription{"instance-tg": {target(instanceID, elbv2.TargetHealthStateEnumUnused)}},
+			true,
+		},
+		{
+			"ip target groups are ignored",
+			map[string][]*elbv2.TargetHealthDescription{"ip-tg": {target(instanceID, elbv2.TargetHealthStateEnumHealthy)}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &provider{
+				elbv2Service: mockedELBV2{TargetGroups: targetGroups, TargetHealth: tt.targetHealth},
+				logger:       logr.Discard(),
+			}
+
+			deregistered, err := p.InstanceDeregisteredFromLoadBalancers(providerID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, deregistered)
+		})
+	}
+}
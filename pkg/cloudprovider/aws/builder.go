@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/go-logr/logr"
 )
 
@@ -35,10 +36,12 @@ func NewCloudProvider(logger logr.Logger) (cloudprovider.CloudProvider, error) {
 
 	ec2Service := ec2.New(sess, config)
 	autoScalingService := autoscaling.New(sess, config)
+	elbv2Service := elbv2.New(sess, config)
 
 	p := &provider{
 		autoScalingService: autoScalingService,
 		ec2Service:         ec2Service,
+		elbv2Service:       elbv2Service,
 		logger:             logger,
 	}
 
@@ -26,3 +26,9 @@ type Instance interface {
 	MatchesProviderID(string) bool
 	NodeGroupName() string
 }
+
+// LoadBalancerDeregistrationChecker is implemented by cloud providers which can report whether an instance is
+// still registered with any of the load balancers it was serving traffic for
+type LoadBalancerDeregistrationChecker interface {
+	InstanceDeregisteredFromLoadBalancers(providerID string) (bool, error)
+}
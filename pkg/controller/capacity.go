@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeCapacity tracks the allocatable resources on a node which haven't been requested by pods yet
+type nodeCapacity struct {
+	node   *v1.Node
+	cpu    resource.Quantity
+	memory resource.Quantity
+	pods   int64
+}
+
+// CheckDrainCapacity checks whether the remaining schedulable nodes have enough allocatable cpu, memory
+// and pods to absorb the drainable pods from the named nodes. This is a coarse simulation of the scheduler,
+// placing pods on the first node that fits them while considering taints, node selectors and required node
+// affinity. If a pod can't be placed, the returned reason describes it.
+func (rm *ResourceManager) CheckDrainCapacity(nodeNames []string) (fits bool, reason string, err error) {
+	nodes, err := rm.ListNodes(labels.Everything())
+	if err != nil {
+		return false, "", err
+	}
+
+	podList := &v1.PodList{}
+	if err := rm.Client.List(context.TODO(), podList, &client.ListOptions{}); err != nil {
+		return false, "", err
+	}
+
+	draining := make(map[string]bool, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		draining[nodeName] = true
+	}
+
+	var podsToPlace []v1.Pod
+	podsByNode := make(map[string][]v1.Pod)
+
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		if draining[pod.Spec.NodeName] {
+			if k8s.PodIsDrainable(&pod) {
+				podsToPlace = append(podsToPlace, pod)
+			}
+			continue
+		}
+
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	var capacities []*nodeCapacity
+
+	for i := range nodes {
+		node := &nodes[i]
+		if draining[node.Name] || node.Spec.Unschedulable || !nodeIsReady(node) {
+			continue
+		}
+
+		capacities = append(capacities, newNodeCapacity(node, podsByNode[node.Name]))
+	}
+
+	for _, pod := range podsToPlace {
+		if !placePod(&pod, capacities) {
+			return false, fmt.Sprintf("not enough capacity on the remaining nodes for pod %s/%s", pod.Namespace, pod.Name), nil
+		}
+	}
+
+	return true, "", nil
+}
+
+// newNodeCapacity calculates the capacity left on the node after the requests of the pods on it
+func newNodeCapacity(node *v1.Node, pods []v1.Pod) *nodeCapacity {
+	capacity := &nodeCapacity{
+		node:   node,
+		cpu:    node.Status.Allocatable.Cpu().DeepCopy(),
+		memory: node.Status.Allocatable.Memory().DeepCopy(),
+		pods:   node.Status.Allocatable.Pods().Value(),
+	}
+
+	for _, pod := range pods {
+		requests := podRequests(&pod)
+		capacity.cpu.Sub(*requests.Cpu())
+		capacity.memory.Sub(*requests.Memory())
+		capacity.pods--
+	}
+
+	return capacity
+}
+
+// placePod finds the first node the pod can be scheduled to and takes the pod's requests from its capacity
+func placePod(pod *v1.Pod, capacities []*nodeCapacity) bool {
+	requests := podRequests(pod)
+
+	for _, capacity := range capacities {
+		if capacity.pods < 1 || capacity.cpu.Cmp(*requests.Cpu()) < 0 || capacity.memory.Cmp(*requests.Memory()) < 0 {
+			continue
+		}
+
+		if !podToleratesNodeTaints(pod, capacity.node) || !podMatchesNodeSelector(pod, capacity.node) {
+			continue
+		}
+
+		capacity.cpu.Sub(*requests.Cpu())
+		capacity.memory.Sub(*requests.Memory())
+		capacity.pods--
+		return true
+	}
+
+	return false
+}
+
+// podRequests returns the total cpu and memory requested by the pod. Init containers run one at a time
+// before the other containers, so the effective request is the larger of the largest init container and
+// the sum of the containers.
+func podRequests(pod *v1.Pod) v1.ResourceList {
+	cpu := resource.Quantity{}
+	memory := resource.Quantity{}
+
+	for _, container := range pod.Spec.Containers {
+		cpu.Add(*container.Resources.Requests.Cpu())
+		memory.Add(*container.Resources.Requests.Memory())
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		if container.Resources.Requests.Cpu().Cmp(cpu) > 0 {
+			cpu = container.Resources.Requests.Cpu().DeepCopy()
+		}
+		if container.Resources.Requests.Memory().Cmp(memory) > 0 {
+			memory = container.Resources.Requests.Memory().DeepCopy()
+		}
+	}
+
+	for name, quantity := range pod.Spec.Overhead {
+		switch name {
+		case v1.ResourceCPU:
+			cpu.Add(quantity)
+		case v1.ResourceMemory:
+			memory.Add(quantity)
+		}
+	}
+
+	return v1.ResourceList{
+		v1.ResourceCPU:    cpu,
+		v1.ResourceMemory: memory,
+	}
+}
+
+// podToleratesNodeTaints returns whether the pod tolerates all the NoSchedule and NoExecute taints on the node
+func podToleratesNodeTaints(pod *v1.Pod, node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for _, toleration := range pod.Spec.Tolerations {
+			if toleration.ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+
+		if !tolerated {
+			return false
+		}
+	}
+
+	return true
+}
+
+// podMatchesNodeSelector returns whether the node matches the pod's node selector and the match expressions
+// of its required node affinity. Match fields are not considered.
+func podMatchesNodeSelector(pod *v1.Pod, node *v1.Node) bool {
+	nodeLabels := labels.Set(node.Labels)
+
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(nodeLabels) {
+		return false
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+
+	// The terms are ORed together, and the expressions in each term are ANDed
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for _, term := range terms {
+		selector, err := nodeSelectorTermSelector(term)
+		if err != nil {
+			continue
+		}
+
+		if selector.Matches(nodeLabels) {
+			return true
+		}
+	}
+
+	return len(terms) == 0
+}
+
+// nodeSelectorOperators maps node selector operators to label selector operators
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// nodeSelectorTermSelector converts the match expressions of a node selector term to a label selector
+func nodeSelectorTermSelector(term v1.NodeSelectorTerm) (labels.Selector, error) {
+	selector := labels.NewSelector()
+
+	for _, expression := range term.MatchExpressions {
+		operator, ok := nodeSelectorOperators[expression.Operator]
+		if !ok {
+			return nil, fmt.Errorf("unknown node selector operator %q", expression.Operator)
+		}
+
+		requirement, err := labels.NewRequirement(expression.Key, operator, expression.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		selector = selector.Add(*requirement)
+	}
+
+	return selector, nil
+}
+
+// nodeIsReady returns whether the node has the Ready condition
+func nodeIsReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
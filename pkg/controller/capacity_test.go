@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// buildCapacityNode returns a Ready node with the given allocatable cpu in millicores, memory in bytes and pods
+func buildCapacityNode(name string, cpu, memory, pods int64) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"kubernetes.io/hostname": name},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+				corev1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+				corev1.ResourcePods:   *resource.NewQuantity(pods, resource.DecimalSI),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+// buildCapacityPod returns a running pod on the node requesting the given cpu in millicores and memory in bytes
+func buildCapacityPod(name, nodeName string, cpu, memory int64) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+							corev1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
+
+func TestCheckDrainCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects func() []client.Object
+		fits    bool
+	}{
+		{
+			"pod fits on the remaining node",
+			func() []client.Object {
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<30, 10),
+					buildCapacityPod("pod-1", "old", 500, 1<<28),
+				}
+			},
+			true,
+		},
+		{
+			"pod doesn't fit with the requests of the pods already on the remaining node",
+			func() []client.Object {
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<30, 10),
+					buildCapacityPod("pod-1", "old", 500, 1<<28),
+					buildCapacityPod("pod-2", "new", 600, 1<<28),
+				}
+			},
+			false,
+		},
+		{
+			"pods don't all fit on the remaining node",
+			func() []client.Object {
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<30, 10),
+					buildCapacityPod("pod-1", "old", 600, 1<<28),
+					buildCapacityPod("pod-2", "old", 600, 1<<28),
+				}
+			},
+			false,
+		},
+		{
+			"pods are spread across the remaining nodes",
+			func() []client.Object {
+				return []client.Object{
+					buildCapacityNode("old", 2000, 1<<30, 10),
+					buildCapacityNode("new-1", 1000, 1<<30, 10),
+					buildCapacityNode("new-2", 1000, 1<<30, 10),
+					buildCapacityPod("pod-1", "old", 600, 1<<28),
+					buildCapacityPod("pod-2", "old", 600, 1<<28),
+				}
+			},
+			true,
+		},
+		{
+			"pod doesn't fit because of memory",
+			func() []client.Object {
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<28, 10),
+					buildCapacityPod("pod-1", "old", 100, 1<<29),
+				}
+			},
+			false,
+		},
+		{
+			"pod doesn't fit because of the pods limit",
+			func() []client.Object {
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<30, 1),
+					buildCapacityPod("pod-1", "old", 100, 1<<20),
+					buildCapacityPod("pod-2", "new", 100, 1<<20),
+				}
+			},
+			false,
+		},
+		{
+			"cordoned and not ready nodes are not considered",
+			func() []client.Object {
+				cordoned := buildCapacityNode("cordoned", 1000, 1<<30, 10)
+				cordoned.Spec.Unschedulable = true
+				notReady := buildCapacityNode("not-ready", 1000, 1<<30, 10)
+				notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					cordoned,
+					notReady,
+					buildCapacityPod("pod-1", "old", 100, 1<<20),
+				}
+			},
+			false,
+		},
+		{
+			"daemonset and completed pods on the draining node are ignored",
+			func() []client.Object {
+				daemonSetPod := buildCapacityPod("ds-pod", "old", 100, 1<<20)
+				daemonSetPod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds"}}
+				completedPod := buildCapacityPod("completed", "old", 100, 1<<20)
+				completedPod.Status.Phase = corev1.PodSucceeded
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					daemonSetPod,
+					completedPod,
+				}
+			},
+			true,
+		},
+		{
+			"pod doesn't tolerate the taint on the remaining node",
+			func() []client.Object {
+				tainted := buildCapacityNode("new", 1000, 1<<30, 10)
+				tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "ingress", Effect: corev1.TaintEffectNoSchedule}}
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					tainted,
+					buildCapacityPod("pod-1", "old", 100, 1<<20),
+				}
+			},
+			false,
+		},
+		{
+			"pod tolerates the taint on the remaining node",
+			func() []client.Object {
+				tainted := buildCapacityNode("new", 1000, 1<<30, 10)
+				tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "ingress", Effect: corev1.TaintEffectNoSchedule}}
+				pod := buildCapacityPod("pod-1", "old", 100, 1<<20)
+				pod.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "ingress"}}
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					tainted,
+					pod,
+				}
+			},
+			true,
+		},
+		{
+			"pod node selector doesn't match the remaining node",
+			func() []client.Object {
+				pod := buildCapacityPod("pod-1", "old", 100, 1<<20)
+				pod.Spec.NodeSelector = map[string]string{"kubernetes.io/hostname": "old"}
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<30, 10),
+					pod,
+				}
+			},
+			false,
+		},
+		{
+			"pod required node affinity matches the remaining node",
+			func() []client.Object {
+				pod := buildCapacityPod("pod-1", "old", 100, 1<<20)
+				pod.Spec.Affinity = &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{
+								{MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"other"}},
+								}},
+								{MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"old"}},
+								}},
+							},
+						},
+					},
+				}
+				return []client.Object{
+					buildCapacityNode("old", 1000, 1<<30, 10),
+					buildCapacityNode("new", 1000, 1<<30, 10),
+					pod,
+				}
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := &ResourceManager{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.objects()...).Build(),
+			}
+
+			fits, reason, err := rm.CheckDrainCapacity([]string{"old"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.fits, fits)
+			if !tt.fits {
+				assert.NotEmpty(t, reason)
+			}
+		})
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	pod := buildCapacityPod("pod-1", "node", 100, 1<<20)
+	pod.Spec.Containers = append(pod.Spec.Containers, pod.Spec.Containers[0])
+	pod.Spec.InitContainers = []corev1.Container{
+		{
+			Name: "init",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: *resource.NewMilliQuantity(500, resource.DecimalSI),
+				},
+			},
+		},
+	}
+
+	requests := podRequests(pod)
+	assert.Equal(t, int64(500), requests.Cpu().MilliValue())
+	assert.Equal(t, int64(2<<20), requests.Memory().Value())
+}
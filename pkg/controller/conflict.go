@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+// CycleNodeRequestConflict is another CycleNodeRequest which targets some of the same nodes. Two CycleNodeRequests
+// cycling the same nodes at once fight over the terminate label and the node finalizers.
+type CycleNodeRequestConflict struct {
+	CycleNodeRequest v1.CycleNodeRequest
+	Nodes            []string
+}
+
+// String describes the conflict for messages, e.g. "example (ScalingUp) on nodes node-0, node-1"
+func (c CycleNodeRequestConflict) String() string {
+	phase := c.CycleNodeRequest.Status.Phase
+	if phase == v1.CycleNodeRequestUndefined {
+		phase = v1.CycleNodeRequestPending
+	}
+
+	return fmt.Sprintf("%s (%s) on nodes %s", c.CycleNodeRequest.Name, phase, strings.Join(c.Nodes, ", "))
+}
+
+// GoesFirst returns whether the conflicting CycleNodeRequest cycles its nodes before cnr. A CycleNodeRequest which
+// has left the Pending phase is cycling its nodes already, otherwise the oldest CycleNodeRequest goes first.
+func (c CycleNodeRequestConflict) GoesFirst(cnr *v1.CycleNodeRequest) bool {
+	other := c.CycleNodeRequest
+
+	switch other.Status.Phase {
+	case v1.CycleNodeRequestUndefined, v1.CycleNodeRequestPending:
+	default:
+		return true
+	}
+
+	if !other.CreationTimestamp.Equal(&cnr.CreationTimestamp) {
+		return other.CreationTimestamp.Before(&cnr.CreationTimestamp)
+	}
+
+	return other.Name < cnr.Name
+}
+
+// FindConflictingCycleNodeRequests finds the other unfinished CycleNodeRequests in the namespace of cnr which target
+// any of the same nodes, along with the nodes they have in common
+func FindConflictingCycleNodeRequests(ctx context.Context, c client.Reader, cnr *v1.CycleNodeRequest) ([]CycleNodeRequestConflict, error) {
+	targetNodes, err := CycleNodeRequestTargetNodes(ctx, c, cnr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targetNodes) == 0 {
+		return nil, nil
+	}
+
+	var cycleNodeRequestList v1.CycleNodeRequestList
+	if err := c.List(ctx, &cycleNodeRequestList, &client.ListOptions{Namespace: cnr.Namespace}); err != nil {
+		return nil, err
+	}
+
+	var conflicts []CycleNodeRequestConflict
+
+	for _, other := range cycleNodeRequestList.Items {
+		if other.Name == cnr.Name || other.IsTerminal() {
+			continue
+		}
+
+		otherTargetNodes, err := CycleNodeRequestTargetNodes(ctx, c, &other)
+		if err != nil {
+			return nil, fmt.Errorf("finding the nodes targeted by %s: %w", other.Name, err)
+		}
+
+		var nodes []string
+		for nodeName := range targetNodes {
+			if otherTargetNodes[nodeName] {
+				nodes = append(nodes, nodeName)
+			}
+		}
+
+		if len(nodes) > 0 {
+			sort.Strings(nodes)
+			conflicts = append(conflicts, CycleNodeRequestConflict{CycleNodeRequest: other, Nodes: nodes})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// CycleNodeRequestTargetNodes returns the names of the nodes the CycleNodeRequest cycles. Once they've been selected
+// these are the nodes to terminate, before then they're the nodes matching the selector, limited to the named nodes
+// if there are any.
+func CycleNodeRequestTargetNodes(ctx context.Context, c client.Reader, cnr *v1.CycleNodeRequest) (map[string]bool, error) {
+	targetNodes := make(map[string]bool)
+
+	if len(cnr.Status.NodesToTerminate) > 0 {
+		for _, node := range cnr.Status.NodesToTerminate {
+			targetNodes[node.Name] = true
+		}
+
+		return targetNodes, nil
+	}
+
+	selector, err := cnr.NodeLabelSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeList corev1.NodeList
+	if err := c.List(ctx, &nodeList, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	namedNodes := make(map[string]bool, len(cnr.Spec.NodeNames))
+	for _, nodeName := range cnr.Spec.NodeNames {
+		namedNodes[nodeName] = true
+	}
+
+	for _, node := range nodeList.Items {
+		if len(namedNodes) == 0 || namedNodes[node.Name] {
+			targetNodes[node.Name] = true
+		}
+	}
+
+	return targetNodes, nil
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+)
+
+func TestCycleNodeRequestTargetNodes(t *testing.T) {
+	nodes, err := mock.NewNodegroup("ng-1", 3)
+	require.NoError(t, err)
+
+	c := mock.NewClient(nodes, nil).K8sClient
+
+	cnr := &v1.CycleNodeRequest{
+		Spec: v1.CycleNodeRequestSpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"customer": "kitt"}},
+		},
+	}
+
+	// Every node matching the selector
+	targetNodes, err := CycleNodeRequestTargetNodes(context.Background(), c, cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"ng-1-node-0": true, "ng-1-node-1": true, "ng-1-node-2": true}, targetNodes)
+
+	// Only the named nodes matching the selector
+	cnr.Spec.NodeNames = []string{"ng-1-node-1", "other-node"}
+	targetNodes, err = CycleNodeRequestTargetNodes(context.Background(), c, cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"ng-1-node-1": true}, targetNodes)
+
+	// The nodes to terminate once they've been selected
+	cnr.Status.NodesToTerminate = []v1.CycleNodeRequestNode{{Name: "ng-1-node-2"}}
+	targetNodes, err = CycleNodeRequestTargetNodes(context.Background(), c, cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"ng-1-node-2": true}, targetNodes)
+}
+
+func TestCycleNodeRequestConflictGoesFirst(t *testing.T) {
+	now := time.Now()
+
+	newCNR := func(name string, created time.Time, phase v1.CycleNodeRequestPhase) v1.CycleNodeRequest {
+		return v1.CycleNodeRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Status:     v1.CycleNodeRequestStatus{Phase: phase},
+		}
+	}
+
+	cnr := newCNR("cnr-b", now, v1.CycleNodeRequestPending)
+
+	tests := []struct {
+		name  string
+		other v1.CycleNodeRequest
+		first bool
+	}{
+		{"cycling", newCNR("cnr-c", now.Add(time.Hour), v1.CycleNodeRequestScalingUp), true},
+		{"healing", newCNR("cnr-c", now.Add(time.Hour), v1.CycleNodeRequestHealing), true},
+		{"older pending", newCNR("cnr-c", now.Add(-time.Hour), v1.CycleNodeRequestPending), true},
+		{"newer pending", newCNR("cnr-a", now.Add(time.Hour), v1.CycleNodeRequestPending), false},
+		{"newer undefined", newCNR("cnr-a", now.Add(time.Hour), v1.CycleNodeRequestUndefined), false},
+		{"same age ordered by name", newCNR("cnr-a", now, v1.CycleNodeRequestPending), true},
+		{"same age ordered by name after", newCNR("cnr-c", now, v1.CycleNodeRequestPending), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conflict := CycleNodeRequestConflict{CycleNodeRequest: tc.other, Nodes: []string{"node-0"}}
+			assert.Equal(t, tc.first, conflict.GoesFirst(&cnr))
+		})
+	}
+}
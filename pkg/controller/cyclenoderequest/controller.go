@@ -89,37 +89,15 @@ func NewReconciler(
 	return reconciler, nil
 }
 
-// Validates the tls configuration for a pre-termination check or healthcheck and
-// returns an error if these are misconfigured
-// There are 3 valid modes:
-// No fields:   no TLS
-// CA only:     TLS
-// All fields:  mTLS
+// Validates the tls configuration held in environment variables for a pre-termination check or healthcheck
+// and returns an error if these are misconfigured. tls material referenced in Secrets and ConfigMaps is
+// validated the same way when it's read at check time.
 func tlsCertsValid(tlsConfig v1.TLSConfig) error {
-	// Check that either both the the tls cert and private key are added or missing
-	// If they are both added, cyclops will forward them when making requests for mTLS
-	// If they are both missing, they will not be added. No mTLS.
-	rootCA := os.Getenv(tlsConfig.RootCA)
-	certificate := os.Getenv(tlsConfig.Certificate)
-	key := os.Getenv(tlsConfig.Key)
-
-	if (certificate == "" && key != "") || (certificate != "" && key == "") {
-		return fmt.Errorf("cert or key missing, ensure neither are missing for mTLS")
-	}
-
-	// Check that if the certificate and key and both present, the root CA must also
-	// be present. At this point the certificate and key are either both present or
-	// missing so checking one or the other is the same thing.
-	if rootCA == "" && certificate != "" {
-		return fmt.Errorf("the cert and key are both added but the root CA is missing, mTLS will fail")
-	}
-
-	return nil
+	return cyclecontroller.ValidateTLSMaterial(cyclecontroller.EnvTLSMaterial(tlsConfig))
 }
 
 // apiVersionCheckResult holds the outcome of checkAPIVersionCompatibility.
 type apiVersionCheckResult struct {
-
 	skipCheck bool
 	// failed indicates the CNR should be marked as Failed.
 	failed bool
@@ -222,6 +200,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		}
 	}
 
+	for i, healthCheck := range cycleNodeRequest.Spec.ClusterHealthChecks {
+		if len(healthCheck.ValidStatusCodes) == 0 {
+			cycleNodeRequest.Spec.ClusterHealthChecks[i].ValidStatusCodes = []uint{200}
+		}
+
+		// Validate the tls certs before starting to cycle. The certs are optional.
+		if err := tlsCertsValid(healthCheck.TLSConfig); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	if len(cycleNodeRequest.Spec.HealthChecks) > 0 && cycleNodeRequest.Status.HealthChecks == nil {
 		cycleNodeRequest.Status.HealthChecks = make(map[string]v1.HealthCheckStatus)
 	}
@@ -1,19 +1,36 @@
 package transitioner
 
 import (
+	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"encoding/json"
 	"fmt"
-	"html/template"
+	"hash/fnv"
 	"io"
+	"net"
 	"net/http"
-	"os"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 // getNodeHash generates a unique name for the node by combining the node name and provider ID
@@ -49,59 +66,21 @@ func getCycleRequestNode(kubeNode corev1.Node) v1.CycleNodeRequestNode {
 // It will render a string and replace {{ .NodeIP }} with the node private IP. If this is not present,
 // then the endpoint returned will be identical to the input
 func buildHealthCheckEndpoint(node v1.CycleNodeRequestNode, endpoint string) (string, error) {
-	tmpl, err := template.New("endpoint").Parse(endpoint)
-	if err != nil {
-		return "", err
-	}
-
-	// Ensure other fields cannot be rendered to the filter
-	tmplStruct := struct {
-		NodeIP string
-	}{
-		NodeIP: node.PrivateIP,
-	}
-
-	var renderedEndpoint strings.Builder
-	if err = tmpl.Execute(&renderedEndpoint, tmplStruct); err != nil {
-		return "", err
-	}
-
-	return renderedEndpoint.String(), nil
+	return controller.RenderNodeEndpoint(node, endpoint)
 }
 
-// Build a http client which contains the root CA and certs configured as environment
-// variables. The environment variables have already been validated, need to check
-// again in here.
-func (t *CycleNodeRequestTransitioner) buildHttpClient(tlsConfig v1.TLSConfig) (*http.Client, error) {
-	config := &tls.Config{}
-
-	rootCA, ok := os.LookupEnv(tlsConfig.RootCA)
-	if ok {
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM([]byte(rootCA))
-		config.RootCAs = caCertPool
-	}
-
-	// Both will be either configured or missing
-	certificate, ok := os.LookupEnv(tlsConfig.Certificate)
-	key, _ := os.LookupEnv(tlsConfig.Key)
-
-	if ok {
-		cert, err := tls.X509KeyPair([]byte(certificate), []byte(key))
-		if err != nil {
-			return nil, fmt.Errorf("failed to load certs for client: %v", err)
-		}
+// truncateBody shortens a response body so that it can be included in a health check error
+func truncateBody(body []byte) string {
+	return truncate(string(body), maxHealthCheckBodyLength)
+}
 
-		config.Certificates = []tls.Certificate{cert}
+// truncate shortens the string to at most length bytes, marking where it was cut
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
 	}
 
-	// Return the configured client and add the timeout from the "default" client
-	return &http.Client{
-		Timeout: t.rm.HttpClient.Timeout,
-		Transport: &http.Transport{
-			TLSClientConfig: config,
-		},
-	}, nil
+	return strings.ToValidUTF8(s[:length-len(truncatedSuffix)], "") + truncatedSuffix
 }
 
 // healthCheckPassed checks if the statusCode returned matches the set of valid status code for the health check
@@ -114,7 +93,13 @@ func healthCheckPassed(healthCheck v1.HealthCheck, statusCode uint, body []byte)
 	}
 
 	if healthCheck.RegexMatch != "" && !r.Match(body) {
-		return fmt.Errorf("regex %s did not match body %s", healthCheck.RegexMatch, string(body))
+		return fmt.Errorf("regex %s did not match body %s", healthCheck.RegexMatch, truncateBody(body))
+	}
+
+	if healthCheck.JSONPathMatch != nil {
+		if err := jsonPathMatched(*healthCheck.JSONPathMatch, body); err != nil {
+			return err
+		}
 	}
 
 	for _, validStatusCode := range healthCheck.ValidStatusCodes {
@@ -126,14 +111,86 @@ func healthCheckPassed(healthCheck v1.HealthCheck, statusCode uint, body []byte)
 	return fmt.Errorf("status code %d returned, did not match expected %v", statusCode, healthCheck.ValidStatusCodes)
 }
 
+// jsonPathMatched checks the JSONPath finds the expected value in the json body
+func jsonPathMatched(assertion v1.JSONPathAssertion, body []byte) error {
+	jp := jsonpath.New("jsonPathMatch")
+	if err := jp.Parse(assertion.Path); err != nil {
+		return fmt.Errorf("invalid json path %s: %v", assertion.Path, err)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("body %s is not json: %v", truncateBody(body), err)
+	}
+
+	var result strings.Builder
+	if err := jp.Execute(&result, data); err != nil {
+		return fmt.Errorf("json path %s did not match body %s: %v", assertion.Path, truncateBody(body), err)
+	}
+
+	if assertion.Value != "" && result.String() != assertion.Value {
+		return fmt.Errorf("json path %s returned %q, expected %q", assertion.Path, result.String(), assertion.Value)
+	}
+
+	return nil
+}
+
 // makeRequest makes the health check request to the endpoint specified, reads the body and returns
 // the status code/body to determinate whether it passed
 func (t *CycleNodeRequestTransitioner) makeRequest(httpMethod string, httpClient *http.Client, endpoint string) (uint, []byte, error) {
-	httpReq, err := http.NewRequest(httpMethod, endpoint, nil)
+	return t.makeCheckRequest(v1.CycleNodeRequestNode{}, httpClient, endpoint, v1.HTTPRequest{Method: httpMethod})
+}
+
+// getCheckSecretValue reads the value referenced by the request of a check, only reading the Secrets the operator
+// has allowed
+func (t *CycleNodeRequestTransitioner) getCheckSecretValue(ref v1.SecretKeyReference) (string, error) {
+	if err := controller.ValidateCheckSecretReference(t.options.HealthCheckCredentialSecrets, ref); err != nil {
+		return "", err
+	}
+	return t.rm.GetSecretValue(t.cycleNodeRequest.Namespace, ref)
+}
+
+// makeCheckRequest makes the request configured for a check to the endpoint specified, adding the headers, body and
+// bearer token. Values held in Secrets are read when the request is made. It reads the body and returns the
+// status code/body to determinate whether it passed
+func (t *CycleNodeRequestTransitioner) makeCheckRequest(node v1.CycleNodeRequestNode, httpClient *http.Client, endpoint string, request v1.HTTPRequest) (uint, []byte, error) {
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if request.Body != "" {
+		renderedBody, err := renderNodeText(node, request.Body)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to build request body: %v", err)
+		}
+		body = strings.NewReader(renderedBody)
+	}
+
+	httpReq, err := http.NewRequest(method, endpoint, body)
 	if err != nil {
 		return 0, nil, err
 	}
 
+	for _, header := range request.Headers {
+		value := header.Value
+		if header.ValueFrom != nil {
+			if value, err = t.getCheckSecretValue(*header.ValueFrom); err != nil {
+				return 0, nil, fmt.Errorf("failed to get value of header %s: %v", header.Name, err)
+			}
+		}
+		httpReq.Header.Add(header.Name, value)
+	}
+
+	if request.BearerTokenSecretRef != nil {
+		token, err := t.getCheckSecretValue(*request.BearerTokenSecretRef)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to get bearer token: %v", err)
+		}
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", strings.TrimSpace(token)))
+	}
+
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
 		return 0, nil, err
@@ -150,43 +207,598 @@ func (t *CycleNodeRequestTransitioner) makeRequest(httpMethod string, httpClient
 }
 
 // performHealthCheck builds the endpoint, checks that the waiting period han't been exceeded and then makes the
-// request for the health checks. It will finally return whether the health check passed.
-func (t *CycleNodeRequestTransitioner) performHealthCheck(node v1.CycleNodeRequestNode, healthCheck v1.HealthCheck, anchorTime *metav1.Time) (bool, error) {
+// request for the health checks. It will finally return whether the health check passed. The status code of the
+// response is also returned for http and Prometheus health checks, it is 0 for all other types.
+func (t *CycleNodeRequestTransitioner) performHealthCheck(node v1.CycleNodeRequestNode, healthCheck v1.HealthCheck, anchorTime *metav1.Time) (uint, bool, error) {
+	var errorAllowed bool
+	var err error
+
+	switch healthCheck.Type {
+	case v1.HealthCheckJob:
+		errorAllowed, err = t.performJobHealthCheck(node, healthCheck, anchorTime)
+		return 0, errorAllowed, err
+	case v1.HealthCheckNodeReadiness:
+		errorAllowed, err = t.performNodeReadinessHealthCheck(node, healthCheck, anchorTime)
+		return 0, errorAllowed, err
+	}
+
 	endpoint, err := buildHealthCheckEndpoint(node, healthCheck.Endpoint)
 	if err != nil {
-		return false, fmt.Errorf("failed to build health check endpoint: %v", err)
+		return 0, false, fmt.Errorf("failed to build health check endpoint: %v", err)
 	}
 
 	// If the wait period has been exceeded, the health check is considered to have failed
 	// Only perform this check if the anchor time is supplied
 	if anchorTime != nil && anchorTime.Add(healthCheck.WaitPeriod.Duration).Before(metav1.Now().Time) {
-		return false, fmt.Errorf("health check %s failed: didn't become healthy in time", endpoint)
+		return 0, false, fmt.Errorf("health check %s failed: didn't become healthy in time", endpoint)
 	}
 
-	httpClient, err := t.buildHttpClient(healthCheck.TLSConfig)
+	switch healthCheck.Type {
+	case v1.HealthCheckGRPC:
+		errorAllowed, err = t.performGRPCHealthCheck(endpoint, healthCheck)
+		return 0, errorAllowed, err
+	case v1.HealthCheckTCP:
+		errorAllowed, err = t.performTCPHealthCheck(endpoint, healthCheck)
+		return 0, errorAllowed, err
+	case v1.HealthCheckPrometheus:
+		return t.performPrometheusHealthCheck(node, endpoint, healthCheck)
+	}
+
+	httpClient, err := t.rm.BuildHttpClient(t.cycleNodeRequest.Namespace, healthCheck.TLSConfig)
 	if err != nil {
-		return false, fmt.Errorf("failed to build http client: %v", err)
+		return 0, false, fmt.Errorf("failed to build http client: %v", err)
 	}
 
 	// Perform the health check and log any error but don't fail the cycle
 	// If a workload which is being checked has not started up yet, it should be allowed time to do so
 	// as configured in the Nodegroup spec
-	statusCode, body, err := t.makeRequest(http.MethodGet, httpClient, endpoint)
+	statusCode, body, err := t.makeCheckRequest(node, httpClient, endpoint, healthCheck.HTTPRequest)
 	if err != nil {
 		t.rm.Logger.Error(err, "Health check failed", "endpoint", endpoint, "error", err)
-		return true, fmt.Errorf("health check failed: %v", err)
+		return 0, true, fmt.Errorf("health check failed: %v", err)
 	}
 
 	// Still within the waiting period here, must trigger requeueing this phase
 	if err := healthCheckPassed(healthCheck, statusCode, body); err != nil {
 		t.rm.Logger.Error(err, "Health check did not pass", "endpoint", endpoint, "error", err)
-		return true, fmt.Errorf("health check did not pass for the endpoint %s, got: (%d) %s", endpoint, statusCode, string(body))
+		return statusCode, true, fmt.Errorf("health check did not pass for the endpoint %s, got: (%d) %s", endpoint, statusCode, truncateBody(body))
 	}
 
 	t.rm.Logger.Info("Health check passed", "endpoint", endpoint)
+	return statusCode, true, nil
+}
+
+// performGRPCHealthCheck calls the gRPC health checking protocol on the endpoint and checks the service is serving
+func (t *CycleNodeRequestTransitioner) performGRPCHealthCheck(endpoint string, healthCheck v1.HealthCheck) (bool, error) {
+	creds := insecure.NewCredentials()
+	if controller.TLSConfigured(healthCheck.TLSConfig) {
+		tlsConfig, err := t.rm.BuildTLSConfig(t.cycleNodeRequest.Namespace, healthCheck.TLSConfig)
+		if err != nil {
+			return false, fmt.Errorf("failed to build tls config: %v", err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return false, fmt.Errorf("failed to build grpc client: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := t.healthCheckContext()
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: healthCheck.GRPCService})
+	if err != nil {
+		t.rm.Logger.Error(err, "Health check failed", "endpoint", endpoint, "error", err)
+		return true, fmt.Errorf("health check failed: %v", err)
+	}
+
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		err := fmt.Errorf("health check did not pass for the endpoint %s, got: %s", endpoint, resp.Status)
+		t.rm.Logger.Error(err, "Health check did not pass", "endpoint", endpoint, "error", err)
+		return true, err
+	}
+
+	t.rm.Logger.Info("Health check passed", "endpoint", endpoint)
+	return true, nil
+}
+
+// performTCPHealthCheck checks that a tcp connection can be opened to the endpoint, completing a tls handshake if
+// tls is configured
+func (t *CycleNodeRequestTransitioner) performTCPHealthCheck(endpoint string, healthCheck v1.HealthCheck) (bool, error) {
+	dialer := &net.Dialer{Timeout: t.rm.HttpClient.Timeout}
+
+	var conn net.Conn
+	var err error
+	if controller.TLSConfigured(healthCheck.TLSConfig) {
+		tlsConfig, tlsErr := t.rm.BuildTLSConfig(t.cycleNodeRequest.Namespace, healthCheck.TLSConfig)
+		if tlsErr != nil {
+			return false, fmt.Errorf("failed to build tls config: %v", tlsErr)
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", endpoint, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", endpoint)
+	}
+
+	if err != nil {
+		t.rm.Logger.Error(err, "Health check failed", "endpoint", endpoint, "error", err)
+		return true, fmt.Errorf("health check failed: %v", err)
+	}
+	_ = conn.Close()
+
+	t.rm.Logger.Info("Health check passed", "endpoint", endpoint)
+	return true, nil
+}
+
+// prometheusQueryResponse is the subset of the Prometheus instant query api response used by health checks
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// performPrometheusHealthCheck evaluates the query of the health check against the Prometheus api at the endpoint
+// and checks that every sample returned meets the threshold
+func (t *CycleNodeRequestTransitioner) performPrometheusHealthCheck(node v1.CycleNodeRequestNode, endpoint string, healthCheck v1.HealthCheck) (uint, bool, error) {
+	if healthCheck.Prometheus == nil {
+		return 0, false, fmt.Errorf("prometheus health check %s has no query configured", endpoint)
+	}
+
+	threshold, err := strconv.ParseFloat(healthCheck.Prometheus.Threshold, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid prometheus health check threshold %q: %v", healthCheck.Prometheus.Threshold, err)
+	}
+
+	query, err := renderNodeText(node, healthCheck.Prometheus.Query)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to build prometheus query: %v", err)
+	}
+
+	httpClient, err := t.rm.BuildHttpClient(t.cycleNodeRequest.Namespace, healthCheck.TLSConfig)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to build http client: %v", err)
+	}
+
+	// The query is always a GET request, but can be sent with the headers and bearer token configured
+	request := healthCheck.HTTPRequest
+	request.Method = http.MethodGet
+	request.Body = ""
+
+	queryEndpoint := fmt.Sprintf("%s/api/v1/query?%s", strings.TrimSuffix(endpoint, "/"), url.Values{"query": {query}}.Encode())
+	statusCode, body, err := t.makeCheckRequest(node, httpClient, queryEndpoint, request)
+	if err != nil {
+		t.rm.Logger.Error(err, "Health check failed", "endpoint", endpoint, "query", query, "error", err)
+		return 0, true, fmt.Errorf("health check failed: %v", err)
+	}
+
+	if statusCode != http.StatusOK {
+		err := fmt.Errorf("prometheus query %s failed, got: (%d) %s", query, statusCode, truncateBody(body))
+		t.rm.Logger.Error(err, "Health check did not pass", "endpoint", endpoint, "query", query, "error", err)
+		return statusCode, true, err
+	}
+
+	samples, err := parsePrometheusSamples(body)
+	if err != nil {
+		t.rm.Logger.Error(err, "Health check did not pass", "endpoint", endpoint, "query", query, "error", err)
+		return statusCode, true, fmt.Errorf("prometheus query %s failed: %v", query, err)
+	}
+
+	// Still within the waiting period here, must trigger requeueing this phase
+	if err := prometheusSamplesPassed(*healthCheck.Prometheus, threshold, samples); err != nil {
+		t.rm.Logger.Error(err, "Health check did not pass", "endpoint", endpoint, "query", query, "error", err)
+		return statusCode, true, fmt.Errorf("health check did not pass for the query %s: %v", query, err)
+	}
+
+	t.rm.Logger.Info("Health check passed", "endpoint", endpoint, "query", query)
+	return statusCode, true, nil
+}
+
+// renderNodeText renders a Prometheus query or request body, replacing {{ .NodeName }} and {{ .NodeIP }} with the
+// name and private IP of the node. They are rendered as text so operators and quotes in them aren't escaped.
+func renderNodeText(node v1.CycleNodeRequestNode, text string) (string, error) {
+	tmpl, err := template.New("text").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure other fields cannot be rendered to the text
+	tmplStruct := struct {
+		NodeName string
+		NodeIP   string
+	}{
+		NodeName: node.Name,
+		NodeIP:   node.PrivateIP,
+	}
+
+	var rendered strings.Builder
+	if err = tmpl.Execute(&rendered, tmplStruct); err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+// parsePrometheusSamples returns the values of the samples in a Prometheus instant query response. Vector and
+// scalar results are supported.
+func parsePrometheusSamples(body []byte) ([]float64, error) {
+	var resp prometheusQueryResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("query returned status %s: %s", resp.Status, resp.Error)
+	}
+
+	// Each value is a [timestamp, "value"] pair
+	var values [][]interface{}
+
+	switch resp.Data.ResultType {
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(resp.Data.Result, &vector); err != nil {
+			return nil, fmt.Errorf("failed to parse vector result: %v", err)
+		}
+		for _, sample := range vector {
+			values = append(values, sample.Value)
+		}
+	case "scalar":
+		var scalar []interface{}
+		if err := json.Unmarshal(resp.Data.Result, &scalar); err != nil {
+			return nil, fmt.Errorf("failed to parse scalar result: %v", err)
+		}
+		values = append(values, scalar)
+	default:
+		return nil, fmt.Errorf("unsupported result type %q", resp.Data.ResultType)
+	}
+
+	samples := make([]float64, 0, len(values))
+	for _, value := range values {
+		if len(value) != 2 {
+			return nil, fmt.Errorf("invalid sample %v", value)
+		}
+
+		str, ok := value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample value %v", value[1])
+		}
+
+		sample, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample value %q: %v", str, err)
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// prometheusSamplesPassed checks that every sample compares to the threshold with the operator of the health check
+func prometheusSamplesPassed(check v1.PrometheusHealthCheck, threshold float64, samples []float64) error {
+	if len(samples) == 0 {
+		if check.AllowEmptyResult {
+			return nil
+		}
+		return fmt.Errorf("query returned no samples")
+	}
+
+	for _, sample := range samples {
+		var passed bool
+
+		switch check.Operator {
+		case v1.PrometheusLessThan:
+			passed = sample < threshold
+		case v1.PrometheusLessThanOrEqual:
+			passed = sample <= threshold
+		case v1.PrometheusGreaterThan:
+			passed = sample > threshold
+		case v1.PrometheusGreaterThanOrEqual:
+			passed = sample >= threshold
+		case v1.PrometheusEqual:
+			passed = sample == threshold
+		default:
+			return fmt.Errorf("unknown operator %q", check.Operator)
+		}
+
+		if !passed {
+			return fmt.Errorf("sample %v is not %s %v", sample, check.Operator, threshold)
+		}
+	}
+
+	return nil
+}
+
+// healthCheckContext returns a context with the timeout from the "default" http client, for health checks which
+// don't use http
+func (t *CycleNodeRequestTransitioner) healthCheckContext() (context.Context, context.CancelFunc) {
+	if t.rm.HttpClient.Timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), t.rm.HttpClient.Timeout)
+}
+
+// performJobHealthCheck runs the Job of the health check on the node, checks that the waiting period hasn't been
+// exceeded and then returns whether the Job has succeeded.
+func (t *CycleNodeRequestTransitioner) performJobHealthCheck(node v1.CycleNodeRequestNode, healthCheck v1.HealthCheck, anchorTime *metav1.Time) (bool, error) {
+	if healthCheck.Job == nil {
+		return false, fmt.Errorf("job health check for node %s has no job configured", node.Name)
+	}
+
+	// If the wait period has been exceeded, the health check is considered to have failed
+	// Only perform this check if the anchor time is supplied
+	if anchorTime != nil && anchorTime.Add(healthCheck.WaitPeriod.Duration).Before(metav1.Now().Time) {
+		return false, fmt.Errorf("health check job for node %s failed: didn't succeed in time", node.Name)
+	}
+
+	// Only run Jobs as the service accounts the operator has allowed, they're created in the namespace of the
+	// CycleNodeRequest which is usually kube-system
+	if serviceAccount := healthCheck.Job.ServiceAccountName; serviceAccount != "" &&
+		!slices.Contains(t.options.HealthCheckJobServiceAccounts, serviceAccount) {
+		return false, fmt.Errorf("health check job for node %s can't run as service account %s, it must be one of %v",
+			node.Name, serviceAccount, t.options.HealthCheckJobServiceAccounts)
+	}
+
+	job, err := t.runHealthCheckJob(node, *healthCheck.Job)
+	if err != nil {
+		t.rm.Logger.Error(err, "Health check job failed to run", "node", node.Name, "error", err)
+		return true, fmt.Errorf("health check job failed to run: %v", err)
+	}
+
+	// Finished Jobs are deleted so that a later attempt at the health check, e.g. once a failed
+	// CycleNodeRequest is retried, runs the Job again rather than using the result of this one
+	if job.Status.Succeeded > 0 {
+		if err := t.deleteHealthCheckJob(job); err != nil {
+			return true, err
+		}
+
+		t.rm.Logger.Info("Health check job passed", "job", job.Name, "node", node.Name)
+		return true, nil
+	}
+
+	// The Job has run out of retries, it won't succeed any more
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			if err := t.deleteHealthCheckJob(job); err != nil {
+				return true, err
+			}
+
+			return false, fmt.Errorf("health check job %s failed for node %s: %s", job.Name, node.Name, condition.Message)
+		}
+	}
+
+	return true, fmt.Errorf("health check job %s has not succeeded yet for node %s", job.Name, node.Name)
+}
+
+// performNodeReadinessHealthCheck checks that the waiting period hasn't been exceeded and then returns whether the
+// node has reached the state required by the health check.
+func (t *CycleNodeRequestTransitioner) performNodeReadinessHealthCheck(node v1.CycleNodeRequestNode, healthCheck v1.HealthCheck, anchorTime *metav1.Time) (bool, error) {
+	if healthCheck.NodeReadiness == nil {
+		return false, fmt.Errorf("node readiness health check for node %s has no requirements configured", node.Name)
+	}
+
+	// If the wait period has been exceeded, the health check is considered to have failed
+	// Only perform this check if the anchor time is supplied
+	if anchorTime != nil && anchorTime.Add(healthCheck.WaitPeriod.Duration).Before(metav1.Now().Time) {
+		return false, fmt.Errorf("node readiness health check for node %s failed: didn't become ready in time", node.Name)
+	}
+
+	kubeNode, err := t.rm.GetNode(node.Name)
+	if err != nil {
+		t.rm.Logger.Error(err, "Failed to get node for readiness health check", "node", node.Name, "error", err)
+		return true, fmt.Errorf("failed to get node %s: %v", node.Name, err)
+	}
+
+	var unmet []string
+	readiness := healthCheck.NodeReadiness
+
+	for _, requirement := range readiness.Conditions {
+		if !nodeHasCondition(kubeNode, requirement) {
+			unmet = append(unmet, fmt.Sprintf("condition %s is not %s", requirement.Type, requirement.Status))
+		}
+	}
+
+	for key, value := range readiness.Labels {
+		actual, ok := kubeNode.Labels[key]
+		if !ok {
+			unmet = append(unmet, fmt.Sprintf("label %s is missing", key))
+		} else if value != "" && actual != value {
+			unmet = append(unmet, fmt.Sprintf("label %s is %q, expected %q", key, actual, value))
+		}
+	}
+
+	for _, taint := range kubeNode.Spec.Taints {
+		for _, key := range readiness.AbsentTaints {
+			if taint.Key == key {
+				unmet = append(unmet, fmt.Sprintf("taint %s is present", key))
+			}
+		}
+	}
+
+	if readiness.DaemonSetPods {
+		notReady, err := t.daemonSetPodsNotReady(kubeNode)
+		if err != nil {
+			t.rm.Logger.Error(err, "Failed to check daemonset pods for readiness health check", "node", node.Name, "error", err)
+			return true, err
+		}
+
+		unmet = append(unmet, notReady...)
+	}
+
+	// Still within the waiting period here, must trigger requeueing this phase
+	if len(unmet) > 0 {
+		err := fmt.Errorf("node %s is not ready: %s", node.Name, strings.Join(unmet, ", "))
+		t.rm.Logger.Error(err, "Node readiness health check did not pass", "node", node.Name, "error", err)
+		return true, err
+	}
+
+	t.rm.Logger.Info("Node readiness health check passed", "node", node.Name)
 	return true, nil
 }
 
+// daemonSetPodsNotReady works out which DaemonSets should run on the node and describes the ones which don't
+// have a Ready pod on it yet. DaemonSet pods which haven't been created yet aren't missed this way.
+func (t *CycleNodeRequestTransitioner) daemonSetPodsNotReady(kubeNode *corev1.Node) ([]string, error) {
+	daemonSets, err := t.rm.ListDaemonSets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %v", err)
+	}
+
+	pods, err := t.rm.GetPodsOnNode(kubeNode.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node %s: %v", kubeNode.Name, err)
+	}
+
+	podsByDaemonSet := make(map[types.UID]corev1.Pod)
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "DaemonSet" {
+			podsByDaemonSet[owner.UID] = pod
+		}
+	}
+
+	var notReady []string
+	for _, daemonSet := range daemonSets {
+		if !k8s.DaemonSetShouldRunOnNode(&daemonSet, kubeNode) {
+			continue
+		}
+
+		pod, ok := podsByDaemonSet[daemonSet.UID]
+		if !ok {
+			notReady = append(notReady, fmt.Sprintf("daemonset %s/%s has no pod on the node", daemonSet.Namespace, daemonSet.Name))
+		} else if !podIsReady(pod) {
+			notReady = append(notReady, fmt.Sprintf("daemonset pod %s/%s is not ready", pod.Namespace, pod.Name))
+		}
+	}
+
+	return notReady, nil
+}
+
+// nodeHasCondition returns true if the node has the condition with the required status
+func nodeHasCondition(node *corev1.Node, requirement v1.NodeConditionRequirement) bool {
+	for _, condition := range node.Status.Conditions {
+		if string(condition.Type) == requirement.Type {
+			return string(condition.Status) == requirement.Status
+		}
+	}
+
+	return false
+}
+
+// podIsReady returns true if the pod has the Ready condition
+func podIsReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// runHealthCheckJob creates the Job for the health check on the node if it doesn't exist yet and returns it.
+// The Job name is derived from the CycleNodeRequest, node and the whole Job spec so it's only created once.
+func (t *CycleNodeRequestTransitioner) runHealthCheckJob(node v1.CycleNodeRequestNode, jobHealthCheck v1.JobHealthCheck) (*batchv1.Job, error) {
+	spec, err := json.Marshal(jobHealthCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := fnv.New32a()
+	_, _ = fmt.Fprintf(hasher, "%s/%s/%s", t.cycleNodeRequest.Name, getNodeHash(node), spec)
+	name := fmt.Sprintf("cyclops-health-check-%s", rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())))
+
+	jobs := t.rm.RawClient.BatchV1().Jobs(t.cycleNodeRequest.Namespace)
+	job, err := jobs.Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil || !apierrors.IsNotFound(err) {
+		return job, err
+	}
+
+	backoffLimit := int32(0)
+	if jobHealthCheck.BackoffLimit != nil {
+		backoffLimit = *jobHealthCheck.BackoffLimit
+	}
+
+	// Don't hand the Job the token of the default service account unless it asks for a service account
+	automountServiceAccountToken := jobHealthCheck.ServiceAccountName != ""
+	ttlSecondsAfterFinished := healthCheckJobTTLSeconds
+
+	job = &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: t.cycleNodeRequest.Namespace,
+			Labels: map[string]string{
+				healthCheckJobLabel: t.cycleNodeRequest.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(t.cycleNodeRequest, v1.SchemeGroupVersion.WithKind("CycleNodeRequest")),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						healthCheckJobLabel: t.cycleNodeRequest.Name,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					ServiceAccountName:           jobHealthCheck.ServiceAccountName,
+					AutomountServiceAccountToken: &automountServiceAccountToken,
+					// Pin the pod to the node the same way DaemonSet pods are, and schedule it even though the
+					// node may be tainted or cordoned. NoExecute taints aren't tolerated, the node isn't ready yet
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+									MatchFields: []corev1.NodeSelectorRequirement{{
+										Key:      "metadata.name",
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   []string{node.Name},
+									}},
+								}},
+							},
+						},
+					},
+					Tolerations: []corev1.Toleration{{
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					}},
+					Containers: []corev1.Container{{
+						Name:    "health-check",
+						Image:   jobHealthCheck.Image,
+						Command: jobHealthCheck.Command,
+						Args:    jobHealthCheck.Args,
+					}},
+				},
+			},
+		},
+	}
+
+	t.rm.Logger.Info("Creating health check job", "job", name, "node", node.Name)
+	return jobs.Create(context.TODO(), job, metav1.CreateOptions{})
+}
+
+// deleteHealthCheckJob deletes a finished health check Job along with its pods
+func (t *CycleNodeRequestTransitioner) deleteHealthCheckJob(job *batchv1.Job) error {
+	propagationPolicy := metav1.DeletePropagationBackground
+	err := t.rm.RawClient.BatchV1().Jobs(job.Namespace).Delete(context.TODO(), job.Name, metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.rm.Logger.Error(err, "Failed to delete health check job", "job", job.Name, "error", err)
+		return fmt.Errorf("failed to delete health check job %s: %v", job.Name, err)
+	}
+
+	return nil
+}
+
 // performInitialHealthChecks on the nodes selected to be terminated before cycling begin. If any health
 // check fails return an error to prevent cycling from starting
 func (t *CycleNodeRequestTransitioner) performInitialHealthChecks(kubeNodes map[string]corev1.Node) error {
@@ -227,10 +839,15 @@ func (t *CycleNodeRequestTransitioner) performInitialHealthChecks(kubeNodes map[
 		}
 
 		for _, healthCheck := range t.cycleNodeRequest.Spec.HealthChecks {
+			// Job health checks take time to run so can't immediately pass, only run them on new nodes
+			if healthCheck.Type == v1.HealthCheckJob {
+				continue
+			}
+
 			// Perform the health check on the instance without an anchor time, the health check
 			// should immediately pass, if it doesn't then fail the CNR because that's an issue.
 			// As a result, disregard whether the error is allowed or not.
-			if _, err := t.performHealthCheck(node, healthCheck, nil); err != nil {
+			if _, _, err := t.performHealthCheck(node, healthCheck, nil); err != nil {
 				return fmt.Errorf("initial: %v", err)
 			}
 		}
@@ -239,6 +856,45 @@ func (t *CycleNodeRequestTransitioner) performInitialHealthChecks(kubeNodes map[
 	return nil
 }
 
+// performClusterHealthChecks before selecting the next batch of nodes. Cycling pauses until all of the cluster
+// health checks pass, and an error is returned if they don't pass within their wait period.
+func (t *CycleNodeRequestTransitioner) performClusterHealthChecks() (bool, error) {
+	if len(t.cycleNodeRequest.Spec.ClusterHealthChecks) == 0 {
+		return true, nil
+	}
+
+	// The wait period is measured from when the cluster health checks first failed
+	anchorTime := t.cycleNodeRequest.Status.ClusterHealthCheckStarted
+	if anchorTime == nil {
+		now := metav1.Now()
+		anchorTime = &now
+	}
+
+	for _, healthCheck := range t.cycleNodeRequest.Spec.ClusterHealthChecks {
+		// These health checks need a node to check
+		if healthCheck.Type == v1.HealthCheckJob || healthCheck.Type == v1.HealthCheckNodeReadiness {
+			return false, fmt.Errorf("%s health checks can't be used as cluster health checks", healthCheck.Type)
+		}
+
+		_, errorAllowed, err := t.performHealthCheck(v1.CycleNodeRequestNode{}, healthCheck, anchorTime)
+		if err == nil {
+			continue
+		}
+
+		if !errorAllowed {
+			return false, fmt.Errorf("cluster: %v", err)
+		}
+
+		t.cycleNodeRequest.Status.ClusterHealthCheckStarted = anchorTime
+		t.rm.LogEvent(t.cycleNodeRequest, "WaitingClusterHealthChecks",
+			"Waiting for cluster health checks to pass before selecting nodes: %v", err)
+		return false, nil
+	}
+
+	t.cycleNodeRequest.Status.ClusterHealthCheckStarted = nil
+	return true, nil
+}
+
 // performCyclingHealthChecks before terminating an instance selected for termination. Cycling pauses
 // until all health checks pass for the new instance before terminating the old one
 func (t *CycleNodeRequestTransitioner) performCyclingHealthChecks(kubeNodes map[string]corev1.Node) (bool, error) {
@@ -259,7 +915,8 @@ func (t *CycleNodeRequestTransitioner) performCyclingHealthChecks(kubeNodes map[
 		// Do not add set Skip=true or else they will be skipped as part of the health checks below
 		if !ok {
 			healthChecksStatus = v1.HealthCheckStatus{
-				Checks: make([]bool, len(t.cycleNodeRequest.Spec.HealthChecks)),
+				Checks:  make([]bool, len(t.cycleNodeRequest.Spec.HealthChecks)),
+				Results: make([]v1.HealthCheckResult, len(t.cycleNodeRequest.Spec.HealthChecks)),
 			}
 
 			t.cycleNodeRequest.Status.HealthChecks[nodeHash] = healthChecksStatus
@@ -280,19 +937,31 @@ func (t *CycleNodeRequestTransitioner) performCyclingHealthChecks(kubeNodes map[
 			t.cycleNodeRequest.Status.HealthChecks[nodeHash] = healthChecksStatus
 		}
 
+		// Statuses recorded by an older version of Cyclops don't have any results
+		if len(healthChecksStatus.Results) != len(healthChecksStatus.Checks) {
+			results := make([]v1.HealthCheckResult, len(healthChecksStatus.Checks))
+			copy(results, healthChecksStatus.Results)
+			healthChecksStatus.Results = results
+		}
+
 		for i, healthCheck := range t.cycleNodeRequest.Spec.HealthChecks {
 			// If the health check has already passed, skip it
 			if healthChecksStatus.Checks[i] {
 				continue
 			}
 
-			errorAllowed, err := t.performHealthCheck(node, healthCheck, healthChecksStatus.NodeReady)
+			start := time.Now()
+			statusCode, errorAllowed, err := t.performHealthCheck(node, healthCheck, healthChecksStatus.NodeReady)
 
-			// If the error is not allowed then the cycling should fail
+			// If the error is not allowed then the cycling should fail, explaining what happened in the
+			// attempts leading up to it
 			if !errorAllowed && err != nil {
-				return false, fmt.Errorf("cycling: %v", err)
+				return false, fmt.Errorf("cycling: %v (%s)", err, healthChecksStatus.Results[i].Summary())
 			}
 
+			recordHealthCheckResult(&healthChecksStatus.Results[i], statusCode, time.Since(start), err)
+			t.cycleNodeRequest.Status.HealthChecks[nodeHash] = healthChecksStatus
+
 			// If the error is allowed, log out the error and continue to the next health check
 			if err != nil {
 				allHealthChecksPassed = false
@@ -308,6 +977,26 @@ func (t *CycleNodeRequestTransitioner) performCyclingHealthChecks(kubeNodes map[
 	return allHealthChecksPassed, nil
 }
 
+// recordHealthCheckResult records the outcome of an attempt of a health check in its result
+func recordHealthCheckResult(result *v1.HealthCheckResult, statusCode uint, latency time.Duration, err error) {
+	now := metav1.Now()
+
+	result.Attempts++
+	result.LastAttempt = &now
+	result.LastStatusCode = statusCode
+	result.LastLatency = &metav1.Duration{Duration: latency.Round(time.Millisecond)}
+	result.LastError = ""
+
+	if err != nil {
+		result.LastError = truncate(err.Error(), maxHealthCheckErrorLength)
+		return
+	}
+
+	if result.Passed == nil {
+		result.Passed = &now
+	}
+}
+
 // sendPreTerminationTrigger sends a http request as a trigger. When this is done, the upstream host
 // will know that the associated node is going to be terminated and so it should begin it's own
 // shutdown process before that begins. This can be thought of as a http sigterm.
@@ -341,13 +1030,19 @@ func (t *CycleNodeRequestTransitioner) sendPreTerminationTrigger(node v1.CycleNo
 			return fmt.Errorf("failed to build health check endpoint: %v", err)
 		}
 
-		httpClient, err := t.buildHttpClient(preTerminationCheck.TLSConfig)
+		httpClient, err := t.rm.BuildHttpClient(t.cycleNodeRequest.Namespace, preTerminationCheck.TLSConfig)
 		if err != nil {
 			return fmt.Errorf("failed to build http client: %v", err)
 		}
 
+		// Triggers are sent as POST requests unless configured otherwise
+		request := preTerminationCheck.HTTPRequest
+		if request.Method == "" {
+			request.Method = http.MethodPost
+		}
+
 		// Send the trigger, disregard the response body
-		statusCode, res, err := t.makeRequest(http.MethodPost, httpClient, endpoint)
+		statusCode, res, err := t.makeCheckRequest(node, httpClient, endpoint, request)
 		if err != nil {
 			return fmt.Errorf("sending trigger failed: %v", err)
 		}
@@ -397,7 +1092,7 @@ func (t *CycleNodeRequestTransitioner) performPreTerminationHealthChecks(node v1
 			continue
 		}
 
-		errorAllowed, err := t.performHealthCheck(node, preTerminationCheck.HealthCheck, status.Trigger)
+		_, errorAllowed, err := t.performHealthCheck(node, preTerminationCheck.HealthCheck, status.Trigger)
 		// If the error is not allowed then the cycling should fail
 		if !errorAllowed && err != nil {
 			return false, fmt.Errorf("pre-termination: %v", err)
@@ -417,3 +1112,34 @@ func (t *CycleNodeRequestTransitioner) performPreTerminationHealthChecks(node v1
 
 	return allHealthChecksPassed, nil
 }
+
+// callPreCordonHooks calls the PreCordon lifecycle hooks for the node before it is cordoned. It returns whether
+// all the blocking hooks have succeeded and the node can be cordoned.
+func (t *CycleNodeRequestTransitioner) callPreCordonHooks(node v1.CycleNodeRequestNode) (bool, error) {
+	nodeHash := getNodeHash(node)
+
+	// The first time this runs, the status will not have been initialised yet
+	if t.cycleNodeRequest.Status.PreCordonHooks == nil {
+		t.cycleNodeRequest.Status.PreCordonHooks = make(map[string]v1.LifecycleHookStatusList)
+	}
+
+	status := t.cycleNodeRequest.Status.PreCordonHooks[nodeHash]
+	hooks, finished, err := t.rm.CallLifecycleHooks(
+		t.cycleNodeRequest,
+		v1.LifecycleHookPreCordon,
+		t.cycleNodeRequest.Spec.CycleSettings.LifecycleHooks,
+		status.Hooks,
+		controller.LifecycleHookRequest{
+			CycleNodeRequest: t.cycleNodeRequest.Name,
+			Namespace:        t.cycleNodeRequest.Namespace,
+			Node:             node,
+		},
+	)
+
+	if len(hooks) > 0 {
+		status.Hooks = hooks
+		t.cycleNodeRequest.Status.PreCordonHooks[nodeHash] = status
+	}
+
+	return finished, err
+}
@@ -1,18 +1,31 @@
 package transitioner
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
 	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
 	"github.com/go-logr/logr"     // required for the resource manager logger
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 // getNodeHash function tests, verifies the node hash is generated correctly
@@ -334,6 +347,59 @@ func TestChecks_HealthCheckPassed(t *testing.T) {
 			expectError: true,
 			errorMsg:    "status code 200 returned, did not match expected []",
 		},
+		{
+			name: "matching status code and matching json path",
+			healthCheck: v1.HealthCheck{
+				ValidStatusCodes: []uint{200},
+				JSONPathMatch:    &v1.JSONPathAssertion{Path: "{.checks[0].status}", Value: "up"},
+			},
+			statusCode:  200,
+			body:        []byte(`{"checks": [{"name": "disk", "status": "up"}]}`),
+			expectError: false,
+		},
+		{
+			name: "json path found without an expected value",
+			healthCheck: v1.HealthCheck{
+				ValidStatusCodes: []uint{200},
+				JSONPathMatch:    &v1.JSONPathAssertion{Path: "{.ready}"},
+			},
+			statusCode:  200,
+			body:        []byte(`{"ready": true}`),
+			expectError: false,
+		},
+		{
+			name: "json path returns a different value",
+			healthCheck: v1.HealthCheck{
+				ValidStatusCodes: []uint{200},
+				JSONPathMatch:    &v1.JSONPathAssertion{Path: "{.status}", Value: "up"},
+			},
+			statusCode:  200,
+			body:        []byte(`{"status": "down"}`),
+			expectError: true,
+			errorMsg:    `json path {.status} returned "down", expected "up"`,
+		},
+		{
+			name: "json path not found in body",
+			healthCheck: v1.HealthCheck{
+				ValidStatusCodes: []uint{200},
+				JSONPathMatch:    &v1.JSONPathAssertion{Path: "{.status}"},
+			},
+			statusCode:  200,
+			body:        []byte(`{"state": "up"}`),
+			expectError: true,
+			errorMsg:    "json path {.status} did not match body",
+		},
+		{
+			name: "json path with a body which isn't json",
+			healthCheck: v1.HealthCheck{
+				ValidStatusCodes: []uint{200},
+				JSONPathMatch:    &v1.JSONPathAssertion{Path: "{.status}"},
+			},
+			statusCode:  200,
+			body:        []byte("OK"),
+			expectError: true,
+			errorMsg:    "is not json",
+		},
 	}
 
 	for _, tt := range tests {
@@ -432,6 +498,65 @@ func TestChecks_MakeRequest(t *testing.T) {
 	}
 }
 
+// makeCheckRequest function tests, verifies the method, headers, body and bearer token are sent
+func TestChecks_MakeCheckRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret-key", r.Header.Get("X-Api-Key"))
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"node": "node-1", "ip": "10.0.0.1"}`, string(body))
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: &v1.CycleNodeRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "cnr-1", Namespace: "kube-system"},
+		},
+		rm: &controller.ResourceManager{
+			RawClient: fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "trigger-auth", Namespace: "kube-system"},
+				Data: map[string][]byte{
+					"api-key": []byte("secret-key"),
+					"token":   []byte("secret-token\n"),
+				},
+			}),
+			Logger: logr.Discard(),
+		},
+		options: Options{HealthCheckCredentialSecrets: []string{"trigger-auth"}},
+	}
+
+	node := v1.CycleNodeRequestNode{Name: "node-1", PrivateIP: "10.0.0.1"}
+	request := v1.HTTPRequest{
+		Method: http.MethodPut,
+		Headers: []v1.HTTPHeader{
+			{Name: "Content-Type", Value: "application/json"},
+			{Name: "X-Api-Key", ValueFrom: &v1.SecretKeyReference{Name: "trigger-auth", Key: "api-key"}},
+		},
+		Body:                 `{"node": "{{ .NodeName }}", "ip": "{{ .NodeIP }}"}`,
+		BearerTokenSecretRef: &v1.SecretKeyReference{Name: "trigger-auth", Key: "token"},
+	}
+
+	statusCode, _, err := transitioner.makeCheckRequest(node, server.Client(), server.URL, request)
+	require.NoError(t, err)
+	assert.Equal(t, uint(http.StatusAccepted), statusCode)
+
+	// Missing secrets fail the request before it's sent
+	request.BearerTokenSecretRef = &v1.SecretKeyReference{Name: "trigger-auth", Key: "missing"}
+	_, _, err = transitioner.makeCheckRequest(node, server.Client(), server.URL, request)
+	assert.Error(t, err)
+
+	// Secrets which the operator hasn't allowed aren't read
+	request.BearerTokenSecretRef = &v1.SecretKeyReference{Name: "other-secret", Key: "token"}
+	_, _, err = transitioner.makeCheckRequest(node, server.Client(), server.URL, request)
+	assert.ErrorContains(t, err, "can't be read by checks")
+}
+
 // makeRequest function test with connection error, verifies the connection error is returned
 func TestChecks_MakeRequest_ConnectionError(t *testing.T) {
 	transitioner := &CycleNodeRequestTransitioner{
@@ -539,7 +664,7 @@ func TestChecks_PerformHealthCheck(t *testing.T) {
 				},
 			}
 
-			continueProcessing, err := transitioner.performHealthCheck(tt.node, tt.healthCheck, tt.anchorTime)
+			_, continueProcessing, err := transitioner.performHealthCheck(tt.node, tt.healthCheck, tt.anchorTime)
 
 			assert.Equal(t, tt.expectContinue, continueProcessing)
 			if tt.expectError {
@@ -551,6 +676,415 @@ func TestChecks_PerformHealthCheck(t *testing.T) {
 	}
 }
 
+// Large response bodies are cut short in health check errors and results so they don't bloat the CycleNodeRequest
+func TestChecks_PerformHealthCheckTruncatesBody(t *testing.T) {
+	body := strings.Repeat("<html>", 2000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	node := v1.CycleNodeRequestNode{Name: "node-1", PrivateIP: "127.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Endpoint:         server.URL,
+		ValidStatusCodes: []uint{200},
+		RegexMatch:       "healthy",
+		WaitPeriod:       &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: &v1.CycleNodeRequest{Spec: v1.CycleNodeRequestSpec{HealthChecks: []v1.HealthCheck{healthCheck}}},
+		rm: &controller.ResourceManager{
+			HttpClient: &http.Client{Timeout: 5 * time.Second},
+			Logger:     logr.Discard(),
+		},
+	}
+
+	statusCode, _, err := transitioner.performHealthCheck(node, healthCheck, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), body[:maxHealthCheckBodyLength-len(truncatedSuffix)]+truncatedSuffix)
+	assert.NotContains(t, err.Error(), body[:maxHealthCheckBodyLength])
+
+	var result v1.HealthCheckResult
+	recordHealthCheckResult(&result, statusCode, time.Second, fmt.Errorf("%s", body))
+	assert.Len(t, result.LastError, maxHealthCheckErrorLength)
+	assert.True(t, strings.HasSuffix(result.LastError, truncatedSuffix))
+}
+
+// performHealthCheck function tests for gRPC health checks, verifies the check passes once the service is serving
+func TestChecks_PerformGRPCHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	node := v1.CycleNodeRequestNode{Name: "node-1", PrivateIP: "127.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Type:        v1.HealthCheckGRPC,
+		Endpoint:    "{{ .NodeIP }}:" + port,
+		GRPCService: "node-agent",
+		WaitPeriod:  &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: &v1.CycleNodeRequest{},
+		rm: &controller.ResourceManager{
+			HttpClient: &http.Client{Timeout: 5 * time.Second},
+			Logger:     logr.Discard(),
+		},
+	}
+
+	healthServer.SetServingStatus("node-agent", healthpb.HealthCheckResponse_NOT_SERVING)
+	_, errorAllowed, err := transitioner.performHealthCheck(node, healthCheck, nil)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+
+	healthServer.SetServingStatus("node-agent", healthpb.HealthCheckResponse_SERVING)
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, nil)
+	assert.True(t, errorAllowed)
+	assert.NoError(t, err)
+}
+
+// performHealthCheck function tests for tcp health checks, verifies the check passes when a connection can be opened
+func TestChecks_PerformTCPHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	node := v1.CycleNodeRequestNode{Name: "node-1", PrivateIP: "127.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Type:       v1.HealthCheckTCP,
+		Endpoint:   listener.Addr().String(),
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: &v1.CycleNodeRequest{},
+		rm: &controller.ResourceManager{
+			HttpClient: &http.Client{Timeout: 5 * time.Second},
+			Logger:     logr.Discard(),
+		},
+	}
+
+	_, errorAllowed, err := transitioner.performHealthCheck(node, healthCheck, nil)
+	assert.True(t, errorAllowed)
+	assert.NoError(t, err)
+
+	// Nothing is listening once the listener is closed
+	require.NoError(t, listener.Close())
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, nil)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+}
+
+// performHealthCheck function tests for prometheus health checks, verifies the query result is compared to the threshold
+func TestChecks_PerformPrometheusHealthCheck(t *testing.T) {
+	var query string
+	response := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"node":"node-1"},"value":[1700000000,"0.5"]}]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		query = r.URL.Query().Get("query")
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	node := v1.CycleNodeRequestNode{Name: "node-1", ProviderID: "aws:///us-east-1a/i-123", PrivateIP: "10.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Type:     v1.HealthCheckPrometheus,
+		Endpoint: server.URL,
+		Prometheus: &v1.PrometheusHealthCheck{
+			Query:     `avg(node_load1{node="{{ .NodeName }}",instance=~"{{ .NodeIP }}:.*"}) < 10`,
+			Operator:  v1.PrometheusLessThanOrEqual,
+			Threshold: "0.5",
+		},
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	anchorTime := metav1.Now()
+
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: &v1.CycleNodeRequest{},
+		rm: &controller.ResourceManager{
+			HttpClient: http.DefaultClient,
+			Logger:     logr.Discard(),
+		},
+	}
+
+	// The query is rendered with the node and the sample meets the threshold
+	_, errorAllowed, err := transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.NoError(t, err)
+	assert.Equal(t, `avg(node_load1{node="node-1",instance=~"10.0.0.1:.*"}) < 10`, query)
+
+	// Every sample must meet the threshold
+	response = `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"0.1"]},{"metric":{},"value":[1700000000,"0.9"]}]}}`
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+
+	// An empty result only passes when allowed
+	response = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+
+	healthCheck.Prometheus.AllowEmptyResult = true
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.NoError(t, err)
+
+	// Errors from prometheus are retried
+	response = `{"status":"error","errorType":"bad_data","error":"parse error"}`
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+
+	// An invalid threshold fails the check straight away
+	healthCheck.Prometheus.Threshold = "ten"
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.False(t, errorAllowed)
+	assert.Error(t, err)
+}
+
+// performJobHealthCheck function tests, verifies the Job is created on the node and the check passes once it succeeds
+func TestChecks_PerformJobHealthCheck(t *testing.T) {
+	node := v1.CycleNodeRequestNode{Name: "node-1", ProviderID: "aws:///us-east-1a/i-123", PrivateIP: "10.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Type:       v1.HealthCheckJob,
+		Job:        &v1.JobHealthCheck{Image: "busybox", Command: []string{"nslookup", "kubernetes.default"}},
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	anchorTime := metav1.Now()
+
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnr-1", Namespace: "kube-system"},
+	}
+	rawClient := fake.NewSimpleClientset()
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: cnr,
+		rm: &controller.ResourceManager{
+			RawClient: rawClient,
+			Logger:    logr.Discard(),
+		},
+	}
+
+	// The Job is created pinned to the node, and the check waits for it to finish
+	_, errorAllowed, err := transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+
+	jobs, err := rawClient.BatchV1().Jobs("kube-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+	job := jobs.Items[0]
+	assert.Equal(t, "cnr-1", job.Labels[healthCheckJobLabel])
+	assert.Equal(t, []string{"node-1"}, job.Spec.Template.Spec.Affinity.NodeAffinity.
+		RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields[0].Values)
+	assert.Equal(t, "busybox", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, healthCheckJobTTLSeconds, *job.Spec.TTLSecondsAfterFinished)
+	assert.False(t, *job.Spec.Template.Spec.AutomountServiceAccountToken)
+
+	// Checking again doesn't create another Job
+	_, _, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.Error(t, err)
+
+	jobs, err = rawClient.BatchV1().Jobs("kube-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, jobs.Items, 1)
+
+	// The check passes once the Job has succeeded, and the Job is deleted
+	job.Status.Succeeded = 1
+	_, err = rawClient.BatchV1().Jobs("kube-system").UpdateStatus(context.TODO(), &job, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.NoError(t, err)
+
+	jobs, err = rawClient.BatchV1().Jobs("kube-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+
+	// A failed Job fails the check straight away, and is deleted so that the next attempt runs it again
+	_, _, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.Error(t, err)
+
+	job.Status.Succeeded = 0
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}}
+	_, err = rawClient.BatchV1().Jobs("kube-system").UpdateStatus(context.TODO(), &job, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.False(t, errorAllowed)
+	assert.Error(t, err)
+
+	jobs, err = rawClient.BatchV1().Jobs("kube-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+}
+
+func TestChecks_PerformJobHealthCheckServiceAccount(t *testing.T) {
+	node := v1.CycleNodeRequestNode{Name: "node-1", ProviderID: "aws:///us-east-1a/i-123", PrivateIP: "10.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Type:       v1.HealthCheckJob,
+		Job:        &v1.JobHealthCheck{Image: "busybox", ServiceAccountName: "cluster-admin"},
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	anchorTime := metav1.Now()
+
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnr-1", Namespace: "kube-system"},
+	}
+	rawClient := fake.NewSimpleClientset()
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: cnr,
+		rm: &controller.ResourceManager{
+			RawClient: rawClient,
+			Logger:    logr.Discard(),
+		},
+		options: Options{HealthCheckJobServiceAccounts: []string{"health-check"}},
+	}
+
+	// Service accounts which aren't allowed fail the check without creating a Job
+	_, errorAllowed, err := transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.False(t, errorAllowed)
+	assert.Error(t, err)
+
+	jobs, err := rawClient.BatchV1().Jobs("kube-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+
+	// Allowed service accounts are used by the Job
+	healthCheck.Job.ServiceAccountName = "health-check"
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.Error(t, err)
+
+	// Changing any part of the Job spec creates a new Job
+	backoffLimit := int32(2)
+	healthCheck.Job.BackoffLimit = &backoffLimit
+	_, _, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.Error(t, err)
+
+	jobs, err = rawClient.BatchV1().Jobs("kube-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 2)
+	for _, job := range jobs.Items {
+		assert.Equal(t, "health-check", job.Spec.Template.Spec.ServiceAccountName)
+		assert.True(t, *job.Spec.Template.Spec.AutomountServiceAccountToken)
+	}
+}
+
+func TestChecks_PerformNodeReadinessHealthCheck(t *testing.T) {
+	node := v1.CycleNodeRequestNode{Name: "node-1", ProviderID: "aws:///us-east-1a/i-123", PrivateIP: "10.0.0.1"}
+	healthCheck := v1.HealthCheck{
+		Type: v1.HealthCheckNodeReadiness,
+		NodeReadiness: &v1.NodeReadinessHealthCheck{
+			DaemonSetPods: true,
+			Conditions:    []v1.NodeConditionRequirement{{Type: "NetworkUnavailable", Status: "False"}},
+			Labels: map[string]string{
+				"node.kubernetes.io/instance-type": "",
+				"topology.kubernetes.io/zone":      "us-east-1a",
+			},
+			AbsentTaints: []string{"node.cilium.io/agent-not-ready"},
+		},
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	kubeNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{"topology.kubernetes.io/zone": "us-east-1b"},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "node.cilium.io/agent-not-ready", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionTrue}},
+		},
+	}
+	buildDaemonSet := func(name string, podSpec corev1.PodSpec) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", UID: types.UID(name)},
+			Spec:       appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: podSpec}},
+		}
+	}
+	buildPod := func(name string, daemonSet *appsv1.DaemonSet, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "kube-system",
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(daemonSet, appsv1.SchemeGroupVersion.WithKind("DaemonSet")),
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+
+	tolerateAll := []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	cilium := buildDaemonSet("cilium", corev1.PodSpec{Tolerations: tolerateAll})
+	logging := buildDaemonSet("logging", corev1.PodSpec{Tolerations: tolerateAll})
+	windows := buildDaemonSet("windows-agent", corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "windows"}})
+	pod := buildPod("cilium-abcde", cilium, corev1.ConditionFalse)
+
+	client := mock.NewClient(nil, nil, kubeNode, cilium, logging, windows, pod)
+	transitioner := &CycleNodeRequestTransitioner{
+		cycleNodeRequest: &v1.CycleNodeRequest{},
+		rm: &controller.ResourceManager{
+			Client: client.K8sClient,
+			Logger: logr.Discard(),
+		},
+	}
+
+	// None of the requirements are met yet, keep waiting
+	anchorTime := metav1.Now()
+	_, errorAllowed, err := transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "condition NetworkUnavailable is not False")
+	assert.Contains(t, err.Error(), "label node.kubernetes.io/instance-type is missing")
+	assert.Contains(t, err.Error(), `label topology.kubernetes.io/zone is "us-east-1b", expected "us-east-1a"`)
+	assert.Contains(t, err.Error(), "taint node.cilium.io/agent-not-ready is present")
+	assert.Contains(t, err.Error(), "daemonset pod kube-system/cilium-abcde is not ready")
+	assert.Contains(t, err.Error(), "daemonset kube-system/logging has no pod on the node")
+	assert.NotContains(t, err.Error(), "windows-agent")
+
+	// The node is ready once the networking and logging agents are up
+	kubeNode.Labels = map[string]string{
+		"node.kubernetes.io/instance-type": "m5.large",
+		"topology.kubernetes.io/zone":      "us-east-1a",
+	}
+	kubeNode.Spec.Taints = nil
+	require.NoError(t, client.K8sClient.Update(context.TODO(), kubeNode))
+
+	kubeNode.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionFalse}}
+	require.NoError(t, client.K8sClient.Status().Update(context.TODO(), kubeNode))
+
+	pod.Status.Conditions[0].Status = corev1.ConditionTrue
+	require.NoError(t, client.K8sClient.Status().Update(context.TODO(), pod))
+	require.NoError(t, client.K8sClient.Create(context.TODO(), buildPod("logging-abcde", logging, corev1.ConditionTrue)))
+
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.True(t, errorAllowed)
+	assert.NoError(t, err)
+
+	// The check fails once the wait period is exceeded
+	anchorTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	_, errorAllowed, err = transitioner.performHealthCheck(node, healthCheck, &anchorTime)
+	assert.False(t, errorAllowed)
+	assert.Error(t, err)
+}
+
 // performInitialHealthChecks function tests, verifies the initial health checks are performed correctly on the nodes selected to be terminated before cycling begin
 func TestChecks_PerformInitialHealthChecks(t *testing.T) {
 	tests := []struct {
@@ -705,6 +1239,79 @@ func TestChecks_PerformCyclingHealthChecks(t *testing.T) {
 		allPassed, err := transitioner.performCyclingHealthChecks(kubeNodes) // should pass as mock 200 response code is returned
 		assert.NoError(t, err)
 		assert.True(t, allPassed)
+
+		result := cnr.Status.HealthChecks["aws:///us-east-1a/i-new/new-node"].Results[0]
+		assert.Equal(t, int64(1), result.Attempts)
+		assert.Equal(t, uint(http.StatusOK), result.LastStatusCode)
+		assert.NotNil(t, result.LastLatency)
+		assert.NotNil(t, result.Passed)
+		assert.Empty(t, result.LastError)
+		assert.Empty(t, cnr.PendingHealthChecks())
+	})
+
+	t.Run("failing health check results explain the failure", func(t *testing.T) {
+		unhealthyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("starting"))
+		}))
+		defer unhealthyServer.Close()
+
+		kubeNodes := map[string]corev1.Node{
+			"aws:///us-east-1a/i-new": {
+				ObjectMeta: metav1.ObjectMeta{Name: "new-node"},
+				Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-new"},
+			},
+		}
+
+		cnr := &v1.CycleNodeRequest{
+			Spec: v1.CycleNodeRequestSpec{
+				HealthChecks: []v1.HealthCheck{{
+					Endpoint:         unhealthyServer.URL,
+					ValidStatusCodes: []uint{200},
+					WaitPeriod:       &metav1.Duration{Duration: 10 * time.Minute},
+				}},
+			},
+			Status: v1.CycleNodeRequestStatus{
+				HealthChecks: make(map[string]v1.HealthCheckStatus),
+			},
+		}
+
+		transitioner := &CycleNodeRequestTransitioner{
+			cycleNodeRequest: cnr,
+			rm: &controller.ResourceManager{
+				HttpClient: &http.Client{Timeout: 5 * time.Second},
+				Logger:     logr.Discard(),
+			},
+		}
+
+		for i := 0; i < 2; i++ {
+			allPassed, err := transitioner.performCyclingHealthChecks(kubeNodes)
+			assert.NoError(t, err)
+			assert.False(t, allPassed)
+		}
+
+		nodeHash := "aws:///us-east-1a/i-new/new-node"
+		result := cnr.Status.HealthChecks[nodeHash].Results[0]
+		assert.Equal(t, int64(2), result.Attempts)
+		assert.Equal(t, uint(http.StatusServiceUnavailable), result.LastStatusCode)
+		assert.Contains(t, result.LastError, "starting")
+		assert.Nil(t, result.Passed)
+
+		pending := cnr.PendingHealthChecks()
+		require.Len(t, pending, 1)
+		assert.Contains(t, pending[0], nodeHash)
+		assert.Contains(t, pending[0], "last status code 503")
+
+		// Once the wait period is exceeded the error explains the attempts which were made
+		status := cnr.Status.HealthChecks[nodeHash]
+		status.NodeReady = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		cnr.Status.HealthChecks[nodeHash] = status
+
+		allPassed, err := transitioner.performCyclingHealthChecks(kubeNodes)
+		assert.False(t, allPassed)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "didn't become healthy in time")
+		assert.Contains(t, err.Error(), "2 attempts, last status code 503")
 	})
 
 	t.Run("skip node with Skip flag", func(t *testing.T) {
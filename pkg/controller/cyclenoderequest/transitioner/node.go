@@ -80,9 +80,24 @@ func (t *CycleNodeRequestTransitioner) getNodesToTerminate(numNodes int64) (node
 		return nil, 0, err
 	}
 
+	inProgressNodeNames := make(map[string]bool)
+
 	for _, kubeNode := range kubeNodes {
+		// Skipped nodes are no longer in progress, the label may still be there if removing it failed
+		if t.isSkippedNode(kubeNode.Name) {
+			continue
+		}
+
 		if value, ok := kubeNode.Labels[cycleNodeLabel]; ok && value == t.cycleNodeRequest.Name {
 			numNodesInProgress++
+			inProgressNodeNames[kubeNode.Name] = true
+		}
+	}
+
+	// Nodes waiting to be retried are still in progress, even if they have already been removed from kube
+	for nodeName := range t.cycleNodeRequest.Status.NodeAttempts {
+		if t.isRetryingNode(nodeName) && !inProgressNodeNames[nodeName] {
+			numNodesInProgress++
 		}
 	}
 
@@ -98,6 +113,11 @@ func (t *CycleNodeRequestTransitioner) getNodesToTerminate(numNodes int64) (node
 			continue
 		}
 
+		// Skip nodes that have already failed to cycle within the failure budget
+		if t.isSkippedNode(kubeNode.Name) {
+			continue
+		}
+
 		// Add nodes that need to be terminated but have not yet been actioned
 		nodes = append(nodes, &kubeNode)
 
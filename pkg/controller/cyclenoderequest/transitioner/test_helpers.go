@@ -22,6 +22,7 @@ func defaultTestTransitionerOptions() Options {
 		NodeEquilibriumWaitLimit: 5 * time.Minute,
 		TransitionDuration:       10 * time.Second,
 		RequeueDuration:          30 * time.Second,
+		CapacityWaitLimit:        20 * time.Minute,
 	}
 }
 
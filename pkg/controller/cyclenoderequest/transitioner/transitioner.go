@@ -2,6 +2,7 @@ package transitioner
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
@@ -18,6 +19,33 @@ type transitionFunc func() (reconcile.Result, error)
 // different request types.
 const cycleNodeLabel = "cyclops.atlassian.com/terminate"
 
+// healthCheckJobLabel is placed on the Jobs run by Job health checks. The value is the
+// name of the CycleNodeRequest which created the Job.
+const healthCheckJobLabel = "cyclops.atlassian.com/health-check"
+
+// healthCheckJobTTLSeconds is how long a finished health check Job is kept for if Cyclops doesn't
+// delete it, e.g. the CycleNodeRequest stopped waiting for it.
+const healthCheckJobTTLSeconds = int32(60 * 60)
+
+// maxRetryBackoffShift caps how many times the retry backoff is doubled, so the wait stops
+// growing at 1024 times the backoff of the first retry.
+const maxRetryBackoffShift = 10
+
+// globalConcurrencyLock serialises selecting nodes to cycle against the GlobalConcurrency budget
+// across the CycleNodeRequests being reconciled.
+var globalConcurrencyLock sync.Mutex
+
+const (
+	// maxHealthCheckBodyLength is how much of a response body is included in health check errors.
+	maxHealthCheckBodyLength = 256
+
+	// maxHealthCheckErrorLength is how much of the last error of a health check is recorded in its result.
+	maxHealthCheckErrorLength = 512
+
+	// truncatedSuffix marks where a body or error was cut short.
+	truncatedSuffix = "..."
+)
+
 const (
 	// cyclopsManagedAnnotation marks nodes where Cyclops added the scale-down-disabled annotation.
 	cyclopsManagedAnnotation = k8s.CyclopsManagedAnnotation
@@ -58,6 +86,14 @@ type Options struct {
 	// HealthCheckTimeout controls the duration of the timeout period for health checks performed on nodes
 	HealthCheckTimeout time.Duration
 
+	// HealthCheckJobServiceAccounts are the service accounts Job health checks are allowed to run as.
+	// Jobs which don't set a service account run without a service account token.
+	HealthCheckJobServiceAccounts []string
+
+	// HealthCheckCredentialSecrets are the Secrets in the namespace of the CycleNodeRequest which the requests of
+	// checks are allowed to read header values and bearer tokens from.
+	HealthCheckCredentialSecrets []string
+
 	// ScaleUpWait is the minimum time the transitioner waits after detaching
 	// instances before checking whether replacement Kubernetes nodes have
 	// become Ready.
@@ -80,6 +116,15 @@ type Options struct {
 	// an external condition within a phase (e.g. ScalingUp readiness,
 	// WaitingTermination).
 	RequeueDuration time.Duration
+
+	// CapacityWaitLimit caps how long the transitioner will wait for the
+	// cluster to have enough capacity for the pods on a batch of nodes
+	// before cordoning them, when CheckCapacity is enabled.
+	CapacityWaitLimit time.Duration
+
+	// GlobalConcurrency is the maximum number of nodes being cycled at once
+	// across all CycleNodeRequests in the namespace. Zero means no limit.
+	GlobalConcurrency int64
 }
 
 // NewCycleNodeRequestTransitioner returns a new cycleNodeRequest transitioner.
@@ -121,14 +166,15 @@ func (t *CycleNodeRequestTransitioner) Run() (reconcile.Result, error) {
 
 func (t *CycleNodeRequestTransitioner) transitionFuncs() map[v1.CycleNodeRequestPhase]transitionFunc {
 	return map[v1.CycleNodeRequestPhase]transitionFunc{
-		v1.CycleNodeRequestUndefined:          t.transitionUndefined,
-		v1.CycleNodeRequestPending:            t.transitionPending,
-		v1.CycleNodeRequestInitialised:        t.transitionInitialised,
-		v1.CycleNodeRequestScalingUp:          t.transitionScalingUp,
-		v1.CycleNodeRequestCordoningNode:      t.transitionCordoning,
-		v1.CycleNodeRequestWaitingTermination: t.transitionWaitingTermination,
-		v1.CycleNodeRequestFailed:             t.transitionFailed,
-		v1.CycleNodeRequestSuccessful:         t.transitionSuccessful,
-		v1.CycleNodeRequestHealing:            t.transitionHealing,
+		v1.CycleNodeRequestUndefined:           t.transitionUndefined,
+		v1.CycleNodeRequestPending:             t.transitionPending,
+		v1.CycleNodeRequestInitialised:         t.transitionInitialised,
+		v1.CycleNodeRequestScalingUp:           t.transitionScalingUp,
+		v1.CycleNodeRequestCordoningNode:       t.transitionCordoning,
+		v1.CycleNodeRequestWaitingTermination:  t.transitionWaitingTermination,
+		v1.CycleNodeRequestFailed:              t.transitionFailed,
+		v1.CycleNodeRequestSuccessful:          t.transitionSuccessful,
+		v1.CycleNodeRequestPartiallySuccessful: t.transitionSuccessful,
+		v1.CycleNodeRequestHealing:             t.transitionHealing,
 	}
 }
@@ -10,11 +10,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
 	"github.com/atlassian-labs/cyclops/pkg/k8s"
 	"github.com/pkg/errors"
 
 	"k8s.io/apimachinery/pkg/util/validation"
-	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -45,6 +45,16 @@ func (t *CycleNodeRequestTransitioner) transitionUndefined() (reconcile.Result,
 		return t.transitionToFailed(fmt.Errorf("%s", strings.Join(validationErrors, ",")))
 	}
 
+	// Only the Secrets the operator has opted in to can be read by the requests of checks
+	if err := controller.ValidateCheckSecretReferences(
+		t.options.HealthCheckCredentialSecrets,
+		t.cycleNodeRequest.Spec.HealthChecks,
+		t.cycleNodeRequest.Spec.ClusterHealthChecks,
+		t.cycleNodeRequest.Spec.PreTerminationChecks,
+	); err != nil {
+		return t.transitionToFailed(err)
+	}
+
 	// Transition the object to pending
 	return t.transitionObject(v1.CycleNodeRequestPending)
 }
@@ -55,6 +65,17 @@ func (t *CycleNodeRequestTransitioner) transitionUndefined() (reconcile.Result,
 //  2. describes the node group and checks that the number of instances in the node group matches the number we
 //     are planning on terminating
 func (t *CycleNodeRequestTransitioner) transitionPending() (reconcile.Result, error) {
+	// Wait for any other requests cycling the same nodes to finish first. Cycling the same nodes at once
+	// means fighting over labelling, draining and terminating them.
+	held, err := t.holdIfConflicting()
+	if err != nil {
+		return t.transitionToHealing(err)
+	}
+
+	if held {
+		return reconcile.Result{Requeue: true, RequeueAfter: t.options.RequeueDuration}, nil
+	}
+
 	// Start the equilibrium wait timer, if this times out then the set of nodes in kube and
 	// the cloud provider is not considered valid. Transition to the Healing phase as cycling
 	// should not proceed.
@@ -70,7 +91,7 @@ func (t *CycleNodeRequestTransitioner) transitionPending() (reconcile.Result, er
 	kubeNodes, nodeGroupInstances, err := t.findAllNodesForCycle()
 	if err != nil {
 		// Check if this is a retryable error (network timeout, etc.)
-		if isRetryableError(err) {
+		if controller.IsRetryableError(err) {
 			t.rm.Logger.Info("Retryable error encountered, requeuing", "error", err.Error())
 			// Requeue with backoff instead of transitioning to Healing
 			return reconcile.Result{
@@ -133,7 +154,12 @@ func (t *CycleNodeRequestTransitioner) transitionPending() (reconcile.Result, er
 	t.rm.Logger.Info("instance state valid, proceeding")
 
 	// make a list of the nodes to terminate
-	if len(t.cycleNodeRequest.Spec.NodeNames) > 0 {
+	if len(t.cycleNodeRequest.Status.NodesToTerminate) > 0 {
+		// The nodes to terminate were selected before a retry of a Failed request. Only the nodes
+		// which haven't been cycled yet and still match the selector are available to be picked up.
+		t.rm.LogEvent(t.cycleNodeRequest, "SelectingNodes", "Resuming with remaining NodesToTerminate")
+		t.setResumedNodesAvailable(validKubeNodes)
+	} else if len(t.cycleNodeRequest.Spec.NodeNames) > 0 {
 		// If specific node names are provided, check they actually exist in the node group
 		t.rm.LogEvent(t.cycleNodeRequest, "SelectingNodes", "Adding named nodes to NodesToTerminate")
 		err := t.addNamedNodesToTerminate(validKubeNodes, validNodeGroupInstances)
@@ -164,6 +190,13 @@ func (t *CycleNodeRequestTransitioner) transitionPending() (reconcile.Result, er
 		}
 	}
 
+	// Report any PodDisruptionBudgets which will stop the selected nodes from ever being drained
+	if t.cycleNodeRequest.Spec.CycleSettings.Method != v1.CycleNodeRequestMethodWait {
+		if err := t.checkBlockingPDBs(); err != nil {
+			return t.transitionToHealing(err)
+		}
+	}
+
 	if len(t.cycleNodeRequest.Spec.HealthChecks) > 0 {
 		if err = t.performInitialHealthChecks(validKubeNodes); err != nil {
 			return t.transitionToHealing(err)
@@ -196,6 +229,58 @@ func (t *CycleNodeRequestTransitioner) transitionInitialised() (reconcile.Result
 	// of nodes we are already working on, and only introduce up to our concurrency cap more nodes in this step.
 	maxNodesToSelect := t.cycleNodeRequest.Spec.CycleSettings.Concurrency - t.cycleNodeRequest.Status.ActiveChildren
 
+	// The nodes we can select are also bounded by the cluster wide budget shared by all CycleNodeRequests,
+	// so that many requests running at once can't take too much capacity out of the cluster.
+	if t.options.GlobalConcurrency > 0 {
+		// Counting the nodes in progress and selecting more is serialised so that requests reconciled at the
+		// same time can't both take the last of the budget. The lock is held until the selected nodes have
+		// been recorded in the status, where they are counted by other requests.
+		globalConcurrencyLock.Lock()
+		defer globalConcurrencyLock.Unlock()
+
+		globalNodesInProgress, err := t.countGlobalNodesInProgress()
+		if err != nil {
+			return t.transitionToHealing(err)
+		}
+
+		if globalNodesAvailable := t.options.GlobalConcurrency - globalNodesInProgress; globalNodesAvailable < maxNodesToSelect {
+			maxNodesToSelect = globalNodesAvailable
+		}
+
+		if maxNodesToSelect <= 0 {
+			t.rm.LogEvent(t.cycleNodeRequest, "WaitingGlobalConcurrency",
+				"Global concurrency of %d nodes reached, waiting for other nodes to finish cycling", t.options.GlobalConcurrency)
+
+			// Keep reaping our own children while waiting for the budget to free up
+			if t.cycleNodeRequest.Status.ActiveChildren > 0 {
+				return t.transitionObject(v1.CycleNodeRequestWaitingTermination)
+			}
+
+			return reconcile.Result{Requeue: true, RequeueAfter: t.options.RequeueDuration}, nil
+		}
+	}
+
+	// Don't start cycling another batch of nodes while the cluster is unhealthy, e.g. error rates are climbing
+	if len(t.cycleNodeRequest.Status.NodesAvailable) > 0 {
+		clusterHealthy, err := t.performClusterHealthChecks()
+		if err != nil {
+			return t.transitionToHealing(err)
+		}
+
+		if !clusterHealthy {
+			// Keep reaping our own children while waiting for the cluster to become healthy
+			if t.cycleNodeRequest.Status.ActiveChildren > 0 {
+				return t.transitionObject(v1.CycleNodeRequestWaitingTermination)
+			}
+
+			if err := t.rm.UpdateObject(t.cycleNodeRequest); err != nil {
+				return t.transitionToHealing(err)
+			}
+
+			return reconcile.Result{Requeue: true, RequeueAfter: t.options.RequeueDuration}, nil
+		}
+	}
+
 	t.rm.Logger.Info("Selecting nodes to terminate", "numNodes", maxNodesToSelect)
 
 	nodes, numNodesInProgress, err := t.getNodesToTerminate(maxNodesToSelect)
@@ -203,7 +288,7 @@ func (t *CycleNodeRequestTransitioner) transitionInitialised() (reconcile.Result
 		return t.transitionToHealing(err)
 	}
 
-	t.cycleNodeRequest.Status.NumNodesCycled = len(t.cycleNodeRequest.Status.NodesToTerminate) - len(t.cycleNodeRequest.Status.NodesAvailable) - numNodesInProgress - len(nodes)
+	t.cycleNodeRequest.Status.NumNodesCycled = len(t.cycleNodeRequest.Status.NodesToTerminate) - len(t.cycleNodeRequest.Status.NodesAvailable) - len(t.cycleNodeRequest.Status.SkippedNodes) - numNodesInProgress - len(nodes)
 
 	// Check if we can transition to WaitingTermination or Successful
 	if transitioning, reconcileResult, err := t.checkIfTransitioning(len(nodes), numNodesInProgress); transitioning {
@@ -227,7 +312,7 @@ func (t *CycleNodeRequestTransitioner) transitionInitialised() (reconcile.Result
 		}
 	}
 
-	t.cycleNodeRequest.Status.NumNodesCycled = len(t.cycleNodeRequest.Status.NodesToTerminate) - len(t.cycleNodeRequest.Status.NodesAvailable) - numNodesInProgress - len(validProviderIDs)
+	t.cycleNodeRequest.Status.NumNodesCycled = len(t.cycleNodeRequest.Status.NodesToTerminate) - len(t.cycleNodeRequest.Status.NodesAvailable) - len(t.cycleNodeRequest.Status.SkippedNodes) - numNodesInProgress - len(validProviderIDs)
 
 	// This is done a second time to account for a race condition where an instance on cloud provider is no longer running but is still registered in kube
 	// If the check were performed before the transition to WaitingTermination above, cyclops would perform many requests and eventually get rate limited by cloud provider
@@ -437,10 +522,25 @@ func (t *CycleNodeRequestTransitioner) transitionCordoning() (reconcile.Result,
 		t.rm.Logger.Info("Skipping pre-termination checks")
 	}
 
+	// Don't drain the nodes into a cluster which can't absorb their pods, wait for capacity instead
+	if t.cycleNodeRequest.Spec.CycleSettings.CheckCapacity {
+		hasCapacity, err := t.checkDrainCapacity()
+		if err != nil {
+			return t.transitionToHealing(err)
+		}
+
+		if !hasCapacity {
+			if err := t.rm.UpdateObject(t.cycleNodeRequest); err != nil {
+				return t.transitionToHealing(err)
+			}
+
+			return reconcile.Result{Requeue: true, RequeueAfter: t.options.RequeueDuration}, nil
+		}
+	}
+
 	allNodesReadyForTermination := true
 	for _, node := range t.cycleNodeRequest.Status.CurrentNodes {
-		// If the node is not already cordoned, cordon it
-		cordoned, err := k8s.IsCordoned(node.Name, t.rm.RawClient)
+		kubeNode, err := t.rm.RawClient.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
 		// Skip handling the node if it doesn't exist
 		if apierrors.IsNotFound(err) {
 			continue
@@ -449,12 +549,40 @@ func (t *CycleNodeRequestTransitioner) transitionCordoning() (reconcile.Result,
 			t.rm.Logger.Error(err, "failed to check if node is cordoned", "nodeName", node.Name)
 			return t.transitionToHealing(err)
 		}
-		if !cordoned {
+
+		// The node was handed off to its CycleNodeStatus in an earlier reconcile, while the other nodes were
+		// still waiting for their pre-cordon hooks or pre-termination checks
+		if kubeNode.Labels[cycleNodeLabel] == t.cycleNodeRequest.Name {
+			continue
+		}
+
+		// If the node is not already cordoned, cordon it
+		if !kubeNode.Spec.Unschedulable {
+			// Let external systems know the node is about to be cycled before it's cordoned
+			finished, err := t.callPreCordonHooks(node)
+			if err != nil {
+				t.rm.LogEvent(t.cycleNodeRequest,
+					"PreCordonHooksFailed", "failed to call pre-cordon lifecycle hooks for %s, err: %v", node.Name, err)
+				return t.transitionToHealing(errors.Wrapf(err, "failed to call pre-cordon lifecycle hooks for %s", node.Name))
+			}
+
+			// Blocking hooks haven't succeeded yet, but we can continue to call the hooks for the other nodes
+			if !finished {
+				allNodesReadyForTermination = false
+				continue
+			}
+
 			if err := k8s.CordonNode(node.Name, t.rm.RawClient); err != nil {
 				return t.transitionToHealing(err)
 			}
 		}
 
+		// Taint the node before it's drained so anything keyed off the taint can react
+		if err := t.addDrainTaint(node.Name); err != nil {
+			t.rm.Logger.Error(err, "failed to add drain taint to node", "nodeName", node.Name)
+			return t.transitionToHealing(err)
+		}
+
 		// Perform pre-termination checks after the node is cordoned
 		// Cruicially, do this before the CNS is created for node to begin termination
 		if !t.cycleNodeRequest.Spec.SkipPreTerminationChecks && len(t.cycleNodeRequest.Spec.PreTerminationChecks) > 0 {
@@ -483,8 +611,10 @@ func (t *CycleNodeRequestTransitioner) transitionCordoning() (reconcile.Result,
 			}
 		}
 
-		// Create a CycleNodeStatus CRD to start the termination process
-		if err := t.rm.Client.Create(context.TODO(), t.makeCycleNodeStatusForNode(node.Name)); err != nil {
+		// Create a CycleNodeStatus CRD to start the termination process. It may already exist if the node
+		// couldn't be labelled after it was created
+		err = t.rm.Client.Create(context.TODO(), t.makeCycleNodeStatusForNode(node.Name))
+		if err != nil && !apierrors.IsAlreadyExists(err) {
 			return t.transitionToHealing(err)
 		}
 
@@ -555,48 +685,7 @@ func (t *CycleNodeRequestTransitioner) transitionHealing() (reconcile.Result, er
 	}
 
 	for _, node := range t.cycleNodeRequest.Status.NodesToTerminate {
-		// nodes in NodesToTerminate may have been terminated, so check if they still exist
-		nodeExists, err := k8s.NodeExists(node.Name, t.rm.RawClient)
-		if err != nil {
-			return t.transitionToFailed(err)
-		}
-
-		if !nodeExists {
-			t.rm.LogEvent(t.cycleNodeRequest,
-				"HealingNodes", "Node does not exist, skip healing node: %s", node.Name)
-			continue
-		}
-
-		if err := t.rm.RemoveFinalizerFromNode(node.Name); err != nil {
-			t.rm.LogEvent(t.cycleNodeRequest, "RemoveFinalizerFromNodeError", err.Error())
-			return t.transitionToFailed(err)
-		}
-
-		// try and re-attach the nodes, if any were un-attached
-		t.rm.LogEvent(t.cycleNodeRequest, "AttachingNodes", "Attaching instances to nodes group: %v", node.Name)
-		// if the node is already attached, ignore the error and continue to un-cordoning, otherwise return with error
-		alreadyAttached, err := nodeGroups.AttachInstance(node.ProviderID, node.NodeGroupName)
-		if err != nil && !alreadyAttached {
-			return t.transitionToFailed(err)
-		}
-		if alreadyAttached {
-			t.rm.LogEvent(t.cycleNodeRequest,
-				"AttachingNodes", "Skip re-attaching instances to nodes group: %v, err: %v",
-				node.Name, err)
-		}
-
-		// un-cordon after attach as well
-		t.rm.LogEvent(t.cycleNodeRequest, "UncordoningNodes", "Uncordoning nodes in node group: %v", node.Name)
-
-		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			return k8s.UncordonNode(node.Name, t.rm.RawClient)
-		})
-
-		if apierrors.IsNotFound(err) {
-			continue
-		}
-
-		if err != nil {
+		if err := t.healNode(nodeGroups, node); err != nil {
 			return t.transitionToFailed(err)
 		}
 	}
@@ -614,6 +703,11 @@ func (t *CycleNodeRequestTransitioner) transitionFailed() (reconcile.Result, err
 		return reconcile.Result{Requeue: true, RequeueAfter: t.options.TransitionDuration}, nil
 	}
 
+	// Resume cycling the remaining nodes if a retry has been requested, e.g. by kubectl cycle retry
+	if _, ok := t.cycleNodeRequest.Annotations[v1.CycleNodeRequestRetryAnnotation]; ok {
+		return t.resumeFromFailed()
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -0,0 +1,406 @@
+package transitioner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// buildCapacityCheckCNR returns a CNR in the CordoningNode phase working on
+// the first node of the nodegroup with the capacity check enabled.
+func buildCapacityCheckCNR(nodegroup []*mock.Node) *v1.CycleNodeRequest {
+	return &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Method:        v1.CycleNodeRequestMethodDrain,
+				Concurrency:   1,
+				CheckCapacity: true,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase: v1.CycleNodeRequestCordoningNode,
+			CurrentNodes: []v1.CycleNodeRequestNode{
+				{Name: nodegroup[0].Name, NodeGroupName: "ng-1"},
+			},
+		},
+	}
+}
+
+// buildCapacityCheckPod returns a running pod on the node requesting 1 cpu
+func buildCapacityCheckPod(nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-1",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
+
+// Test that the nodes are not cordoned while the remaining nodes don't have
+// enough capacity for the pods on them, and the CNR waits instead.
+func TestCordoningWaitsForCapacity(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildCapacityCheckPod(nodegroup[0].Name)),
+	)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, v1.CycleNodeRequestCordoningNode, cnr.Status.Phase)
+	assert.NotNil(t, cnr.Status.CapacityWaitStarted)
+
+	cordoned, err := k8s.IsCordoned(nodegroup[0].Name, fakeTransitioner.RawClient)
+	require.NoError(t, err)
+	assert.False(t, cordoned)
+
+	var cnsList v1.CycleNodeStatusList
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	assert.Empty(t, cnsList.Items)
+}
+
+// Test that the nodes are cordoned once there is enough capacity on the
+// remaining nodes for the pods on them.
+func TestCordoningWithCapacity(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+	nodegroup[1].CPU = 2000
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	waitStarted := metav1.Now()
+	cnr.Status.CapacityWaitStarted = &waitStarted
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildCapacityCheckPod(nodegroup[0].Name)),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+	assert.Nil(t, cnr.Status.CapacityWaitStarted)
+
+	cordoned, err := k8s.IsCordoned(nodegroup[0].Name, fakeTransitioner.RawClient)
+	require.NoError(t, err)
+	assert.True(t, cordoned)
+}
+
+// Test that the CNR is healed if there is still not enough capacity once the
+// capacity wait limit has been reached.
+func TestCordoningCapacityWaitTimeout(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	waitStarted := metav1.NewTime(time.Now().Add(-time.Hour))
+	cnr.Status.CapacityWaitStarted = &waitStarted
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildCapacityCheckPod(nodegroup[0].Name)),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.Error(t, err)
+	assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
+	assert.Contains(t, cnr.Status.Message, "waiting for cluster capacity")
+}
+
+// Test that the capacity isn't checked again once nodes of the batch have been
+// handed off, so the pods on the draining nodes don't hold up the rest of it.
+func TestCordoningSkipsCapacityCheckOnceStarted(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	waitStarted := metav1.NewTime(time.Now().Add(-time.Hour))
+	cnr.Status.CapacityWaitStarted = &waitStarted
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildCapacityCheckPod(nodegroup[0].Name)),
+	)
+
+	require.NoError(t, k8s.CordonNode(nodegroup[0].Name, fakeTransitioner.RawClient))
+	require.NoError(t, k8s.AddLabelToNode(nodegroup[0].Name, cycleNodeLabel, cnr.Name, fakeTransitioner.RawClient))
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+}
+
+// Test that the capacity is still checked if the nodes have been cordoned by
+// something other than this CNR.
+func TestCordoningChecksCapacityOfExternallyCordonedNodes(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildCapacityCheckPod(nodegroup[0].Name)),
+	)
+
+	require.NoError(t, k8s.CordonNode(nodegroup[0].Name, fakeTransitioner.RawClient))
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, v1.CycleNodeRequestCordoningNode, cnr.Status.Phase)
+	assert.NotNil(t, cnr.Status.CapacityWaitStarted)
+
+	var cnsList v1.CycleNodeStatusList
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	assert.Empty(t, cnsList.Items)
+}
+
+// Test that the drain taint is added to the nodes when they are cordoned and
+// the nodes are tracked so the taint can be removed later. A NoExecute taint
+// is only added as NoSchedule so the pods are evicted by the drain.
+func TestCordoningAddsDrainTaint(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	cnr.Spec.CycleSettings.CheckCapacity = false
+	cnr.Spec.CycleSettings.DrainTaint = &v1.DrainTaint{
+		Key:    "cyclops.atlassian.com/cycling",
+		Value:  "true",
+		Effect: string(corev1.TaintEffectNoExecute),
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+	assert.Equal(t, []string{nodegroup[0].Name}, cnr.Status.TaintedNodes)
+
+	node, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+	require.Len(t, node.Spec.Taints, 1)
+	assert.Equal(t, "cyclops.atlassian.com/cycling", node.Spec.Taints[0].Key)
+	assert.Equal(t, corev1.TaintEffectNoSchedule, node.Spec.Taints[0].Effect)
+
+	// The node which wasn't selected is left alone
+	node, err = fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[1].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, node.Spec.Taints)
+}
+
+// Test that healing removes the drain taint from the nodes which were tainted
+// and leaves the taint on nodes which Cyclops didn't add it to.
+func TestHealingRemovesDrainTaint(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	// Both nodes start with the taint the mock adds
+	for _, node := range nodegroup {
+		node.Tainted = true
+	}
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	cnr.Spec.CycleSettings.DrainTaint = &v1.DrainTaint{
+		Key:    "atlassian.com/cyclops",
+		Effect: string(corev1.TaintEffectNoSchedule),
+	}
+	cnr.Status.Phase = v1.CycleNodeRequestHealing
+	cnr.Status.TaintedNodes = []string{nodegroup[0].Name}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	for _, node := range nodegroup {
+		cnr.Status.NodesToTerminate = append(cnr.Status.NodesToTerminate, v1.CycleNodeRequestNode{
+			Name:          node.Name,
+			ProviderID:    node.ProviderID,
+			NodeGroupName: "ng-1",
+		})
+	}
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestFailed, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.TaintedNodes)
+
+	node, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, node.Spec.Taints)
+	assert.False(t, node.Spec.Unschedulable)
+
+	node, err = fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[1].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, node.Spec.Taints, 1)
+}
+
+// Test that healing also removes the NoExecute drain taint the CycleNodeStatus
+// adds once the node has been drained.
+func TestHealingRemovesNoExecuteDrainTaint(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	cnr.Spec.CycleSettings.DrainTaint = &v1.DrainTaint{
+		Key:    "cyclops.atlassian.com/cycling",
+		Effect: string(corev1.TaintEffectNoExecute),
+	}
+	cnr.Status.Phase = v1.CycleNodeRequestHealing
+	cnr.Status.TaintedNodes = []string{nodegroup[0].Name}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	cnr.Status.NodesToTerminate = append(cnr.Status.NodesToTerminate, v1.CycleNodeRequestNode{
+		Name:          nodegroup[0].Name,
+		ProviderID:    nodegroup[0].ProviderID,
+		NodeGroupName: "ng-1",
+	})
+
+	for _, effect := range []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute} {
+		require.NoError(t, k8s.AddTaintToNode(nodegroup[0].Name, corev1.Taint{
+			Key:    "cyclops.atlassian.com/cycling",
+			Effect: effect,
+		}, fakeTransitioner.RawClient))
+	}
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+	assert.Empty(t, cnr.Status.TaintedNodes)
+
+	node, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, node.Spec.Taints)
+}
+
+// Test that the nodes whose blocking PreCordon hooks have succeeded are handed
+// off to a CycleNodeStatus while another node is still waiting on its hook, and
+// aren't handed off again when the CNR is requeued.
+func TestCordoningWaitsForBlockingPreCordonHook(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	calls := make(map[string]int)
+	blockedNode := nodegroup[1].Name
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request controller.LifecycleHookRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		calls[request.Node.Name]++
+		if request.Node.Name == blockedNode {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cnr := buildCapacityCheckCNR(nodegroup)
+	cnr.Spec.CycleSettings.CheckCapacity = false
+	cnr.Spec.CycleSettings.LifecycleHooks = []v1.LifecycleHook{{
+		Name:     "cmdb",
+		Event:    v1.LifecycleHookPreCordon,
+		Endpoint: server.URL,
+		Blocking: true,
+	}}
+	cnr.Status.CurrentNodes = []v1.CycleNodeRequestNode{
+		{Name: nodegroup[0].Name, NodeGroupName: "ng-1"},
+		{Name: nodegroup[1].Name, NodeGroupName: "ng-1"},
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	// The first node is handed off while the hook for the second is failing
+	for i := 0; i < 2; i++ {
+		result, err := fakeTransitioner.Run()
+		require.NoError(t, err)
+		assert.True(t, result.Requeue)
+		assert.Equal(t, v1.CycleNodeRequestCordoningNode, cnr.Status.Phase)
+	}
+
+	assert.Equal(t, 1, calls[nodegroup[0].Name])
+	assert.Equal(t, 2, calls[nodegroup[1].Name])
+
+	cordoned, err := k8s.IsCordoned(nodegroup[1].Name, fakeTransitioner.RawClient)
+	require.NoError(t, err)
+	assert.False(t, cordoned)
+
+	var cnsList v1.CycleNodeStatusList
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	require.Len(t, cnsList.Items, 1)
+	assert.Equal(t, nodegroup[0].Name, cnsList.Items[0].Spec.NodeName)
+
+	// Once the hook succeeds the second node is handed off as well
+	blockedNode = ""
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+	assert.Equal(t, 1, calls[nodegroup[0].Name])
+	assert.Equal(t, 3, calls[nodegroup[1].Name])
+
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	assert.Len(t, cnsList.Items, 2)
+}
@@ -0,0 +1,149 @@
+package transitioner
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildResumedCNR returns a CNR which selected all the nodes in the nodegroup
+// and has already cycled the first one.
+func buildResumedCNR(nodegroup []*mock.Node, phase v1.CycleNodeRequestPhase) *v1.CycleNodeRequest {
+	nodesToTerminate := make([]v1.CycleNodeRequestNode, 0, len(nodegroup))
+	for _, node := range nodegroup {
+		nodesToTerminate = append(nodesToTerminate, v1.CycleNodeRequestNode{
+			Name:          node.Name,
+			NodeGroupName: "ng-1",
+		})
+	}
+
+	return &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Method:      v1.CycleNodeRequestMethodDrain,
+				Concurrency: 1,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase:            phase,
+			NodesToTerminate: nodesToTerminate,
+			NumNodesCycled:   1,
+		},
+	}
+}
+
+// Test that a Failed CNR without the retry annotation stays Failed.
+func TestFailedWithoutRetry(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildResumedCNR(nodegroup, v1.CycleNodeRequestFailed)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+
+	var stored v1.CycleNodeRequest
+	require.NoError(t, fakeTransitioner.K8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cnr), &stored))
+	assert.Equal(t, v1.CycleNodeRequestFailed, stored.Status.Phase)
+}
+
+// Test that a Failed CNR with the retry annotation is moved back to Pending.
+// The remaining nodes should be made selectable again while the progress made
+// before the failure is kept.
+func TestFailedResumesWithRetryAnnotation(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 3)
+	require.NoError(t, err)
+
+	cnr := buildResumedCNR(nodegroup, v1.CycleNodeRequestFailed)
+	cnr.Annotations = map[string]string{v1.CycleNodeRequestRetryAnnotation: "2024-01-01T00:00:00Z"}
+	cnr.Status.Message = "failed to drain node ng-1-node-1"
+	cnr.Status.NodesAvailable = []v1.CycleNodeRequestNode{cnr.Status.NodesToTerminate[2]}
+	cnr.Status.NodeAttempts = map[string]v1.CycleNodeAttemptStatus{
+		nodegroup[1].Name: {},
+	}
+
+	// The first node has already been cycled and no longer exists
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup[1:]),
+		WithCloudProviderInstances(nodegroup[1:]),
+	)
+
+	require.NoError(t, k8s.AddLabelToNode(nodegroup[1].Name, cycleNodeLabel, cnr.Name, fakeTransitioner.RawClient))
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+
+	assert.Equal(t, v1.CycleNodeRequestPending, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.Message)
+	assert.Empty(t, cnr.Status.NodesAvailable)
+	assert.NotContains(t, cnr.Annotations, v1.CycleNodeRequestRetryAnnotation)
+	assert.Len(t, cnr.Status.NodesToTerminate, 3)
+	assert.Equal(t, 1, cnr.Status.NumNodesCycled)
+	assert.Contains(t, cnr.Status.NodeAttempts, nodegroup[1].Name)
+
+	node, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[1].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, node.Labels, cycleNodeLabel)
+}
+
+// Test that a resumed CNR in the Pending phase only makes the nodes which
+// haven't been cycled available and doesn't select the new nodes.
+func TestPendingResumesRemainingNodes(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 3)
+	require.NoError(t, err)
+
+	cnr := buildResumedCNR(nodegroup, v1.CycleNodeRequestPending)
+
+	// The first node has been replaced by a new node since the CNR failed
+	replacement, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+	replacement[0].Name = "ng-1-node-new"
+
+	kubeNodes := append([]*mock.Node{replacement[0]}, nodegroup[1:]...)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(kubeNodes),
+		WithCloudProviderInstances(kubeNodes),
+	)
+
+	// Provider IDs are only generated once the fake client has been built
+	for i, node := range nodegroup {
+		cnr.Status.NodesToTerminate[i].ProviderID = node.ProviderID
+	}
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+	assert.Len(t, cnr.Status.NodesToTerminate, 3)
+	assert.Equal(t, []v1.CycleNodeRequestNode{
+		cnr.Status.NodesToTerminate[1],
+		cnr.Status.NodesToTerminate[2],
+	}, cnr.Status.NodesAvailable)
+}
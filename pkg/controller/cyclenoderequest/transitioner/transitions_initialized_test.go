@@ -2,14 +2,20 @@ package transitioner
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
 	"github.com/atlassian-labs/cyclops/pkg/mock"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Base case of the Initialized phase. Start cycling by detaching an instance
@@ -92,3 +98,318 @@ func TestInitializedSimpleCase(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "ng-1", nodegroupName)
 }
+
+// buildGlobalConcurrencyCNR returns a CNR straight after being transitioned
+// from Pending which can cycle all the nodes in the nodegroup at once.
+func buildGlobalConcurrencyCNR(nodegroup []*mock.Node) *v1.CycleNodeRequest {
+	var nodes []v1.CycleNodeRequestNode
+	for _, node := range nodegroup {
+		nodes = append(nodes, v1.CycleNodeRequestNode{
+			Name:          node.Name,
+			NodeGroupName: node.Nodegroup,
+		})
+	}
+
+	return &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Concurrency: int64(len(nodegroup)),
+				Method:      v1.CycleNodeRequestMethodDrain,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase:            v1.CycleNodeRequestInitialised,
+			NodesToTerminate: nodes,
+			NodesAvailable:   append([]v1.CycleNodeRequestNode{}, nodes...),
+		},
+	}
+}
+
+// buildOtherCycles returns the objects of another CNR which is cycling two
+// nodes, one in the ScalingUp phase and one with an active CNS.
+func buildOtherCycles() []client.Object {
+	return []client.Object{
+		&v1.CycleNodeRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cnr-2",
+				Namespace: "kube-system",
+			},
+			Status: v1.CycleNodeRequestStatus{
+				Phase:        v1.CycleNodeRequestScalingUp,
+				CurrentNodes: []v1.CycleNodeRequestNode{{Name: "ng-2-node-1"}},
+			},
+		},
+		&v1.CycleNodeStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cnr-2-ng-2-node-0",
+				Namespace: "kube-system",
+				Labels:    map[string]string{"name": "cnr-2"},
+			},
+			Status: v1.CycleNodeStatusStatus{
+				Phase: v1.CycleNodeStatusDrainingPods,
+			},
+		},
+		&v1.CycleNodeStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cnr-3-ng-3-node-0",
+				Namespace: "kube-system",
+				Labels:    map[string]string{"name": "cnr-3"},
+			},
+			Status: v1.CycleNodeStatusStatus{
+				Phase: v1.CycleNodeStatusSuccessful,
+			},
+		},
+	}
+}
+
+// Test that the nodes selected are limited by the global concurrency when
+// other CNRs are already cycling nodes.
+func TestInitializedGlobalConcurrencyLimitsSelection(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildGlobalConcurrencyCNR(nodegroup)
+
+	options := defaultTestTransitionerOptions()
+	options.GlobalConcurrency = 3
+
+	opts := []Option{
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithTransitionerOptions(options),
+	}
+	for _, obj := range buildOtherCycles() {
+		opts = append(opts, WithExtraKubeObject(obj))
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr, opts...)
+
+	for i, node := range nodegroup {
+		cnr.Status.NodesToTerminate[i].ProviderID = node.ProviderID
+		cnr.Status.NodesAvailable[i].ProviderID = node.ProviderID
+	}
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestScalingUp, cnr.Status.Phase)
+	assert.Len(t, cnr.Status.CurrentNodes, 1)
+	assert.Len(t, cnr.Status.NodesAvailable, 1)
+}
+
+// Test that no nodes are selected while the global concurrency is used up by
+// other CNRs and the CNR waits in the Initialised phase.
+func TestInitializedGlobalConcurrencyReached(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildGlobalConcurrencyCNR(nodegroup)
+
+	options := defaultTestTransitionerOptions()
+	options.GlobalConcurrency = 2
+
+	opts := []Option{
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithTransitionerOptions(options),
+	}
+	for _, obj := range buildOtherCycles() {
+		opts = append(opts, WithExtraKubeObject(obj))
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr, opts...)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, options.RequeueDuration, result.RequeueAfter)
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.CurrentNodes)
+	assert.Len(t, cnr.Status.NodesAvailable, 2)
+}
+
+// Test that nodes other CNRs are waiting to retry count towards the global
+// concurrency, even though their previous CycleNodeStatus has failed.
+func TestInitializedGlobalConcurrencyCountsPendingRetries(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildGlobalConcurrencyCNR(nodegroup)
+
+	options := defaultTestTransitionerOptions()
+	options.GlobalConcurrency = 3
+
+	nextAttempt := metav1.NewTime(time.Now().Add(time.Minute))
+
+	opts := []Option{
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithTransitionerOptions(options),
+		WithExtraKubeObject(&v1.CycleNodeRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cnr-4",
+				Namespace: "kube-system",
+			},
+			Status: v1.CycleNodeRequestStatus{
+				Phase: v1.CycleNodeRequestWaitingTermination,
+				NodeAttempts: map[string]v1.CycleNodeAttemptStatus{
+					"ng-4-node-0": {NextAttempt: &nextAttempt},
+					"ng-4-node-1": {},
+				},
+			},
+		}),
+		WithExtraKubeObject(&v1.CycleNodeStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cnr-4-ng-4-node-0",
+				Namespace: "kube-system",
+				Labels:    map[string]string{"name": "cnr-4"},
+			},
+			Status: v1.CycleNodeStatusStatus{
+				Phase: v1.CycleNodeStatusFailed,
+			},
+		}),
+	}
+	for _, obj := range buildOtherCycles() {
+		opts = append(opts, WithExtraKubeObject(obj))
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr, opts...)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.CurrentNodes)
+	assert.Len(t, cnr.Status.NodesAvailable, 2)
+}
+
+// Test that a CNR with its own active children waits for them in the
+// WaitingTermination phase while the global concurrency is used up.
+func TestInitializedGlobalConcurrencyReachedWithActiveChildren(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildGlobalConcurrencyCNR(nodegroup)
+	cnr.Status.ActiveChildren = 1
+
+	options := defaultTestTransitionerOptions()
+	options.GlobalConcurrency = 2
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithTransitionerOptions(options),
+		WithExtraKubeObject(&v1.CycleNodeStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cnr-1-ng-1-node-0",
+				Namespace: "kube-system",
+				Labels:    map[string]string{"name": "cnr-1"},
+			},
+			Status: v1.CycleNodeStatusStatus{
+				Phase: v1.CycleNodeStatusDrainingPods,
+			},
+		}),
+		WithExtraKubeObject(buildOtherCycles()[0]),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.CurrentNodes)
+}
+
+// Test that no nodes are selected while a cluster health check is failing, and
+// that cycling continues once it passes again.
+func TestInitializedClusterHealthChecksGateSelection(t *testing.T) {
+	errorRate := "0.2"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"%s"]}]}}`, errorRate)
+	}))
+	defer server.Close()
+
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildGlobalConcurrencyCNR(nodegroup)
+	cnr.Spec.ClusterHealthChecks = []v1.HealthCheck{{
+		Type:     v1.HealthCheckPrometheus,
+		Endpoint: server.URL,
+		Prometheus: &v1.PrometheusHealthCheck{
+			Query:     `sum(rate(http_requests_total{code=~"5.."}[5m])) / sum(rate(http_requests_total[5m]))`,
+			Operator:  v1.PrometheusLessThan,
+			Threshold: "0.05",
+		},
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	for i, node := range nodegroup {
+		cnr.Status.NodesToTerminate[i].ProviderID = node.ProviderID
+		cnr.Status.NodesAvailable[i].ProviderID = node.ProviderID
+	}
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, fakeTransitioner.options.RequeueDuration, result.RequeueAfter)
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.CurrentNodes)
+	assert.Len(t, cnr.Status.NodesAvailable, 2)
+	assert.NotNil(t, cnr.Status.ClusterHealthCheckStarted)
+
+	errorRate = "0.01"
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestScalingUp, cnr.Status.Phase)
+	assert.Len(t, cnr.Status.CurrentNodes, 2)
+	assert.Nil(t, cnr.Status.ClusterHealthCheckStarted)
+}
+
+// Test that the CNR fails if the cluster health checks don't pass within their
+// wait period.
+func TestInitializedClusterHealthChecksTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"0.2"]}}`))
+	}))
+	defer server.Close()
+
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cnr := buildGlobalConcurrencyCNR(nodegroup)
+	cnr.Spec.ClusterHealthChecks = []v1.HealthCheck{{
+		Type:     v1.HealthCheckPrometheus,
+		Endpoint: server.URL,
+		Prometheus: &v1.PrometheusHealthCheck{
+			Query:     "job:error_rate:ratio5m",
+			Operator:  v1.PrometheusLessThan,
+			Threshold: "0.05",
+		},
+		WaitPeriod: &metav1.Duration{Duration: 10 * time.Minute},
+	}}
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	cnr.Status.ClusterHealthCheckStarted = &started
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.Error(t, err)
+	assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.CurrentNodes)
+}
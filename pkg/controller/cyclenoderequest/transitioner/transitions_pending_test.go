@@ -1,6 +1,8 @@
 package transitioner
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,8 +12,14 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 )
 
 // Basic test to ensure the base functionality of the Pending phase works. A
@@ -759,3 +767,370 @@ func TestPendingMismatchingNodegroupName(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
 }
+
+// buildBlockingPDBObjects returns a pod on the node covered by a
+// PodDisruptionBudget which will never allow it to be evicted.
+func buildBlockingPDBObjects(nodeName string) (*corev1.Pod, *policyv1.PodDisruptionBudget) {
+	maxUnavailable := intstr.FromInt32(0)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-1",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "web"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	return pod, pdb
+}
+
+// Test that PodDisruptionBudgets which will never allow a pod on the selected
+// nodes to be evicted are only reported by default, and cycling continues. They
+// are reported once and recorded in the BlockingPodDisruptionBudgets condition.
+func TestPendingWithBlockingPDB(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Concurrency: 1,
+				Method:      v1.CycleNodeRequestMethodDrain,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase: v1.CycleNodeRequestPending,
+		},
+	}
+
+	pod, pdb := buildBlockingPDBObjects(nodegroup[0].Name)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(pod),
+		WithExtraKubeObject(pdb),
+	)
+	recorder := record.NewFakeRecorder(10)
+	fakeTransitioner.rm.Recorder = recorder
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+
+	condition := meta.FindStatusCondition(cnr.Status.Conditions, v1.CycleNodeRequestConditionBlockingPodDisruptionBudgets)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Contains(t, condition.Message, "default/web")
+
+	assert.Equal(t, 1, countEvents(recorder, "BlockingPodDisruptionBudget"))
+
+	// Checking the same nodes again doesn't report the PodDisruptionBudget again
+	require.NoError(t, fakeTransitioner.checkBlockingPDBs())
+	assert.Equal(t, 0, countEvents(recorder, "BlockingPodDisruptionBudget"))
+}
+
+// countEvents drains the events recorded so far and counts those with the reason
+func countEvents(recorder *record.FakeRecorder, reason string) int {
+	count := 0
+	for {
+		select {
+		case event := <-recorder.Events:
+			if strings.Contains(event, " "+reason+" ") {
+				count++
+			}
+		default:
+			return count
+		}
+	}
+}
+
+// Test that the CNR fails before cycling begins when configured to fail on
+// PodDisruptionBudgets which will never allow a pod on the selected nodes to
+// be evicted.
+func TestPendingFailOnBlockingPDB(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Concurrency: 1,
+				Method:      v1.CycleNodeRequestMethodDrain,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+			ValidationOptions: v1.ValidationOptions{
+				FailOnBlockingPodDisruptionBudgets: true,
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase: v1.CycleNodeRequestPending,
+		},
+	}
+
+	pod, pdb := buildBlockingPDBObjects(nodegroup[0].Name)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(pod),
+		WithExtraKubeObject(pdb),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.Error(t, err)
+	assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
+	assert.Contains(t, cnr.Status.Message, "default/web")
+}
+
+// buildPendingCNR builds a Pending CNR for the ng-1 node group, cycling only the named nodes if any are given,
+// the same as the CLI does. Without named nodes it cycles the whole node group, the same as the observer does.
+func buildPendingCNR(name string, created time.Time, nodeNames ...string) *v1.CycleNodeRequest {
+	return &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "kube-system",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Concurrency: 1,
+				Method:      v1.CycleNodeRequestMethodDrain,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+			NodeNames: nodeNames,
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase: v1.CycleNodeRequestPending,
+		},
+	}
+}
+
+// Test that a CNR is held in the Pending phase while other CNRs are cycling
+// any of the same nodes, whether they were created by the CLI or the observer,
+// and that it proceeds when it doesn't overlap.
+func TestPendingConflictingCycleNodeRequests(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 3)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	now := time.Now()
+
+	// A CNR created by the CLI for two of the nodes which is already cycling them
+	activeCLICNR := buildPendingCNR("cli-ng-1", now.Add(-time.Hour), nodegroup[0].Name, nodegroup[1].Name)
+	activeCLICNR.Status.Phase = v1.CycleNodeRequestScalingUp
+	activeCLICNR.Status.NodesToTerminate = []v1.CycleNodeRequestNode{
+		{Name: nodegroup[0].Name}, {Name: nodegroup[1].Name},
+	}
+
+	// A CNR created by the observer for the whole node group which hasn't started yet
+	pendingObserverCNR := buildPendingCNR("observer-ng-1-abcde", now.Add(-time.Minute))
+
+	finishedCNR := activeCLICNR.DeepCopy()
+	finishedCNR.Name = "finished"
+	finishedCNR.Status.Phase = v1.CycleNodeRequestSuccessful
+
+	tests := []struct {
+		name     string
+		cnr      *v1.CycleNodeRequest
+		existing []*v1.CycleNodeRequest
+		held     string
+	}{
+		{
+			"observer created held by an active cli created request",
+			buildPendingCNR("observer-ng-1-fghij", now),
+			[]*v1.CycleNodeRequest{activeCLICNR},
+			"cli-ng-1 (ScalingUp) on nodes ng-1-node-0, ng-1-node-1",
+		},
+		{
+			"cli created held by an active cli created request",
+			buildPendingCNR("cli-2-ng-1", now, nodegroup[1].Name),
+			[]*v1.CycleNodeRequest{activeCLICNR},
+			"cli-ng-1 (ScalingUp) on nodes ng-1-node-1",
+		},
+		{
+			"cli created held by an older pending observer created request",
+			buildPendingCNR("cli-2-ng-1", now, nodegroup[2].Name),
+			[]*v1.CycleNodeRequest{pendingObserverCNR},
+			"observer-ng-1-abcde (Pending) on nodes ng-1-node-2",
+		},
+		{
+			"older pending request goes before a newer one",
+			buildPendingCNR("cli-2-ng-1", now.Add(-2*time.Minute), nodegroup[2].Name),
+			[]*v1.CycleNodeRequest{pendingObserverCNR},
+			"",
+		},
+		{
+			"cli created request for nodes no one else is cycling",
+			buildPendingCNR("cli-2-ng-1", now, nodegroup[2].Name),
+			[]*v1.CycleNodeRequest{activeCLICNR},
+			"",
+		},
+		{
+			"finished requests don't hold",
+			buildPendingCNR("observer-ng-1-fghij", now),
+			[]*v1.CycleNodeRequest{finishedCNR},
+			"",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []Option{
+				WithKubeNodes(nodegroup),
+				WithCloudProviderInstances(nodegroup),
+			}
+
+			for _, cnr := range tc.existing {
+				opts = append(opts, WithExtraKubeObject(cnr.DeepCopy()))
+			}
+
+			fakeTransitioner := NewFakeTransitioner(tc.cnr, opts...)
+
+			result, err := fakeTransitioner.Run()
+			assert.NoError(t, err)
+			assert.True(t, result.Requeue)
+
+			conflicting := meta.FindStatusCondition(tc.cnr.Status.Conditions, v1.CycleNodeRequestConditionConflicting)
+
+			if tc.held == "" {
+				assert.Equal(t, v1.CycleNodeRequestInitialised, tc.cnr.Status.Phase)
+				assert.Nil(t, conflicting)
+				return
+			}
+
+			assert.Equal(t, v1.CycleNodeRequestPending, tc.cnr.Status.Phase)
+			assert.Nil(t, tc.cnr.Status.EquilibriumWaitStarted)
+			assert.Empty(t, tc.cnr.Status.NodesToTerminate)
+
+			if assert.NotNil(t, conflicting) {
+				assert.Equal(t, metav1.ConditionTrue, conflicting.Status)
+				assert.Equal(t, v1.CycleNodeRequestReasonOverlappingRequest, conflicting.Reason)
+				assert.Contains(t, conflicting.Message, tc.held)
+			}
+		})
+	}
+}
+
+// Test that a CNR held by a conflicting CNR proceeds once the other CNR has
+// finished, and records that it's no longer conflicting.
+func TestPendingConflictResolved(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	other := buildPendingCNR("cli-ng-1", time.Now().Add(-time.Hour), nodegroup[0].Name)
+	other.Status.Phase = v1.CycleNodeRequestWaitingTermination
+	other.Status.NodesToTerminate = []v1.CycleNodeRequestNode{{Name: nodegroup[0].Name}}
+
+	cnr := buildPendingCNR("observer-ng-1-abcde", time.Now())
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(other),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestPending, cnr.Status.Phase)
+	assert.True(t, meta.IsStatusConditionTrue(cnr.Status.Conditions, v1.CycleNodeRequestConditionConflicting))
+
+	// The other CNR finishes cycling the node
+	other.Status.Phase = v1.CycleNodeRequestSuccessful
+	assert.NoError(t, fakeTransitioner.K8sClient.Update(context.TODO(), other))
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+	assert.Len(t, cnr.Status.NodesToTerminate, 2)
+
+	conflicting := meta.FindStatusCondition(cnr.Status.Conditions, v1.CycleNodeRequestConditionConflicting)
+	if assert.NotNil(t, conflicting) {
+		assert.Equal(t, metav1.ConditionFalse, conflicting.Status)
+		assert.Equal(t, v1.CycleNodeRequestReasonNoOverlap, conflicting.Reason)
+	}
+}
+
+// Test that a CNR whose checks read credentials from a Secret the operator
+// hasn't allowed fails before cycling begins.
+func TestUndefinedFailsWithCredentialSecretNotAllowed(t *testing.T) {
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Concurrency: 1,
+				Method:      v1.CycleNodeRequestMethodDrain,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+			HealthChecks: []v1.HealthCheck{{
+				Endpoint:    "http://{{ .NodeIP }}:8080/ready",
+				HTTPRequest: v1.HTTPRequest{BearerTokenSecretRef: &v1.SecretKeyReference{Name: "cloud-credentials", Key: "token"}},
+			}},
+		},
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithTransitionerOptions(Options{HealthCheckCredentialSecrets: []string{"node-agent-auth"}}),
+	)
+
+	_, err := fakeTransitioner.Run()
+	assert.Error(t, err)
+	assert.Equal(t, v1.CycleNodeRequestFailed, cnr.Status.Phase)
+	assert.Contains(t, cnr.Status.Message, "cloud-credentials")
+}
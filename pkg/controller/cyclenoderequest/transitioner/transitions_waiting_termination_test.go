@@ -0,0 +1,388 @@
+package transitioner
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// buildFailureBudgetCNR returns a CNR in the WaitingTermination phase working on the first
+// node, with the second node still available to cycle.
+func buildFailureBudgetCNR(nodegroup []*mock.Node, maxFailedNodes *intstr.IntOrString) *v1.CycleNodeRequest {
+	return &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Method:         v1.CycleNodeRequestMethodDrain,
+				Concurrency:    1,
+				MaxFailedNodes: maxFailedNodes,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase:          v1.CycleNodeRequestWaitingTermination,
+			ActiveChildren: 1,
+			NodesToTerminate: []v1.CycleNodeRequestNode{
+				{Name: nodegroup[0].Name, ProviderID: nodegroup[0].ProviderID, NodeGroupName: "ng-1"},
+				{Name: nodegroup[1].Name, ProviderID: nodegroup[1].ProviderID, NodeGroupName: "ng-1"},
+			},
+			NodesAvailable: []v1.CycleNodeRequestNode{
+				{Name: nodegroup[1].Name, ProviderID: nodegroup[1].ProviderID, NodeGroupName: "ng-1"},
+			},
+		},
+	}
+}
+
+// buildFailedCNS returns a failed CycleNodeStatus for the node owned by cnr-1
+func buildFailedCNS(nodeName string) *v1.CycleNodeStatus {
+	return &v1.CycleNodeStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1-" + nodeName,
+			Namespace: "kube-system",
+			Labels:    map[string]string{"name": "cnr-1"},
+		},
+		Spec: v1.CycleNodeStatusSpec{
+			NodeName: nodeName,
+		},
+		Status: v1.CycleNodeStatusStatus{
+			Phase:   v1.CycleNodeStatusFailed,
+			Message: "cannot evict pod due to disruption budget",
+		},
+	}
+}
+
+// Test that a failed child within the failure budget is skipped, the node is
+// returned to service and the CNR goes back to Initialised to keep cycling.
+func TestWaitingTerminationSkipsFailedNodeWithinBudget(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	maxFailedNodes := intstr.FromString("50%")
+	cnr := buildFailureBudgetCNR(nodegroup, &maxFailedNodes)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildFailedCNS(nodegroup[0].Name)),
+	)
+
+	// The provider IDs are only generated when the fake client is built
+	cnr.Status.NodesToTerminate[0].ProviderID = nodegroup[0].ProviderID
+	cnr.Status.NodesToTerminate[1].ProviderID = nodegroup[1].ProviderID
+	cnr.Status.NodesAvailable[0].ProviderID = nodegroup[1].ProviderID
+
+	require.NoError(t, k8s.CordonNode(nodegroup[0].Name, fakeTransitioner.RawClient))
+	require.NoError(t, k8s.AddLabelToNode(nodegroup[0].Name, cycleNodeLabel, cnr.Name, fakeTransitioner.RawClient))
+
+	// The fake clients don't share nodes, label the node in both
+	var labelledNode corev1.Node
+	require.NoError(t, fakeTransitioner.K8sClient.Get(context.TODO(), types.NamespacedName{Name: nodegroup[0].Name}, &labelledNode))
+	labelledNode.Labels[cycleNodeLabel] = cnr.Name
+	require.NoError(t, fakeTransitioner.K8sClient.Update(context.TODO(), &labelledNode))
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, v1.CycleNodeRequestInitialised, cnr.Status.Phase)
+	assert.Equal(t, int64(0), cnr.Status.ActiveChildren)
+	require.Len(t, cnr.Status.SkippedNodes, 1)
+	assert.Equal(t, nodegroup[0].Name, cnr.Status.SkippedNodes[0].Name)
+	assert.Equal(t, "cannot evict pod due to disruption budget", cnr.Status.SkippedNodes[0].Reason)
+
+	// The failed node should have been uncordoned
+	cordoned, err := k8s.IsCordoned(nodegroup[0].Name, fakeTransitioner.RawClient)
+	require.NoError(t, err)
+	assert.False(t, cordoned)
+
+	// The failed node should no longer be labelled as being cycled
+	kubeNode, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, kubeNode.Labels, cycleNodeLabel)
+
+	// The failed child should have been reaped
+	var cnsList v1.CycleNodeStatusList
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	assert.Empty(t, cnsList.Items)
+}
+
+// Test that a failed child fails the CNR once the failure budget is used up.
+func TestWaitingTerminationHealsWhenBudgetExhausted(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	maxFailedNodes := intstr.FromInt32(1)
+	cnr := buildFailureBudgetCNR(nodegroup, &maxFailedNodes)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildFailedCNS(nodegroup[0].Name)),
+	)
+
+	// The provider IDs are only generated when the fake client is built
+	cnr.Status.NodesToTerminate[0].ProviderID = nodegroup[0].ProviderID
+	cnr.Status.NodesToTerminate[1].ProviderID = nodegroup[1].ProviderID
+	cnr.Status.NodesAvailable[0].ProviderID = nodegroup[1].ProviderID
+
+	// Another node has already been skipped, leaving no budget
+	cnr.Status.SkippedNodes = []v1.CycleNodeRequestSkippedNode{
+		{CycleNodeRequestNode: v1.CycleNodeRequestNode{Name: "ng-1-node-other"}},
+	}
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
+	assert.Len(t, cnr.Status.SkippedNodes, 1)
+}
+
+// Test that without a failure budget the first failed child fails the CNR.
+func TestWaitingTerminationHealsWithoutBudget(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildFailureBudgetCNR(nodegroup, nil)
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(buildFailedCNS(nodegroup[0].Name)),
+	)
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
+	assert.Empty(t, cnr.Status.SkippedNodes)
+}
+
+// Test that a CNR which finishes cycling with skipped nodes ends up
+// PartiallySuccessful and lists the skipped nodes in the message.
+func TestInitialisedPartiallySuccessful(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	maxFailedNodes := intstr.FromInt32(1)
+
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cnr-1",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupsList: []string{"ng-1"},
+			CycleSettings: v1.CycleSettings{
+				Method:         v1.CycleNodeRequestMethodDrain,
+				Concurrency:    1,
+				MaxFailedNodes: &maxFailedNodes,
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"customer": "kitt",
+				},
+			},
+		},
+		Status: v1.CycleNodeRequestStatus{
+			Phase: v1.CycleNodeRequestInitialised,
+		},
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	skippedNode := v1.CycleNodeRequestNode{Name: nodegroup[0].Name, ProviderID: nodegroup[0].ProviderID, NodeGroupName: "ng-1"}
+	cnr.Status.NodesToTerminate = []v1.CycleNodeRequestNode{skippedNode}
+	cnr.Status.SkippedNodes = []v1.CycleNodeRequestSkippedNode{{CycleNodeRequestNode: skippedNode}}
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, v1.CycleNodeRequestPartiallySuccessful, cnr.Status.Phase)
+	assert.Contains(t, cnr.Status.Message, nodegroup[0].Name)
+	assert.Equal(t, 0, cnr.Status.NumNodesCycled)
+	assert.True(t, cnr.IsTerminal())
+}
+
+// Test that a child which failed with a transient error is retried by creating
+// a fresh CycleNodeStatus for the node, and the attempt is recorded.
+func TestWaitingTerminationRetriesRetryableFailure(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildFailureBudgetCNR(nodegroup, nil)
+	cnr.Spec.CycleSettings.RetryPolicy = &v1.RetryPolicy{MaxAttempts: 2}
+
+	cns := buildFailedCNS(nodegroup[0].Name)
+	cns.Status.FailedPhase = v1.CycleNodeStatusTerminatingNode
+	cns.Status.Message = "RequestLimitExceeded: Request limit exceeded."
+	cns.Status.Retryable = true
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(cns),
+	)
+
+	// The provider IDs are only generated when the fake client is built
+	cnr.Status.NodesToTerminate[0].ProviderID = nodegroup[0].ProviderID
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+
+	// The retried node is still in progress so the CNR should keep waiting
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+	assert.Equal(t, int64(1), cnr.Status.ActiveChildren)
+	assert.Empty(t, cnr.Status.SkippedNodes)
+
+	require.Contains(t, cnr.Status.NodeAttempts, nodegroup[0].Name)
+	attemptStatus := cnr.Status.NodeAttempts[nodegroup[0].Name]
+	require.Len(t, attemptStatus.Attempts, 1)
+	assert.Equal(t, v1.CycleNodeStatusTerminatingNode, attemptStatus.Attempts[0].Phase)
+	assert.True(t, attemptStatus.Attempts[0].Retryable)
+	assert.Nil(t, attemptStatus.NextAttempt)
+
+	// A fresh CNS should have been created for the node with the provider ID
+	var cnsList v1.CycleNodeStatusList
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	require.Len(t, cnsList.Items, 1)
+	assert.Equal(t, nodegroup[0].Name, cnsList.Items[0].Spec.NodeName)
+	assert.Equal(t, nodegroup[0].ProviderID, cnsList.Items[0].Spec.ProviderID)
+	assert.Equal(t, v1.CycleNodeStatusUndefined, cnsList.Items[0].Status.Phase)
+}
+
+// Test that a retry waits for the backoff before creating a fresh CycleNodeStatus.
+func TestWaitingTerminationRetryBackoff(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildFailureBudgetCNR(nodegroup, nil)
+	cnr.Spec.CycleSettings.RetryPolicy = &v1.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     &metav1.Duration{Duration: time.Hour},
+	}
+
+	cns := buildFailedCNS(nodegroup[0].Name)
+	cns.Status.Retryable = true
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(cns),
+	)
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+
+	assert.Equal(t, v1.CycleNodeRequestWaitingTermination, cnr.Status.Phase)
+	assert.Equal(t, int64(1), cnr.Status.ActiveChildren)
+
+	attemptStatus := cnr.Status.NodeAttempts[nodegroup[0].Name]
+	require.NotNil(t, attemptStatus.NextAttempt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), attemptStatus.NextAttempt.Time, time.Minute)
+
+	// No CNS should be created until the backoff has passed
+	var cnsList v1.CycleNodeStatusList
+	require.NoError(t, fakeTransitioner.K8sClient.List(context.TODO(), &cnsList))
+	assert.Empty(t, cnsList.Items)
+}
+
+// Test that a node is not retried once the maximum number of attempts is
+// reached, and that non-transient failures are never retried.
+func TestWaitingTerminationRetryExhausted(t *testing.T) {
+	tests := []struct {
+		name             string
+		retryable        bool
+		previousAttempts int
+	}{
+		{"max attempts reached", true, 1},
+		{"not retryable", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodegroup, err := mock.NewNodegroup("ng-1", 2)
+			require.NoError(t, err)
+
+			cnr := buildFailureBudgetCNR(nodegroup, nil)
+			cnr.Spec.CycleSettings.RetryPolicy = &v1.RetryPolicy{MaxAttempts: 2}
+
+			if tt.previousAttempts > 0 {
+				cnr.Status.NodeAttempts = map[string]v1.CycleNodeAttemptStatus{
+					nodegroup[0].Name: {Attempts: make([]v1.CycleNodeAttempt, tt.previousAttempts)},
+				}
+			}
+
+			cns := buildFailedCNS(nodegroup[0].Name)
+			cns.Status.Retryable = tt.retryable
+
+			fakeTransitioner := NewFakeTransitioner(cnr,
+				WithKubeNodes(nodegroup),
+				WithCloudProviderInstances(nodegroup),
+				WithExtraKubeObject(cns),
+			)
+
+			_, err = fakeTransitioner.Run()
+			require.NoError(t, err)
+
+			assert.Equal(t, v1.CycleNodeRequestHealing, cnr.Status.Phase)
+			assert.Len(t, cnr.Status.NodeAttempts[nodegroup[0].Name].Attempts, tt.previousAttempts+1)
+			assert.Nil(t, cnr.Status.NodeAttempts[nodegroup[0].Name].NextAttempt)
+		})
+	}
+}
+
+// Test that the failed attempt isn't recorded if the child can't be reaped, so it
+// isn't counted again when reaping the child is retried.
+func TestRetryFailedNodeDeleteFailure(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cnr := buildFailureBudgetCNR(nodegroup, nil)
+	cnr.Spec.CycleSettings.RetryPolicy = &v1.RetryPolicy{MaxAttempts: 3}
+
+	// The CNS doesn't exist so deleting it fails
+	cns := buildFailedCNS(nodegroup[0].Name)
+	cns.Status.Retryable = true
+
+	fakeTransitioner := NewFakeTransitioner(cnr,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	retry, err := fakeTransitioner.retryFailedNode(cns)
+	assert.Error(t, err)
+	assert.False(t, retry)
+	assert.Empty(t, cnr.Status.NodeAttempts)
+}
+
+// Test that the retry backoff doubles with each attempt and stops growing once capped.
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, retryBackoff(time.Minute, 1))
+	assert.Equal(t, 2*time.Minute, retryBackoff(time.Minute, 2))
+	assert.Equal(t, 4*time.Minute, retryBackoff(time.Minute, 3))
+	assert.Equal(t, 1024*time.Minute, retryBackoff(time.Minute, 11))
+	assert.Equal(t, 1024*time.Minute, retryBackoff(time.Minute, 100))
+	assert.Equal(t, time.Duration(math.MaxInt64), retryBackoff(1000000*time.Hour, 100))
+}
@@ -3,21 +3,26 @@ package transitioner
 import (
 	"context"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
 	"github.com/atlassian-labs/cyclops/pkg/cloudprovider"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
 	"github.com/atlassian-labs/cyclops/pkg/k8s"
 	"github.com/atlassian-labs/cyclops/pkg/metrics"
 )
@@ -83,8 +88,23 @@ func (t *CycleNodeRequestTransitioner) transitionToSuccessful() (reconcile.Resul
 		t.cleanupScaleDownDisabledAnnotations()
 	}
 
-	t.rm.LogEvent(t.cycleNodeRequest, "Successful", "Successfully cycled nodes")
-	t.cycleNodeRequest.Status.Phase = v1.CycleNodeRequestSuccessful
+	// Tainted nodes have either been terminated or had the taint removed when they were skipped
+	t.cycleNodeRequest.Status.TaintedNodes = nil
+
+	if len(t.cycleNodeRequest.Status.SkippedNodes) > 0 {
+		skippedNodeNames := make([]string, 0, len(t.cycleNodeRequest.Status.SkippedNodes))
+		for _, node := range t.cycleNodeRequest.Status.SkippedNodes {
+			skippedNodeNames = append(skippedNodeNames, node.Name)
+		}
+
+		t.cycleNodeRequest.Status.Message = fmt.Sprintf("cycled nodes, skipped %d failed nodes: %s",
+			len(skippedNodeNames), strings.Join(skippedNodeNames, ", "))
+		t.rm.LogWarningEvent(t.cycleNodeRequest, "PartiallySuccessful", "Cycled nodes, skipped failed nodes: %v", skippedNodeNames)
+		t.cycleNodeRequest.Status.Phase = v1.CycleNodeRequestPartiallySuccessful
+	} else {
+		t.rm.LogEvent(t.cycleNodeRequest, "Successful", "Successfully cycled nodes")
+		t.cycleNodeRequest.Status.Phase = v1.CycleNodeRequestSuccessful
+	}
 
 	// Notify that the cycling has succeeded
 	if t.rm.Notifier != nil {
@@ -159,14 +179,32 @@ func (t *CycleNodeRequestTransitioner) reapChildren() (v1.CycleNodeRequestPhase,
 		return nextPhase, err
 	}
 
-	// Check all of the children - if any are failed, the whole CycleNodeRequest fails
+	// Check all of the children - if any are failed beyond the failure budget, the whole CycleNodeRequest fails
 	inProgressCount := 0
 	for _, cycleNodeStatus := range cycleNodeStatusList.Items {
 		switch cycleNodeStatus.Status.Phase {
 		case v1.CycleNodeStatusFailed:
-			nextPhase = v1.CycleNodeRequestHealing
-			t.rm.LogWarningEvent(t.cycleNodeRequest, "ReapChildren", "Failed to cycle node: %v, reason: %v", cycleNodeStatus.Spec.NodeName, cycleNodeStatus.Status.Message)
 			t.rm.Logger.Info("Child has failed", "nodeName", cycleNodeStatus.Name, "status", cycleNodeStatus.Status.Phase, "message", cycleNodeStatus.Status.Message)
+
+			// Nodes which failed due to a transient error are retried before counting against the failure budget
+			retry, err := t.retryFailedNode(&cycleNodeStatus)
+			if err != nil {
+				return nextPhase, err
+			}
+			if retry {
+				t.rm.Logger.Info("Reaped child for retry", "nodeName", cycleNodeStatus.Name, "status", cycleNodeStatus.Status.Phase)
+				continue
+			}
+
+			skipped, err := t.skipFailedNode(&cycleNodeStatus)
+			if err != nil {
+				return nextPhase, err
+			}
+
+			if !skipped {
+				nextPhase = v1.CycleNodeRequestHealing
+				t.rm.LogWarningEvent(t.cycleNodeRequest, "ReapChildren", "Failed to cycle node: %v, reason: %v", cycleNodeStatus.Spec.NodeName, cycleNodeStatus.Status.Message)
+			}
 			fallthrough
 		case v1.CycleNodeStatusSuccessful:
 			// Delete the Failed and Successful children alike
@@ -175,11 +213,30 @@ func (t *CycleNodeRequestTransitioner) reapChildren() (v1.CycleNodeRequestPhase,
 			if err != nil {
 				return nextPhase, err
 			}
+
+			// The attempt is only recorded once the child is gone, otherwise it would be recorded again when
+			// reaping the child is retried
+			if cycleNodeStatus.Status.Phase == v1.CycleNodeStatusFailed {
+				t.recordFailedAttempt(&cycleNodeStatus)
+			}
 		default:
 			inProgressCount++
 		}
 	}
 
+	// Start any retries which have finished backing off. Nodes waiting to be retried count as active
+	// children so they are not forgotten about. Retries are abandoned if the CycleNodeRequest is failing
+	// since the nodes will be returned to service.
+	if nextPhase == v1.CycleNodeRequestHealing || nextPhase == v1.CycleNodeRequestFailed {
+		t.cancelPendingRetries()
+	} else {
+		startedRetries, pendingRetries, err := t.startPendingRetries()
+		if err != nil {
+			return nextPhase, err
+		}
+		inProgressCount += startedRetries + pendingRetries
+	}
+
 	// Update the count of our active children so we can use this to determine how many more nodes
 	// to schedule at a time.
 	if int64(inProgressCount) != t.cycleNodeRequest.Status.ActiveChildren {
@@ -202,6 +259,338 @@ func (t *CycleNodeRequestTransitioner) reapChildren() (v1.CycleNodeRequestPhase,
 	return nextPhase, nil
 }
 
+// maxFailedNodes returns the number of nodes which are allowed to fail cycling before the
+// CycleNodeRequest is failed. Percentages are scaled against the number of nodes to terminate.
+func (t *CycleNodeRequestTransitioner) maxFailedNodes() (int, error) {
+	if t.cycleNodeRequest.Spec.CycleSettings.MaxFailedNodes == nil {
+		return 0, nil
+	}
+
+	return intstr.GetScaledValueFromIntOrPercent(
+		t.cycleNodeRequest.Spec.CycleSettings.MaxFailedNodes,
+		len(t.cycleNodeRequest.Status.NodesToTerminate),
+		false,
+	)
+}
+
+// retryFailedNode retries the node of a failed CycleNodeStatus if the failure was transient and the retry
+// policy allows another attempt. The CycleNodeStatus is deleted before the failed attempt is recorded, so
+// the attempt isn't counted twice if deleting it fails. Returns true if the node will be retried.
+func (t *CycleNodeRequestTransitioner) retryFailedNode(cycleNodeStatus *v1.CycleNodeStatus) (bool, error) {
+	retryPolicy := t.cycleNodeRequest.Spec.CycleSettings.RetryPolicy
+	if retryPolicy == nil {
+		return false, nil
+	}
+
+	nodeName := cycleNodeStatus.Spec.NodeName
+
+	numAttempts := len(t.cycleNodeRequest.Status.NodeAttempts[nodeName].Attempts) + 1
+	retry := cycleNodeStatus.Status.Retryable &&
+		int64(numAttempts) < retryPolicy.MaxAttempts &&
+		t.cycleNodeRequest.Status.Phase != v1.CycleNodeRequestFailed
+
+	if !retry {
+		return false, nil
+	}
+
+	if err := t.rm.Client.Delete(context.TODO(), cycleNodeStatus); err != nil {
+		return false, err
+	}
+
+	var backoff time.Duration
+	if retryPolicy.Backoff != nil {
+		backoff = retryBackoff(retryPolicy.Backoff.Duration, numAttempts)
+	}
+
+	attemptStatus := t.recordFailedAttempt(cycleNodeStatus)
+	nextAttempt := metav1.NewTime(time.Now().Add(backoff))
+	attemptStatus.NextAttempt = &nextAttempt
+	t.cycleNodeRequest.Status.NodeAttempts[nodeName] = attemptStatus
+
+	t.rm.LogWarningEvent(t.cycleNodeRequest, "RetryingNode",
+		"Failed to cycle node: %v, retrying after %v (attempt %d/%d), reason: %v",
+		nodeName, backoff, numAttempts+1, retryPolicy.MaxAttempts, cycleNodeStatus.Status.Message)
+
+	return true, nil
+}
+
+// recordFailedAttempt adds the failed attempt at cycling the node of a failed CycleNodeStatus to the
+// history of attempts for the node, and returns the updated history.
+func (t *CycleNodeRequestTransitioner) recordFailedAttempt(cycleNodeStatus *v1.CycleNodeStatus) v1.CycleNodeAttemptStatus {
+	nodeName := cycleNodeStatus.Spec.NodeName
+
+	if t.cycleNodeRequest.Status.NodeAttempts == nil {
+		t.cycleNodeRequest.Status.NodeAttempts = make(map[string]v1.CycleNodeAttemptStatus)
+	}
+
+	attemptStatus := t.cycleNodeRequest.Status.NodeAttempts[nodeName]
+	attemptStatus.Attempts = append(attemptStatus.Attempts, v1.CycleNodeAttempt{
+		Phase:           cycleNodeStatus.Status.FailedPhase,
+		Message:         cycleNodeStatus.Status.Message,
+		Retryable:       cycleNodeStatus.Status.Retryable,
+		FailedTimestamp: metav1.Now(),
+	})
+
+	t.cycleNodeRequest.Status.NodeAttempts[nodeName] = attemptStatus
+	return attemptStatus
+}
+
+// retryBackoff returns how long to wait before retrying a node which has failed the given number of
+// attempts. The backoff doubles with each attempt after the first retry, up to maxRetryBackoffShift times.
+func retryBackoff(backoff time.Duration, numAttempts int) time.Duration {
+	shift := min(numAttempts-1, maxRetryBackoffShift)
+	if backoff > math.MaxInt64>>shift {
+		return math.MaxInt64
+	}
+	return backoff << shift
+}
+
+// startPendingRetries creates a fresh CycleNodeStatus for each node waiting to be retried which has
+// finished backing off. Returns the number of retries started and the number still waiting.
+func (t *CycleNodeRequestTransitioner) startPendingRetries() (started int, pending int, err error) {
+	for nodeName, attemptStatus := range t.cycleNodeRequest.Status.NodeAttempts {
+		if attemptStatus.NextAttempt == nil {
+			continue
+		}
+
+		if time.Now().Before(attemptStatus.NextAttempt.Time) {
+			pending++
+			continue
+		}
+
+		cycleNodeStatus := t.makeCycleNodeStatusForNode(nodeName)
+
+		// Pass through the provider ID in case the node has already been removed from Kube
+		for _, node := range t.cycleNodeRequest.Status.NodesToTerminate {
+			if node.Name == nodeName {
+				cycleNodeStatus.Spec.ProviderID = node.ProviderID
+				break
+			}
+		}
+
+		if err := t.rm.Client.Create(context.TODO(), cycleNodeStatus); err != nil {
+			// The previous CycleNodeStatus may still be in the process of being deleted
+			if apierrors.IsAlreadyExists(err) {
+				pending++
+				continue
+			}
+
+			return started, pending, err
+		}
+
+		t.rm.LogEvent(t.cycleNodeRequest, "RetryingNode", "Retrying cycling node: %v", nodeName)
+
+		attemptStatus.NextAttempt = nil
+		t.cycleNodeRequest.Status.NodeAttempts[nodeName] = attemptStatus
+		started++
+	}
+
+	return started, pending, nil
+}
+
+// cancelPendingRetries stops any nodes waiting to be retried from being retried.
+func (t *CycleNodeRequestTransitioner) cancelPendingRetries() {
+	for nodeName, attemptStatus := range t.cycleNodeRequest.Status.NodeAttempts {
+		if attemptStatus.NextAttempt == nil {
+			continue
+		}
+
+		t.rm.Logger.Info("Cancelling retry of node", "nodeName", nodeName)
+		attemptStatus.NextAttempt = nil
+		t.cycleNodeRequest.Status.NodeAttempts[nodeName] = attemptStatus
+	}
+}
+
+// isRetryingNode returns true if the node is waiting to be retried by this CycleNodeRequest.
+func (t *CycleNodeRequestTransitioner) isRetryingNode(nodeName string) bool {
+	attemptStatus, ok := t.cycleNodeRequest.Status.NodeAttempts[nodeName]
+	return ok && attemptStatus.NextAttempt != nil
+}
+
+// isSkippedNode returns true if the node failed to cycle and has been skipped by this CycleNodeRequest.
+func (t *CycleNodeRequestTransitioner) isSkippedNode(nodeName string) bool {
+	for _, node := range t.cycleNodeRequest.Status.SkippedNodes {
+		if node.Name == nodeName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skipFailedNode returns the node of a failed CycleNodeStatus to service and records it as skipped
+// if the failure budget of the CycleNodeRequest allows it. Returns false if the node was not skipped
+// and the CycleNodeRequest should fail.
+func (t *CycleNodeRequestTransitioner) skipFailedNode(cycleNodeStatus *v1.CycleNodeStatus) (bool, error) {
+	nodeName := cycleNodeStatus.Spec.NodeName
+
+	// The node may have been skipped in a previous reconcile which failed to reap the child
+	if t.isSkippedNode(nodeName) {
+		return true, nil
+	}
+
+	maxFailedNodes, err := t.maxFailedNodes()
+	if err != nil {
+		return false, err
+	}
+
+	if len(t.cycleNodeRequest.Status.SkippedNodes) >= maxFailedNodes {
+		return false, nil
+	}
+
+	var failedNode *v1.CycleNodeRequestNode
+
+	for i, node := range t.cycleNodeRequest.Status.NodesToTerminate {
+		if node.Name == nodeName {
+			failedNode = &t.cycleNodeRequest.Status.NodesToTerminate[i]
+			break
+		}
+	}
+
+	// Without the provider details of the node it can't be returned to service
+	if failedNode == nil {
+		return false, nil
+	}
+
+	nodeGroups, err := t.rm.CloudProvider.GetNodeGroups(t.cycleNodeRequest.GetNodeGroupNames())
+	if err != nil {
+		return false, err
+	}
+
+	if err := t.healNode(nodeGroups, *failedNode); err != nil {
+		return false, err
+	}
+
+	// The node is back in service, it's kept out of this request by being recorded as skipped
+	if err := k8s.RemoveLabelFromNode(nodeName, cycleNodeLabel, t.rm.RawClient); err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	t.cycleNodeRequest.Status.SkippedNodes = append(t.cycleNodeRequest.Status.SkippedNodes, v1.CycleNodeRequestSkippedNode{
+		CycleNodeRequestNode: *failedNode,
+		Reason:               cycleNodeStatus.Status.Message,
+	})
+
+	t.rm.LogWarningEvent(t.cycleNodeRequest, "SkippedNode",
+		"Failed to cycle node: %v, returned it to service and skipped it (%d/%d failures allowed), reason: %v",
+		nodeName, len(t.cycleNodeRequest.Status.SkippedNodes), maxFailedNodes, cycleNodeStatus.Status.Message)
+
+	return true, nil
+}
+
+// healNode returns a node to service by removing the cycling finalizer, re-attaching the instance to
+// its cloud provider node group and uncordoning it. Nodes which no longer exist are skipped.
+func (t *CycleNodeRequestTransitioner) healNode(nodeGroups cloudprovider.NodeGroups, node v1.CycleNodeRequestNode) error {
+	// nodes in NodesToTerminate may have been terminated, so check if they still exist
+	nodeExists, err := k8s.NodeExists(node.Name, t.rm.RawClient)
+	if err != nil {
+		return err
+	}
+
+	if !nodeExists {
+		t.rm.LogEvent(t.cycleNodeRequest,
+			"HealingNodes", "Node does not exist, skip healing node: %s", node.Name)
+		t.untrackDrainTaint(node.Name)
+		return nil
+	}
+
+	if err := t.rm.RemoveFinalizerFromNode(node.Name); err != nil {
+		t.rm.LogEvent(t.cycleNodeRequest, "RemoveFinalizerFromNodeError", err.Error())
+		return err
+	}
+
+	// try and re-attach the nodes, if any were un-attached
+	t.rm.LogEvent(t.cycleNodeRequest, "AttachingNodes", "Attaching instances to nodes group: %v", node.Name)
+	// if the node is already attached, ignore the error and continue to un-cordoning, otherwise return with error
+	alreadyAttached, err := nodeGroups.AttachInstance(node.ProviderID, node.NodeGroupName)
+	if err != nil && !alreadyAttached {
+		return err
+	}
+	if alreadyAttached {
+		t.rm.LogEvent(t.cycleNodeRequest,
+			"AttachingNodes", "Skip re-attaching instances to nodes group: %v, err: %v",
+			node.Name, err)
+	}
+
+	// remove the drain taint before un-cordoning so pods are not scheduled onto the node while it is still tainted
+	if err := t.removeDrainTaint(node.Name); err != nil {
+		return err
+	}
+
+	// add the node back to load balancers if it was excluded from them before being deleted
+	if err := k8s.IncludeNodeInLoadBalancers(node.Name, t.rm.RawClient); err != nil {
+		return err
+	}
+
+	// un-cordon after attach as well
+	t.rm.LogEvent(t.cycleNodeRequest, "UncordoningNodes", "Uncordoning nodes in node group: %v", node.Name)
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return k8s.UncordonNode(node.Name, t.rm.RawClient)
+	})
+
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// addDrainTaint adds the drainTaint from the cycle settings to the node and tracks the node so the taint can be
+// removed if it is returned to service. Nodes which have already been tainted are skipped. The node is always
+// tainted NoSchedule here, a NoExecute taint is added by the CycleNodeStatus once the node has been drained so
+// the pods are evicted by the drain rather than straight away.
+func (t *CycleNodeRequestTransitioner) addDrainTaint(nodeName string) error {
+	drainTaint := t.cycleNodeRequest.Spec.CycleSettings.DrainTaint
+	if drainTaint == nil || slices.Contains(t.cycleNodeRequest.Status.TaintedNodes, nodeName) {
+		return nil
+	}
+
+	t.rm.LogEvent(t.cycleNodeRequest, "TaintingNode", "Adding taint %s:%s to node %s", drainTaint.Key, corev1.TaintEffectNoSchedule, nodeName)
+
+	err := k8s.AddTaintToNode(nodeName, corev1.Taint{
+		Key:    drainTaint.Key,
+		Value:  drainTaint.Value,
+		Effect: corev1.TaintEffectNoSchedule,
+	}, t.rm.RawClient)
+	if err != nil {
+		return err
+	}
+
+	t.cycleNodeRequest.Status.TaintedNodes = append(t.cycleNodeRequest.Status.TaintedNodes, nodeName)
+	return nil
+}
+
+// removeDrainTaint removes the drainTaint from a node which Cyclops tainted, including the NoExecute taint the
+// CycleNodeStatus adds after draining the node
+func (t *CycleNodeRequestTransitioner) removeDrainTaint(nodeName string) error {
+	drainTaint := t.cycleNodeRequest.Spec.CycleSettings.DrainTaint
+	if drainTaint == nil || !slices.Contains(t.cycleNodeRequest.Status.TaintedNodes, nodeName) {
+		return nil
+	}
+
+	t.rm.LogEvent(t.cycleNodeRequest, "UntaintingNode", "Removing taint %s:%s from node %s", drainTaint.Key, drainTaint.Effect, nodeName)
+
+	if err := k8s.RemoveTaintFromNode(nodeName, drainTaint.Key, corev1.TaintEffectNoSchedule, t.rm.RawClient); err != nil {
+		return err
+	}
+	if corev1.TaintEffect(drainTaint.Effect) == corev1.TaintEffectNoExecute {
+		if err := k8s.RemoveTaintFromNode(nodeName, drainTaint.Key, corev1.TaintEffectNoExecute, t.rm.RawClient); err != nil {
+			return err
+		}
+	}
+
+	t.untrackDrainTaint(nodeName)
+	return nil
+}
+
+// untrackDrainTaint stops tracking the drainTaint on the node
+func (t *CycleNodeRequestTransitioner) untrackDrainTaint(nodeName string) {
+	t.cycleNodeRequest.Status.TaintedNodes = slices.DeleteFunc(t.cycleNodeRequest.Status.TaintedNodes, func(name string) bool {
+		return name == nodeName
+	})
+}
+
 // finalReapChildren handles reaping of children where instead of going back to Initialised,
 // we need to end the cycle for this CycleNodeRequest.
 func (t *CycleNodeRequestTransitioner) finalReapChildren() (shouldRequeue bool, err error) {
@@ -838,3 +1227,290 @@ func (t *CycleNodeRequestTransitioner) cleanupScaleDownDisabledAnnotations() {
 	// no-ops — preventing old Successful CNRs from interfering with active ones.
 	t.cycleNodeRequest.Status.AnnotatedNodes = failedNodes
 }
+
+// resumeFromFailed moves a Failed CycleNodeRequest back to Pending so that the nodes which
+// have not been cycled yet are picked up again. The nodes to terminate, the number of nodes
+// cycled, skipped nodes and attempts are preserved. Everything tracking the batch in progress
+// at the time of failure is reset.
+func (t *CycleNodeRequestTransitioner) resumeFromFailed() (reconcile.Result, error) {
+	t.rm.LogEvent(t.cycleNodeRequest, "Resuming",
+		"Resuming cycleNodeRequest after failure: %s", t.cycleNodeRequest.Status.Message)
+
+	// Allow the remaining nodes to be selected again. Nodes which have been skipped already had
+	// the label removed and aren't picked up by this request.
+	for _, node := range t.cycleNodeRequest.Status.NodesToTerminate {
+		if t.isSkippedNode(node.Name) {
+			continue
+		}
+
+		if err := k8s.RemoveLabelFromNode(node.Name, cycleNodeLabel, t.rm.RawClient); err != nil && !apierrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+	}
+
+	delete(t.cycleNodeRequest.Annotations, v1.CycleNodeRequestRetryAnnotation)
+
+	t.cycleNodeRequest.Status.Message = ""
+	t.cycleNodeRequest.Status.CurrentNodes = nil
+	t.cycleNodeRequest.Status.NodesAvailable = nil
+	t.cycleNodeRequest.Status.ScaleUpStarted = nil
+	t.cycleNodeRequest.Status.EquilibriumWaitStarted = nil
+	t.cycleNodeRequest.Status.CapacityWaitStarted = nil
+	t.cycleNodeRequest.Status.ClusterHealthCheckStarted = nil
+	t.cycleNodeRequest.Status.ActiveChildren = 0
+	t.cycleNodeRequest.Status.HealthChecks = nil
+	t.cycleNodeRequest.Status.PreTerminationChecks = nil
+
+	return t.transitionObject(v1.CycleNodeRequestPending)
+}
+
+// setResumedNodesAvailable makes the nodes to terminate which still exist in the node group
+// available for cycling again. Nodes which no longer exist have already been cycled.
+func (t *CycleNodeRequestTransitioner) setResumedNodesAvailable(validKubeNodes map[string]corev1.Node) {
+	t.cycleNodeRequest.Status.NodesAvailable = nil
+
+	for _, node := range t.cycleNodeRequest.Status.NodesToTerminate {
+		if _, ok := validKubeNodes[node.ProviderID]; !ok {
+			continue
+		}
+
+		if t.isSkippedNode(node.Name) {
+			continue
+		}
+
+		t.cycleNodeRequest.Status.NodesAvailable = append(t.cycleNodeRequest.Status.NodesAvailable, node)
+	}
+}
+
+// countGlobalNodesInProgress counts the nodes being cycled by all CycleNodeRequests in the namespace.
+// This is every CycleNodeStatus which hasn't finished, plus the nodes other CycleNodeRequests have
+// selected but not yet handed off to a CycleNodeStatus, plus the nodes any CycleNodeRequest is
+// waiting to retry.
+func (t *CycleNodeRequestTransitioner) countGlobalNodesInProgress() (int64, error) {
+	listOptions := &client.ListOptions{Namespace: t.cycleNodeRequest.Namespace}
+
+	var cycleNodeStatusList v1.CycleNodeStatusList
+	if err := t.rm.Client.List(context.TODO(), &cycleNodeStatusList, listOptions); err != nil {
+		return 0, err
+	}
+
+	var count int64
+
+	for _, cns := range cycleNodeStatusList.Items {
+		switch cns.Status.Phase {
+		case v1.CycleNodeStatusSuccessful, v1.CycleNodeStatusFailed:
+			continue
+		default:
+			count++
+		}
+	}
+
+	var cycleNodeRequestList v1.CycleNodeRequestList
+	if err := t.rm.Client.List(context.TODO(), &cycleNodeRequestList, listOptions); err != nil {
+		return 0, err
+	}
+
+	for _, cnr := range cycleNodeRequestList.Items {
+		if cnr.Name == t.cycleNodeRequest.Name {
+			continue
+		}
+
+		switch cnr.Status.Phase {
+		case v1.CycleNodeRequestScalingUp, v1.CycleNodeRequestCordoningNode:
+			count += int64(len(cnr.Status.CurrentNodes))
+		}
+
+		count += countPendingRetries(&cnr)
+	}
+
+	// The failed CycleNodeStatus of a node waiting to be retried isn't counted above, but the node will be
+	// cycled again once it has finished backing off
+	count += countPendingRetries(t.cycleNodeRequest)
+
+	return count, nil
+}
+
+// countPendingRetries counts the nodes the CycleNodeRequest is waiting to retry
+func countPendingRetries(cnr *v1.CycleNodeRequest) int64 {
+	var count int64
+
+	for _, attemptStatus := range cnr.Status.NodeAttempts {
+		if attemptStatus.NextAttempt != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// holdIfConflicting returns true if the CycleNodeRequest has to wait in the Pending phase for other
+// CycleNodeRequests cycling some of the same nodes to finish. The Conflicting condition records why it's
+// waiting, and is cleared once it's no longer held.
+func (t *CycleNodeRequestTransitioner) holdIfConflicting() (bool, error) {
+	conflicts, err := controller.FindConflictingCycleNodeRequests(context.TODO(), t.rm.Client, t.cycleNodeRequest)
+	if err != nil {
+		return false, err
+	}
+
+	var blocking []string
+	for _, conflict := range conflicts {
+		if conflict.GoesFirst(t.cycleNodeRequest) {
+			blocking = append(blocking, conflict.String())
+		}
+	}
+
+	if len(blocking) == 0 {
+		if meta.IsStatusConditionTrue(t.cycleNodeRequest.Status.Conditions, v1.CycleNodeRequestConditionConflicting) {
+			meta.SetStatusCondition(&t.cycleNodeRequest.Status.Conditions, metav1.Condition{
+				Type:    v1.CycleNodeRequestConditionConflicting,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.CycleNodeRequestReasonNoOverlap,
+				Message: "No other CycleNodeRequests are cycling the same nodes",
+			})
+		}
+
+		return false, nil
+	}
+
+	message := fmt.Sprintf("Waiting for CycleNodeRequests cycling the same nodes to finish: %s", strings.Join(blocking, "; "))
+	t.rm.LogWarningEvent(t.cycleNodeRequest, "WaitingConflictingRequest", "%s", message)
+	t.rm.Logger.Info("Holding request while other requests are cycling the same nodes", "conflicts", blocking)
+
+	meta.SetStatusCondition(&t.cycleNodeRequest.Status.Conditions, metav1.Condition{
+		Type:    v1.CycleNodeRequestConditionConflicting,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1.CycleNodeRequestReasonOverlappingRequest,
+		Message: message,
+	})
+
+	// The node state isn't checked while waiting, so the equilibrium timer starts over once the request proceeds
+	t.cycleNodeRequest.Status.EquilibriumWaitStarted = nil
+
+	return true, t.rm.UpdateObject(t.cycleNodeRequest)
+}
+
+// handOffStarted returns true if any of the current nodes have already been labelled as being worked
+// on by the CycleNodeRequest. Nodes which are only cordoned may have been cordoned by something else.
+func (t *CycleNodeRequestTransitioner) handOffStarted() (bool, error) {
+	for _, node := range t.cycleNodeRequest.Status.CurrentNodes {
+		kubeNode, err := t.rm.RawClient.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if kubeNode.Labels[cycleNodeLabel] == t.cycleNodeRequest.Name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// checkDrainCapacity checks the remaining schedulable nodes have enough capacity for the pods on the
+// current nodes. Starts the capacity wait timer if there isn't enough capacity, and errors if the timer
+// has been exceeded. The capacity is only checked before the first node of the batch is handed off to its
+// CycleNodeStatus, once the batch is in progress the pods being drained can't be scheduled back onto it.
+func (t *CycleNodeRequestTransitioner) checkDrainCapacity() (bool, error) {
+	started, err := t.handOffStarted()
+	if err != nil {
+		return false, err
+	}
+
+	if started {
+		return true, nil
+	}
+
+	var nodeNames []string
+	for _, node := range t.cycleNodeRequest.Status.CurrentNodes {
+		nodeNames = append(nodeNames, node.Name)
+	}
+
+	hasCapacity, reason, err := t.rm.CheckDrainCapacity(nodeNames)
+	if err != nil {
+		return false, err
+	}
+
+	if hasCapacity {
+		t.cycleNodeRequest.Status.CapacityWaitStarted = nil
+		return true, nil
+	}
+
+	if t.cycleNodeRequest.Status.CapacityWaitStarted == nil {
+		now := metav1.Now()
+		t.cycleNodeRequest.Status.CapacityWaitStarted = &now
+	}
+
+	if time.Since(t.cycleNodeRequest.Status.CapacityWaitStarted.Time) > t.options.CapacityWaitLimit {
+		return false, fmt.Errorf("timed out after %s waiting for cluster capacity: %s", t.options.CapacityWaitLimit, reason)
+	}
+
+	t.rm.LogEvent(t.cycleNodeRequest, "WaitingCapacity", "Waiting for cluster capacity before cordoning nodes: %s", reason)
+	return false, nil
+}
+
+// checkBlockingPDBs reports the PodDisruptionBudgets which can never allow the pods on the nodes available
+// for cycling to be evicted. Only errors if the CycleNodeRequest is configured to fail on them.
+func (t *CycleNodeRequestTransitioner) checkBlockingPDBs() error {
+	failOnBlockingPDBs := t.cycleNodeRequest.Spec.ValidationOptions.FailOnBlockingPodDisruptionBudgets
+
+	var nodeNames []string
+	for _, node := range t.cycleNodeRequest.Status.NodesAvailable {
+		nodeNames = append(nodeNames, node.Name)
+	}
+
+	blockingPDBs, err := k8s.FindBlockingPDBsForNodes(t.rm.Client, nodeNames)
+	if err != nil {
+		if failOnBlockingPDBs {
+			return err
+		}
+
+		t.rm.Logger.Error(err, "unable to check for blocking PodDisruptionBudgets")
+		return nil
+	}
+
+	if len(blockingPDBs) == 0 {
+		if meta.IsStatusConditionTrue(t.cycleNodeRequest.Status.Conditions, v1.CycleNodeRequestConditionBlockingPodDisruptionBudgets) {
+			meta.SetStatusCondition(&t.cycleNodeRequest.Status.Conditions, metav1.Condition{
+				Type:    v1.CycleNodeRequestConditionBlockingPodDisruptionBudgets,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1.CycleNodeRequestReasonEvictionAllowed,
+				Message: "No PodDisruptionBudgets will stop the nodes from being drained",
+			})
+		}
+
+		return nil
+	}
+
+	var descriptions []string
+	for _, pdb := range blockingPDBs {
+		descriptions = append(descriptions, pdb.String())
+	}
+	message := fmt.Sprintf("found PodDisruptionBudgets which will never allow the nodes to be drained: %s",
+		strings.Join(descriptions, "; "))
+
+	// The PodDisruptionBudgets are only reported when they change, the condition keeps track of those
+	// already reported if the Pending phase is run again
+	condition := meta.FindStatusCondition(t.cycleNodeRequest.Status.Conditions, v1.CycleNodeRequestConditionBlockingPodDisruptionBudgets)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Message != message {
+		for _, pdb := range blockingPDBs {
+			t.rm.LogWarningEvent(t.cycleNodeRequest, "BlockingPodDisruptionBudget",
+				"PodDisruptionBudget %s will never allow pods to be evicted", pdb)
+		}
+	}
+
+	meta.SetStatusCondition(&t.cycleNodeRequest.Status.Conditions, metav1.Condition{
+		Type:    v1.CycleNodeRequestConditionBlockingPodDisruptionBudgets,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1.CycleNodeRequestReasonEvictionNeverAllowed,
+		Message: message,
+	})
+
+	if failOnBlockingPDBs {
+		return errors.New(message)
+	}
+
+	return nil
+}
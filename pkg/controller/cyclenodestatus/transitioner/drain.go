@@ -0,0 +1,208 @@
+package transitioner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	"github.com/atlassian-labs/cyclops/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// forceDeletedDrainBlocked is the reason for deleting a pod which refused eviction for longer than the
+// drainBlockedTimeout
+const forceDeletedDrainBlocked = "DrainBlockedTimeout"
+
+// recordBlockedPods stores the pods refusing eviction in the status of the CycleNodeStatus, along with the
+// PodDisruptionBudgets covering them and how long they have been blocking the drain. Pods which have just
+// started blocking the drain are reported as events on their owner. Returns whether the status was changed.
+func (t *CycleNodeStatusTransitioner) recordBlockedPods(blocked []corev1.Pod, now time.Time) bool {
+	if len(blocked) == 0 && len(t.cycleNodeStatus.Status.BlockedPods) == 0 {
+		return false
+	}
+
+	var pdbs []policyv1.PodDisruptionBudget
+	if len(blocked) > 0 {
+		var err error
+		// The PodDisruptionBudgets are only for reporting, so don't stop the drain if they can't be listed
+		if pdbs, err = t.rm.ListPodDisruptionBudgets(); err != nil {
+			t.rm.Logger.Error(err, "unable to list PodDisruptionBudgets for blocked pods")
+		}
+	}
+
+	blockedPods, newlyBlocked := updateBlockedPods(t.cycleNodeStatus.Status.BlockedPods, blocked, pdbs, now)
+	t.cycleNodeStatus.Status.BlockedPods = blockedPods
+
+	for _, pod := range newlyBlocked {
+		t.rm.Logger.Info("pod is refusing eviction", "podName", pod.Name, "podNamespace", pod.Namespace)
+		t.rm.LogWarningEvent(controller.PodOwner(&pod), "EvictionBlocked",
+			"Pod %s is refusing eviction from node %s", pod.Name, t.cycleNodeStatus.Status.CurrentNode.Name)
+	}
+
+	if len(blockedPods) > 0 {
+		t.cycleNodeStatus.Status.Message = blockedPodsMessage(blockedPods)
+	}
+	return true
+}
+
+// updateBlockedPods returns the pods refusing eviction, keeping when each pod first started blocking the drain
+// from the previous attempts. Also returns the pods which were not blocking the previous attempt.
+func updateBlockedPods(previous []v1.BlockedPod, blocked []corev1.Pod, pdbs []policyv1.PodDisruptionBudget, now time.Time) (blockedPods []v1.BlockedPod, newlyBlocked []corev1.Pod) {
+	blockedSince := make(map[types.NamespacedName]metav1.Time, len(previous))
+	for _, pod := range previous {
+		blockedSince[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = pod.BlockedSince
+	}
+
+	for _, pod := range blocked {
+		since, ok := blockedSince[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+		if !ok {
+			since = metav1.NewTime(now)
+			newlyBlocked = append(newlyBlocked, pod)
+		}
+
+		blockedPods = append(blockedPods, v1.BlockedPod{
+			Name:                 pod.Name,
+			Namespace:            pod.Namespace,
+			PodDisruptionBudgets: k8s.PodDisruptionBudgetsForPod(&pod, pdbs),
+			BlockedSince:         since,
+			BlockedFor:           metav1.Duration{Duration: now.Sub(since.Time)},
+		})
+	}
+
+	return blockedPods, newlyBlocked
+}
+
+// blockedPodsMessage describes the pods blocking the drain for the status message
+func blockedPodsMessage(blockedPods []v1.BlockedPod) string {
+	var pods []string
+	for _, pod := range blockedPods {
+		description := fmt.Sprintf("%s/%s for %s", pod.Namespace, pod.Name, pod.BlockedFor.Duration)
+		if len(pod.PodDisruptionBudgets) > 0 {
+			description = fmt.Sprintf("%s by PodDisruptionBudget %s", description, strings.Join(pod.PodDisruptionBudgets, ", "))
+		}
+		pods = append(pods, description)
+	}
+	return fmt.Sprintf("pods are refusing eviction: %s", strings.Join(pods, "; "))
+}
+
+// forceEvictBlockedPods deletes the pods which have been refusing eviction for longer than the drainBlockedTimeout
+// of the CycleNodeStatus, bypassing their PodDisruptionBudgets. Nothing is deleted if the unhealthy pod policy never
+// allows pods to be forcibly deleted. Returns the pods which were deleted.
+func (t *CycleNodeStatusTransitioner) forceEvictBlockedPods(blocked []corev1.Pod) (deleted []controller.ForceDeletedPod, err error) {
+	timeout := t.cycleNodeStatus.Spec.CycleSettings.DrainBlockedTimeout
+	if timeout == nil || timeout.Duration <= 0 || controller.ForceDeletionDisabled(t.cycleNodeStatus.Spec.CycleSettings.UnhealthyPodPolicy) {
+		return nil, nil
+	}
+
+	blockedFor := make(map[types.NamespacedName]time.Duration, len(t.cycleNodeStatus.Status.BlockedPods))
+	for _, pod := range t.cycleNodeStatus.Status.BlockedPods {
+		blockedFor[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = pod.BlockedFor.Duration
+	}
+
+	for _, pod := range blocked {
+		duration := blockedFor[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+		if duration < timeout.Duration {
+			continue
+		}
+
+		t.rm.LogWarningEvent(t.cycleNodeStatus, "ForceEvictingPod",
+			"Deleting pod %s/%s which has refused eviction for %s", pod.Namespace, pod.Name, duration)
+		t.rm.LogWarningEvent(controller.PodOwner(&pod), "ForceEvictingPod",
+			"Deleting pod %s which has refused eviction from node %s for %s", pod.Name, pod.Spec.NodeName, duration)
+		if err := k8s.DeletePod(&pod, t.rm.RawClient); err != nil && !apierrors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted = append(deleted, controller.ForceDeletedPod{
+			Pod:     pod,
+			Reason:  forceDeletedDrainBlocked,
+			Message: fmt.Sprintf("pod refused eviction for %s", duration),
+		})
+	}
+
+	return deleted, nil
+}
+
+// recordForceDeletedPods stores the pods which were forcibly deleted from the node in the status of the
+// CycleNodeStatus, and reports them as events on the CycleNodeStatus and the owner of each pod. Returns whether
+// the status was changed.
+func (t *CycleNodeStatusTransitioner) recordForceDeletedPods(forced []controller.ForceDeletedPod, now time.Time) bool {
+	for _, deleted := range forced {
+		pod := deleted.Pod
+		t.rm.Logger.Info("pod was forcibly deleted", "podName", pod.Name, "podNamespace", pod.Namespace,
+			"reason", deleted.Reason)
+		t.rm.LogWarningEvent(t.cycleNodeStatus, "ForceDeletedPod",
+			"Forcibly deleted pod %s/%s: %s", pod.Namespace, pod.Name, deleted.Message)
+		t.rm.LogWarningEvent(controller.PodOwner(&pod), "ForceDeletedPod",
+			"Forcibly deleted pod %s from node %s: %s", pod.Name, t.cycleNodeStatus.Status.CurrentNode.Name, deleted.Message)
+		metrics.PodsForceDeleted.WithLabelValues(pod.Namespace, deleted.Reason).Inc()
+
+		t.cycleNodeStatus.Status.ForceDeletedPods = append(t.cycleNodeStatus.Status.ForceDeletedPods, v1.ForceDeletedPod{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Reason:    deleted.Reason,
+			Message:   deleted.Message,
+			DeletedAt: metav1.NewTime(now),
+		})
+	}
+
+	return len(forced) > 0
+}
+
+// notifyDrainBlocked sends a notification about the pods blocking the drain once any of them have been refusing
+// eviction for longer than the DrainBlockedNotifyThreshold. Only one notification is sent per CycleNodeStatus.
+func (t *CycleNodeStatusTransitioner) notifyDrainBlocked() {
+	if t.rm.Notifier == nil || t.options.DrainBlockedNotifyThreshold <= 0 || t.cycleNodeStatus.Status.DrainBlockedNotified {
+		return
+	}
+
+	var exceeded bool
+	for _, pod := range t.cycleNodeStatus.Status.BlockedPods {
+		if pod.BlockedFor.Duration >= t.options.DrainBlockedNotifyThreshold {
+			exceeded = true
+			break
+		}
+	}
+
+	if !exceeded {
+		return
+	}
+
+	cnr, err := t.getOwnerCycleNodeRequest()
+	if err != nil {
+		t.rm.Logger.Error(err, "unable to get CycleNodeRequest to notify about blocked pods")
+		return
+	}
+
+	if err := t.rm.Notifier.DrainBlocked(cnr, t.cycleNodeStatus); err != nil {
+		t.rm.Logger.Error(err, "Failed to post blocked pods notification")
+		return
+	}
+
+	t.cycleNodeStatus.Status.DrainBlockedNotified = true
+}
+
+// getOwnerCycleNodeRequest gets the CycleNodeRequest which created the CycleNodeStatus
+func (t *CycleNodeStatusTransitioner) getOwnerCycleNodeRequest() (*v1.CycleNodeRequest, error) {
+	for _, owner := range t.cycleNodeStatus.OwnerReferences {
+		if owner.Kind != "CycleNodeRequest" {
+			continue
+		}
+
+		var cnr v1.CycleNodeRequest
+		key := types.NamespacedName{Namespace: t.cycleNodeStatus.Namespace, Name: owner.Name}
+		if err := t.rm.Client.Get(context.TODO(), key, &cnr); err != nil {
+			return nil, err
+		}
+		return &cnr, nil
+	}
+
+	return nil, fmt.Errorf("cycleNodeStatus %s is not owned by a CycleNodeRequest", t.cycleNodeStatus.Name)
+}
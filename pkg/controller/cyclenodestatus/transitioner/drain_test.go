@@ -0,0 +1,193 @@
+package transitioner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/metrics"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateBlockedPods(t *testing.T) {
+	now := time.Unix(960579585, 0)
+	firstBlocked := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	pdbs := []policyv1.PodDisruptionBudget{{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}}
+
+	previous := []v1.BlockedPod{
+		{Name: "web-1", Namespace: "default", BlockedSince: firstBlocked},
+		{Name: "evicted", Namespace: "default", BlockedSince: firstBlocked},
+	}
+
+	blocked := []corev1.Pod{
+		pod("web-1", "default", "app=web"),
+		pod("web-2", "default", "app=web"),
+		pod("api-1", "default", "app=api"),
+	}
+
+	blockedPods, newlyBlocked := updateBlockedPods(previous, blocked, pdbs, now)
+
+	require.Len(t, blockedPods, 3)
+	assert.Equal(t, v1.BlockedPod{
+		Name:                 "web-1",
+		Namespace:            "default",
+		PodDisruptionBudgets: []string{"web"},
+		BlockedSince:         firstBlocked,
+		BlockedFor:           metav1.Duration{Duration: 10 * time.Minute},
+	}, blockedPods[0])
+	assert.Equal(t, v1.BlockedPod{
+		Name:                 "web-2",
+		Namespace:            "default",
+		PodDisruptionBudgets: []string{"web"},
+		BlockedSince:         metav1.NewTime(now),
+	}, blockedPods[1])
+	assert.Empty(t, blockedPods[2].PodDisruptionBudgets)
+
+	// Pods which were not blocking the previous drain attempt are reported as newly blocked, and pods which
+	// were evicted are dropped
+	require.Len(t, newlyBlocked, 2)
+	assert.Equal(t, "web-2", newlyBlocked[0].Name)
+	assert.Equal(t, "api-1", newlyBlocked[1].Name)
+
+	blockedPods, newlyBlocked = updateBlockedPods(blockedPods, nil, pdbs, now)
+	assert.Empty(t, blockedPods)
+	assert.Empty(t, newlyBlocked)
+}
+
+func TestBlockedPodsMessage(t *testing.T) {
+	message := blockedPodsMessage([]v1.BlockedPod{
+		{Name: "web-1", Namespace: "default", PodDisruptionBudgets: []string{"web"}, BlockedFor: metav1.Duration{Duration: 5 * time.Minute}},
+		{Name: "api-1", Namespace: "default", BlockedFor: metav1.Duration{Duration: time.Minute}},
+	})
+
+	assert.Equal(t, "pods are refusing eviction: default/web-1 for 5m0s by PodDisruptionBudget web; default/api-1 for 1m0s", message)
+}
+
+// Test that forcibly deleted pods are recorded in the status of the CycleNodeStatus and counted
+func TestRecordForceDeletedPods(t *testing.T) {
+	now := time.Unix(960579585, 0)
+	cns := newDrainCNS("ng-1-node-0", v1.CycleNodeStatusDrainingPods)
+	fakeTransitioner := NewFakeTransitioner(cns)
+
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "record-force-deleted"}}
+	before := testutil.ToFloat64(metrics.PodsForceDeleted.WithLabelValues(pod.Namespace, controller.ForceDeletedUnhealthy))
+
+	assert.False(t, fakeTransitioner.recordForceDeletedPods(nil, now))
+	assert.Empty(t, cns.Status.ForceDeletedPods)
+
+	changed := fakeTransitioner.recordForceDeletedPods([]controller.ForceDeletedPod{{
+		Pod:     pod,
+		Reason:  controller.ForceDeletedUnhealthy,
+		Message: "pod refused eviction while unhealthy",
+	}}, now)
+	assert.True(t, changed)
+
+	assert.Equal(t, []v1.ForceDeletedPod{{
+		Name:      "web-1",
+		Namespace: "record-force-deleted",
+		Reason:    controller.ForceDeletedUnhealthy,
+		Message:   "pod refused eviction while unhealthy",
+		DeletedAt: metav1.NewTime(now),
+	}}, cns.Status.ForceDeletedPods)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.PodsForceDeleted.WithLabelValues(pod.Namespace, controller.ForceDeletedUnhealthy)))
+}
+
+// Test that pods deleted after blocking the drain for too long are returned so they can be recorded
+func TestForceEvictBlockedPodsReturnsDeletedPods(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "ng-1-node-0"},
+	}
+
+	cns := newDrainCNS("ng-1-node-0", v1.CycleNodeStatusDrainingPods)
+	cns.Spec.CycleSettings.DrainBlockedTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	cns.Status.BlockedPods = []v1.BlockedPod{{
+		Name:       "web-1",
+		Namespace:  "default",
+		BlockedFor: metav1.Duration{Duration: 20 * time.Minute},
+	}}
+
+	fakeTransitioner := NewFakeTransitioner(cns, WithExtraKubeObject(pod))
+
+	deleted, err := fakeTransitioner.forceEvictBlockedPods([]corev1.Pod{*pod})
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "web-1", deleted[0].Pod.Name)
+	assert.Equal(t, forceDeletedDrainBlocked, deleted[0].Reason)
+
+	_, err = fakeTransitioner.RawClient.CoreV1().Pods("default").Get(context.TODO(), "web-1", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+// Test that pods blocking the drain aren't deleted when the unhealthy pod policy never allows force deletion
+func TestForceEvictBlockedPodsNeverPolicy(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "ng-1-node-0"},
+	}
+
+	cns := newDrainCNS("ng-1-node-0", v1.CycleNodeStatusDrainingPods)
+	cns.Spec.CycleSettings.DrainBlockedTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	cns.Spec.CycleSettings.UnhealthyPodPolicy = &v1.UnhealthyPodPolicy{Mode: v1.UnhealthyPodPolicyNever}
+	cns.Status.BlockedPods = []v1.BlockedPod{{
+		Name:       "web-1",
+		Namespace:  "default",
+		BlockedFor: metav1.Duration{Duration: 20 * time.Minute},
+	}}
+
+	fakeTransitioner := NewFakeTransitioner(cns)
+	_, err := fakeTransitioner.RawClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	deleted, err := fakeTransitioner.forceEvictBlockedPods([]corev1.Pod{*pod})
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	_, err = fakeTransitioner.RawClient.CoreV1().Pods("default").Get(context.TODO(), "web-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+// Test that a NoExecute drain taint is only added once the node has been drained
+func TestDrainingAddsNoExecuteDrainTaintAfterDrain(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusDrainingPods)
+	cns.Spec.CycleSettings.DrainTaint = &v1.DrainTaint{
+		Key:    "cyclops.atlassian.com/cycling",
+		Value:  "true",
+		Effect: string(corev1.TaintEffectNoExecute),
+	}
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	_, err = fakeTransitioner.Run()
+	require.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+
+	node, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, node.Spec.Taints, 1)
+	assert.Equal(t, "cyclops.atlassian.com/cycling", node.Spec.Taints[0].Key)
+	assert.Equal(t, corev1.TaintEffectNoExecute, node.Spec.Taints[0].Effect)
+	assert.NotNil(t, node.Spec.Taints[0].TimeAdded)
+}
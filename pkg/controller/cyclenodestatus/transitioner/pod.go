@@ -3,6 +3,7 @@ package transitioner
 import (
 	"fmt"
 
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
 	"github.com/atlassian-labs/cyclops/pkg/k8s"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -51,10 +52,15 @@ func (t *CycleNodeStatusTransitioner) removeLabelsFromPods() (finished bool, err
 	return labelsRemoved == 0, nil
 }
 
-// podsFinished returns true if all relevant pods on the node are finished.
+// podsFinished returns true if all relevant pods on the node are finished. The Wait method waits for pods
+// which cannot be disrupted, and the Drain method for pods which have asked not to be drained.
 func (t *CycleNodeStatusTransitioner) podsFinished() (bool, error) {
-	// Get undisruptable pods
-	undisruptablePods, err := t.rm.GetUndisruptablePods(t.cycleNodeStatus.Status.CurrentNode.Name)
+	getPods := t.rm.GetUndrainablePods
+	if t.cycleNodeStatus.Spec.CycleSettings.Method == v1.CycleNodeRequestMethodWait {
+		getPods = t.rm.GetUndisruptablePods
+	}
+
+	undisruptablePods, err := getPods(t.cycleNodeStatus.Status.CurrentNode.Name)
 	if err != nil {
 		return false, err
 	}
@@ -48,9 +48,16 @@ type Options struct {
 	TransitionDuration time.Duration
 
 	// WaitingPodsRequeue is the RequeueAfter used while waiting for pods
-	// on the cycling node to finish naturally (Method=Wait).
+	// on the cycling node to finish naturally (Method=Wait, or pods which
+	// have asked not to be drained with Method=Drain).
 	WaitingPodsRequeue time.Duration
 
+	// DoNotDisruptTimeout is how long the Drain method waits for pods which
+	// have asked not to be drained before draining the node anyway, unless
+	// the CycleNodeStatus sets doNotDisruptTimeout. 0 waits until the
+	// CycleNodeStatus times out.
+	DoNotDisruptTimeout time.Duration
+
 	// RemovingLabelsPodsRequeue is the RequeueAfter used while removing
 	// labels from pods on the cycling node.
 	RemovingLabelsPodsRequeue time.Duration
@@ -62,6 +69,28 @@ type Options struct {
 	// DrainingPodsRequeue is the RequeueAfter used while waiting for the
 	// in-flight drain to finish.
 	DrainingPodsRequeue time.Duration
+
+	// DrainBlockedNotifyThreshold is how long a pod may refuse eviction
+	// before a notification is sent about the blocked drain. 0 disables
+	// the notification.
+	DrainBlockedNotifyThreshold time.Duration
+
+	// DeregisteringNodeRequeue is the RequeueAfter used while waiting for
+	// the node to be removed from load balancers.
+	DeregisteringNodeRequeue time.Duration
+
+	// VolumeDetachTimeout is how long to wait for volumes to be detached
+	// from a deleted node before terminating the instance anyway, unless
+	// the CycleNodeStatus sets volumeDetachTimeout.
+	VolumeDetachTimeout time.Duration
+
+	// DetachingVolumesRequeue is the RequeueAfter used while waiting for
+	// volumes to be detached from the node.
+	DetachingVolumesRequeue time.Duration
+
+	// LifecycleHookRequeue is the RequeueAfter used while retrying blocking
+	// lifecycle hooks which haven't succeeded yet.
+	LifecycleHookRequeue time.Duration
 }
 
 // Run runs the CycleNodeStatusTransitioner and returns a reconcile result and an error
@@ -95,7 +124,9 @@ func (t *CycleNodeStatusTransitioner) transitionFuncs() map[v1.CycleNodeStatusPh
 		v1.CycleNodeStatusWaitingPods:            t.transitionWaitingPods,
 		v1.CycleNodeStatusRemovingLabelsFromPods: t.transitionRemovingLabelsFromPods,
 		v1.CycleNodeStatusDrainingPods:           t.transitionDraining,
+		v1.CycleNodeStatusDeregisteringNode:      t.transitionDeregistering,
 		v1.CycleNodeStatusDeletingNode:           t.transitionDeleting,
+		v1.CycleNodeStatusDetachingVolumes:       t.transitionDetachingVolumes,
 		v1.CycleNodeStatusTerminatingNode:        t.transitionTerminating,
 		v1.CycleNodeStatusFailed:                 t.transitionFailed,
 		v1.CycleNodeStatusSuccessful:             t.transitionSuccessful,
@@ -7,6 +7,9 @@ import (
 	"strings"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -57,6 +60,11 @@ func (t *CycleNodeStatusTransitioner) transitionPending() (reconcile.Result, err
 		// If the node doesn't exist in Kube then assume that the node was killed by something else
 		// Don't allow this to fail the CycleNodeRequest
 		if serr, ok := err.(*errors.StatusError); ok && errors.IsNotFound(serr) {
+			// A retried node may have been removed from Kube before its instance was terminated
+			if t.cycleNodeStatus.Spec.ProviderID != "" {
+				return t.transitionRemovedNode()
+			}
+
 			t.rm.LogEvent(t.cycleNodeStatus, "FetchingNode", "Node not found, assuming cycle successful: %v", t.cycleNodeStatus.Spec.NodeName)
 			return t.transitionToSuccessful()
 		}
@@ -66,6 +74,11 @@ func (t *CycleNodeStatusTransitioner) transitionPending() (reconcile.Result, err
 	// Set the current node
 	t.cycleNodeStatus.Status.CurrentNode.Name = node.Name
 	t.cycleNodeStatus.Status.CurrentNode.ProviderID = node.Spec.ProviderID
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			t.cycleNodeStatus.Status.CurrentNode.PrivateIP = address.Address
+		}
+	}
 
 	// Ensure the node still exists in AWS before attempting anything
 	existingProviderIDs, err := t.rm.CloudProvider.InstancesExist([]string{t.cycleNodeStatus.Status.CurrentNode.ProviderID})
@@ -82,12 +95,48 @@ func (t *CycleNodeStatusTransitioner) transitionPending() (reconcile.Result, err
 	if t.cycleNodeStatus.Spec.CycleSettings.Method == v1.CycleNodeRequestMethodWait {
 		return t.transitionObject(v1.CycleNodeStatusWaitingPods)
 	}
+
+	// Pods which have asked not to be drained are waited for before draining the node
+	finished, err := t.podsFinished()
+	if err != nil {
+		return t.transitionToFailed(err)
+	}
+	if !finished {
+		t.rm.LogEvent(t.cycleNodeStatus, "WaitingPods", "Waiting for pods which asked not to be drained before draining")
+		return t.transitionObject(v1.CycleNodeStatusWaitingPods)
+	}
 	return t.transitionObject(v1.CycleNodeStatusRemovingLabelsFromPods)
 }
 
+// transitionRemovedNode handles a retried node which has already been removed from Kube. The instance
+// may still need terminating if the previous attempt failed after deleting the node. If it still exists in
+// the cloud provider it is deregistered from load balancers and its volumes detached before terminating it,
+// the same as a node which has just been deleted.
+func (t *CycleNodeStatusTransitioner) transitionRemovedNode() (reconcile.Result, error) {
+	t.cycleNodeStatus.Status.CurrentNode.Name = t.cycleNodeStatus.Spec.NodeName
+	t.cycleNodeStatus.Status.CurrentNode.ProviderID = t.cycleNodeStatus.Spec.ProviderID
+
+	existingProviderIDs, err := t.rm.CloudProvider.InstancesExist([]string{t.cycleNodeStatus.Spec.ProviderID})
+	if err != nil {
+		return t.transitionToFailed(err)
+	}
+	if len(existingProviderIDs) == 0 {
+		t.rm.LogEvent(t.cycleNodeStatus, "FetchingNode", "Node and instance not found, assuming cycle successful: %v", t.cycleNodeStatus.Spec.NodeName)
+		return t.transitionToSuccessful()
+	}
+
+	t.rm.LogEvent(t.cycleNodeStatus, "FetchingNode", "Node not found but instance still exists, terminating instance: %v", t.cycleNodeStatus.Spec.ProviderID)
+	t.cycleNodeStatus.Status.CurrentNode.PrivateIP = t.removedNodePrivateIP()
+	if t.cycleNodeStatus.Spec.CycleSettings.LoadBalancerDeregistration != nil {
+		return t.transitionObject(v1.CycleNodeStatusDeregisteringNode)
+	}
+	return t.transitionObject(v1.CycleNodeStatusDetachingVolumes)
+}
+
 // transitionWaitingPods transitions any CycleNodeStatuses in the WaitingPods phase to the
 // RemovingLabelsFromPods phase. Waits for any pods not excluded by the WaitRules for this CycleNodeStatus
-// to finish then transitions to the next phase.
+// to finish then transitions to the next phase. With the Drain method, the node is drained anyway once the
+// pods have been waited on for longer than the doNotDisruptTimeout.
 func (t *CycleNodeStatusTransitioner) transitionWaitingPods() (reconcile.Result, error) {
 	t.rm.LogEvent(t.cycleNodeStatus, "WaitingPods", "Waiting for pods to finish")
 	finished, err := t.podsFinished()
@@ -98,6 +147,21 @@ func (t *CycleNodeStatusTransitioner) transitionWaitingPods() (reconcile.Result,
 		if t.timedOut() {
 			return t.transitionToFailed(fmt.Errorf("timed out waiting for pods to finish"))
 		}
+
+		if t.cycleNodeStatus.Status.WaitingPodsStarted == nil {
+			now := metav1.Now()
+			t.cycleNodeStatus.Status.WaitingPodsStarted = &now
+			if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+
+		if t.doNotDisruptTimedOut() {
+			t.rm.LogWarningEvent(t.cycleNodeStatus, "DoNotDisruptTimeout",
+				"Pods which asked not to be drained have not finished after %s, draining node %s anyway",
+				t.doNotDisruptTimeout(), t.cycleNodeStatus.Status.CurrentNode.Name)
+			return t.transitionObject(v1.CycleNodeStatusRemovingLabelsFromPods)
+		}
 		return reconcile.Result{Requeue: true, RequeueAfter: t.options.WaitingPodsRequeue}, nil
 	}
 
@@ -128,7 +192,13 @@ func (t *CycleNodeStatusTransitioner) transitionRemovingLabelsFromPods() (reconc
 func (t *CycleNodeStatusTransitioner) transitionDraining() (reconcile.Result, error) {
 	// Drain pods off the node
 	t.rm.LogEvent(t.cycleNodeStatus, "DrainingPods", "Draining pods from node: %v", t.cycleNodeStatus.Status.CurrentNode.Name)
-	finished, errs := t.rm.DrainPods(t.cycleNodeStatus.Status.CurrentNode.Name, t.options.UnhealthyPodTerminationThreshold)
+	finished, blocked, forced, errs := t.rm.DrainPods(
+		t.cycleNodeStatus.Status.CurrentNode.Name,
+		t.options.UnhealthyPodTerminationThreshold,
+		t.cycleNodeStatus.Spec.CycleSettings,
+	)
+	now := time.Now()
+	statusChanged := t.recordForceDeletedPods(forced, now)
 
 	// We need to do some fairly complicated error handling here. It is most efficient to drain all pods at once, as
 	// this stops us being blocked behind one pod that takes a long time to get evicted. This means we need to handle
@@ -136,6 +206,7 @@ func (t *CycleNodeStatusTransitioner) transitionDraining() (reconcile.Result, er
 	// "undisruptable" via a pod disruption budget. This error is fine. All others are not, and we have to combine
 	// them and fail this CycleNodeStatus if we encounter them.
 	var unexpectedErrors []string
+	allRetryable := true
 	tooManyRequests := false
 	for _, err := range errs {
 		if err != nil {
@@ -147,31 +218,122 @@ func (t *CycleNodeStatusTransitioner) transitionDraining() (reconcile.Result, er
 				tooManyRequests = true
 			} else {
 				unexpectedErrors = append(unexpectedErrors, err.Error())
+				allRetryable = allRetryable && controller.IsRetryableError(err)
 			}
 		}
 	}
 	// Fail with all of the combined encountered errors if we got any. If we failed inside the loop we would
 	// potentially miss some important information in the logs.
 	if len(unexpectedErrors) > 0 {
-		return t.transitionToFailed(fmt.Errorf("%s", strings.Join(unexpectedErrors, "\n")))
+		err := fmt.Errorf("%s", strings.Join(unexpectedErrors, "\n"))
+		// If every error was transient the node can be retried by the CycleNodeRequest
+		if allRetryable {
+			return t.transitionToRetryableFailed(err)
+		}
+		return t.transitionToFailed(err)
 	}
 	// No serious errors were encountered. If we're done, move on.
 	if finished {
+		t.cycleNodeStatus.Status.BlockedPods = nil
+
+		// Wait for any blocking PostDrain hooks to succeed before taking the node out of service
+		hooksFinished, err := t.callLifecycleHooks(v1.LifecycleHookPostDrain)
+		if err != nil {
+			return t.transitionToFailed(err)
+		}
+		if !hooksFinished {
+			if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true, RequeueAfter: t.options.LifecycleHookRequeue}, nil
+		}
+
+		// The node was only tainted NoSchedule while it was drained, now add the NoExecute taint to evict the
+		// pods left which don't tolerate it
+		if err := t.addNoExecuteDrainTaint(); err != nil {
+			return t.transitionToFailed(err)
+		}
+
+		if t.cycleNodeStatus.Spec.CycleSettings.LoadBalancerDeregistration != nil {
+			return t.transitionObject(v1.CycleNodeStatusDeregisteringNode)
+		}
 		return t.transitionObject(v1.CycleNodeStatusDeletingNode)
 	}
 
+	// Keep track of the pods refusing eviction so they can be found without digging through the controller logs,
+	// and escalate if they have been blocking the drain for too long
+	if t.recordBlockedPods(blocked, now) {
+		statusChanged = true
+	}
+	forceEvicted, err := t.forceEvictBlockedPods(blocked)
+	if t.recordForceDeletedPods(forceEvicted, now) {
+		statusChanged = true
+	}
+	if err != nil {
+		return t.transitionToFailed(err)
+	}
+	t.notifyDrainBlocked()
+
 	// Fail if we've taken too long in this phase.
 	if t.timedOut() {
 		return t.transitionToFailed(fmt.Errorf("timed out while draining pods"))
 	}
+
+	if statusChanged {
+		if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	// The API says we should retry (likely due to currently undisruptable pods)
-	if tooManyRequests {
+	if tooManyRequests || len(blocked) > 0 {
 		return reconcile.Result{Requeue: true, RequeueAfter: t.options.DrainingRetryRequeue}, nil
 	}
 	// If all the pods aren't finished draining, try again a while later to avoid spamming the API server.
 	return reconcile.Result{Requeue: true, RequeueAfter: t.options.DrainingPodsRequeue}, nil
 }
 
+// transitionDeregistering transitions any CycleNodeStatuses in the DeregisteringNode phase to the Deleting phase.
+// It excludes the node from external load balancers and waits for in-flight connections to drain, and for the
+// cloud provider to report the instance has been deregistered if configured, so connections aren't dropped when
+// the instance is terminated. The node is deleted anyway once the timeout is reached.
+func (t *CycleNodeStatusTransitioner) transitionDeregistering() (reconcile.Result, error) {
+	nodeName := t.cycleNodeStatus.Status.CurrentNode.Name
+
+	if t.cycleNodeStatus.Status.DeregisteringStarted == nil {
+		t.rm.LogEvent(t.cycleNodeStatus, "DeregisteringNode", "Excluding node from load balancers: %v", nodeName)
+		err := k8s.ExcludeNodeFromLoadBalancers(nodeName, t.rm.RawClient)
+		if errors.IsNotFound(err) {
+			// A node which has already been removed isn't a load balancer target any more, but the instance
+			// may still be registered with the cloud provider's load balancers
+			if deregistration := t.cycleNodeStatus.Spec.CycleSettings.LoadBalancerDeregistration; deregistration == nil || !deregistration.WaitForTargets {
+				return t.transitionObject(v1.CycleNodeStatusDeletingNode)
+			}
+		} else if err != nil {
+			return t.transitionToFailed(err)
+		}
+
+		now := metav1.Now()
+		t.cycleNodeStatus.Status.DeregisteringStarted = &now
+		if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if t.loadBalancerDeregistered() {
+		return t.transitionObject(v1.CycleNodeStatusDeletingNode)
+	}
+
+	if t.deregistrationTimedOut() {
+		t.rm.LogWarningEvent(t.cycleNodeStatus, "DeregistrationTimeout",
+			"Node %s is still registered with load balancers after %s, deleting it anyway",
+			nodeName, t.deregistrationTimeout())
+		return t.transitionObject(v1.CycleNodeStatusDeletingNode)
+	}
+
+	return reconcile.Result{Requeue: true, RequeueAfter: t.options.DeregisteringNodeRequeue}, nil
+}
+
 // transitionDeleting transitions any CycleNodeStatuses in the Deleting phase to the Terminating phase
 // It will delete the node out of the Kubernetes API and remove the finalizer.
 func (t *CycleNodeStatusTransitioner) transitionDeleting() (reconcile.Result, error) {
@@ -187,17 +349,72 @@ func (t *CycleNodeStatusTransitioner) transitionDeleting() (reconcile.Result, er
 		return t.transitionToFailed(err)
 	}
 
-	return t.transitionObject(v1.CycleNodeStatusTerminatingNode)
+	return t.transitionObject(v1.CycleNodeStatusDetachingVolumes)
+}
+
+// transitionDetachingVolumes transitions any CycleNodeStatuses in the DetachingVolumes phase to the Terminating
+// phase. It waits for the CSI VolumeAttachments referencing the node to be removed, so pods using the volumes
+// don't hit multi-attach errors when they are rescheduled. The instance is terminated anyway once the
+// volumeDetachTimeout is reached.
+func (t *CycleNodeStatusTransitioner) transitionDetachingVolumes() (reconcile.Result, error) {
+	nodeName := t.cycleNodeStatus.Status.CurrentNode.Name
+
+	volumeAttachments, err := k8s.VolumeAttachmentsForNode(nodeName, t.rm.RawClient)
+	if err != nil {
+		return t.transitionToFailed(err)
+	}
+
+	if len(volumeAttachments) == 0 {
+		return t.transitionObject(v1.CycleNodeStatusTerminatingNode)
+	}
+
+	if t.cycleNodeStatus.Status.DetachingVolumesStarted == nil {
+		now := metav1.Now()
+		t.cycleNodeStatus.Status.DetachingVolumesStarted = &now
+		t.rm.LogEvent(t.cycleNodeStatus, "DetachingVolumes",
+			"Waiting for volumes to be detached from node %s: %v", nodeName, volumeAttachments)
+		if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if t.volumeDetachTimedOut() {
+		t.rm.LogWarningEvent(t.cycleNodeStatus, "VolumeDetachTimeout",
+			"Volumes still attached to node %s after %s, terminating the instance anyway: %v",
+			nodeName, t.volumeDetachTimeout(), volumeAttachments)
+		return t.transitionObject(v1.CycleNodeStatusTerminatingNode)
+	}
+
+	t.rm.Logger.Info("waiting for volumes to be detached", "volumeAttachments", volumeAttachments)
+	return reconcile.Result{Requeue: true, RequeueAfter: t.options.DetachingVolumesRequeue}, nil
 }
 
 // transitionTerminating transitions any CycleNodeStatuses in the Terminating phase to the Successful phase.
 // It terminates the node via the cloud provider.
 func (t *CycleNodeStatusTransitioner) transitionTerminating() (reconcile.Result, error) {
-	t.rm.LogEvent(t.cycleNodeStatus, "TerminatingNode", "Terminating instance: %v", t.cycleNodeStatus.Status.CurrentNode.ProviderID)
-	err := t.rm.CloudProvider.TerminateInstance(t.cycleNodeStatus.Status.CurrentNode.ProviderID)
+	// The instance has already been terminated if the PostTerminate hooks have been called, only retry the hooks
+	if !controller.LifecycleHooksStarted(v1.LifecycleHookPostTerminate,
+		t.cycleNodeStatus.Spec.CycleSettings.LifecycleHooks, t.cycleNodeStatus.Status.LifecycleHooks) {
+		t.rm.LogEvent(t.cycleNodeStatus, "TerminatingNode", "Terminating instance: %v", t.cycleNodeStatus.Status.CurrentNode.ProviderID)
+		err := t.rm.CloudProvider.TerminateInstance(t.cycleNodeStatus.Status.CurrentNode.ProviderID)
+		if err != nil {
+			if controller.IsRetryableError(err) {
+				return t.transitionToRetryableFailed(err)
+			}
+			return t.transitionToFailed(err)
+		}
+	}
+
+	hooksFinished, err := t.callLifecycleHooks(v1.LifecycleHookPostTerminate)
 	if err != nil {
 		return t.transitionToFailed(err)
 	}
+	if !hooksFinished {
+		if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true, RequeueAfter: t.options.LifecycleHookRequeue}, nil
+	}
 
 	return t.transitionObject(v1.CycleNodeStatusSuccessful)
 }
@@ -0,0 +1,280 @@
+package transitioner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/cloudprovider"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// deregistrationCheckingCloudProvider is a cloud provider which reports whether instances have been deregistered
+// from load balancers
+type deregistrationCheckingCloudProvider struct {
+	cloudprovider.CloudProvider
+	deregistered bool
+}
+
+func (p *deregistrationCheckingCloudProvider) InstanceDeregisteredFromLoadBalancers(providerID string) (bool, error) {
+	return p.deregistered, nil
+}
+
+// uncheckedCloudProvider is a cloud provider which can't report whether instances have been deregistered from
+// load balancers
+type uncheckedCloudProvider struct {
+	cloudprovider.CloudProvider
+}
+
+// newDeregisteringCNS returns a CycleNodeStatus in the DeregisteringNode phase for the node
+func newDeregisteringCNS(node *mock.Node, deregistration *v1.LoadBalancerDeregistration) *v1.CycleNodeStatus {
+	cns := newDrainCNS(node.Name, v1.CycleNodeStatusDeregisteringNode)
+	cns.Spec.CycleSettings.LoadBalancerDeregistration = deregistration
+	cns.Status.CurrentNode.ProviderID = node.ProviderID
+	return cns
+}
+
+// Test that the node is excluded from load balancers and the connection draining delay is waited for
+func TestDeregisteringExcludesNodeFromLoadBalancers(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{
+		DrainingDelay: &metav1.Duration{Duration: time.Minute},
+	})
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, fakeTransitioner.options.DeregisteringNodeRequeue, result.RequeueAfter)
+	assert.Equal(t, v1.CycleNodeStatusDeregisteringNode, cns.Status.Phase)
+	assert.NotNil(t, cns.Status.DeregisteringStarted)
+
+	node, err := fakeTransitioner.RawClient.CoreV1().Nodes().Get(context.TODO(), nodegroup[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", node.Labels[corev1.LabelNodeExcludeBalancers])
+}
+
+// Test that the node is deleted once the connection draining delay has passed
+func TestDeregisteringAfterDrainingDelay(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{
+		DrainingDelay: &metav1.Duration{Duration: time.Minute},
+	})
+	deregisteringStarted := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	cns.Status.DeregisteringStarted = &deregisteringStarted
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+}
+
+// Test that the cloud provider is polled until the instance has been deregistered from load balancers
+func TestDeregisteringWaitsForTargets(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{WaitForTargets: true})
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+	cloudProvider := &deregistrationCheckingCloudProvider{CloudProvider: fakeTransitioner.CloudProvider}
+	fakeTransitioner.rm.CloudProvider = cloudProvider
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeregisteringNode, cns.Status.Phase)
+
+	cloudProvider.deregistered = true
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+}
+
+// Test that the node is deleted anyway once the deregistration timeout is reached
+func TestDeregisteringAfterTimeout(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{
+		WaitForTargets: true,
+		Timeout:        &metav1.Duration{Duration: 5 * time.Minute},
+	})
+	deregisteringStarted := metav1.NewTime(time.Now().Add(-6 * time.Minute))
+	cns.Status.DeregisteringStarted = &deregisteringStarted
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+	fakeTransitioner.rm.CloudProvider = &deregistrationCheckingCloudProvider{CloudProvider: fakeTransitioner.CloudProvider}
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+}
+
+// Test that a node which has already been removed is not waited for
+func TestDeregisteringNodeNotFound(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{
+		DrainingDelay: &metav1.Duration{Duration: time.Minute},
+	})
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+	assert.Nil(t, cns.Status.DeregisteringStarted)
+}
+
+// Test that the cloud provider is still waited for when the node has already been removed but its instance
+// is still running
+func TestDeregisteringNodeNotFoundWaitsForTargets(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{WaitForTargets: true})
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithCloudProviderInstances(nodegroup),
+	)
+	cloudProvider := &deregistrationCheckingCloudProvider{CloudProvider: fakeTransitioner.CloudProvider}
+	fakeTransitioner.rm.CloudProvider = cloudProvider
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeregisteringNode, cns.Status.Phase)
+	assert.NotNil(t, cns.Status.DeregisteringStarted)
+
+	cloudProvider.deregistered = true
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+}
+
+// Test that a retried node which has already been removed from Kube is deregistered from load balancers before
+// its instance is terminated, and has its volumes detached otherwise
+func TestPendingRemovedNode(t *testing.T) {
+	tests := []struct {
+		name           string
+		deregistration *v1.LoadBalancerDeregistration
+		expectedPhase  v1.CycleNodeStatusPhase
+	}{
+		{"load balancer deregistration", &v1.LoadBalancerDeregistration{WaitForTargets: true}, v1.CycleNodeStatusDeregisteringNode},
+		{"no load balancer deregistration", nil, v1.CycleNodeStatusDetachingVolumes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodegroup, err := mock.NewNodegroup("ng-1", 1)
+			require.NoError(t, err)
+
+			cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusPending)
+			cns.Spec.CycleSettings.LoadBalancerDeregistration = tt.deregistration
+
+			fakeTransitioner := NewFakeTransitioner(cns,
+				WithCloudProviderInstances(nodegroup),
+			)
+
+			// The provider IDs are only generated when the fake client is built
+			cns.Spec.ProviderID = nodegroup[0].ProviderID
+
+			_, err = fakeTransitioner.Run()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPhase, cns.Status.Phase)
+			assert.Equal(t, nodegroup[0].ProviderID, cns.Status.CurrentNode.ProviderID)
+		})
+	}
+}
+
+// Test that a cloud provider which can't check load balancer registration is only warned about once and
+// recorded in the status
+func TestDeregisteringWithoutTargetsCheckWarnsOnce(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDeregisteringCNS(nodegroup[0], &v1.LoadBalancerDeregistration{WaitForTargets: true})
+	deregisteringStarted := metav1.Now()
+	cns.Status.DeregisteringStarted = &deregisteringStarted
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+	fakeTransitioner.rm.CloudProvider = &uncheckedCloudProvider{CloudProvider: fakeTransitioner.CloudProvider}
+	recorder := record.NewFakeRecorder(10)
+	fakeTransitioner.rm.Recorder = recorder
+
+	assert.True(t, fakeTransitioner.loadBalancerDeregistered())
+	assert.True(t, cns.Status.TargetsUnchecked)
+	assert.True(t, fakeTransitioner.loadBalancerDeregistered())
+
+	close(recorder.Events)
+	var warnings int
+	for event := range recorder.Events {
+		if strings.HasPrefix(event, corev1.EventTypeWarning) {
+			warnings++
+		}
+	}
+	assert.Equal(t, 1, warnings)
+}
+
+// Test that a retried node which has already been removed from Kube gets its private IP from the
+// CycleNodeRequest, so the lifecycle hooks can still be called with it
+func TestPendingRemovedNodePrivateIP(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cnr := &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "cnr-test", Namespace: "kube-system"},
+		Status: v1.CycleNodeRequestStatus{
+			NodesToTerminate: []v1.CycleNodeRequestNode{{
+				Name:      nodegroup[0].Name,
+				PrivateIP: "10.0.0.1",
+			}},
+		},
+	}
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusPending)
+	cns.OwnerReferences = []metav1.OwnerReference{{Kind: "CycleNodeRequest", Name: cnr.Name}}
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(cnr),
+	)
+	cns.Spec.ProviderID = nodegroup[0].ProviderID
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDetachingVolumes, cns.Status.Phase)
+	assert.Equal(t, "10.0.0.1", cns.Status.CurrentNode.PrivateIP)
+}
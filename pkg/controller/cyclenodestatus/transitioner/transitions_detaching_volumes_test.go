@@ -0,0 +1,121 @@
+package transitioner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newVolumeAttachment returns a CSI VolumeAttachment for the node
+func newVolumeAttachment(name, nodeName string) *storagev1.VolumeAttachment {
+	volumeName := name + "-pv"
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "ebs.csi.aws.com",
+			NodeName: nodeName,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &volumeName},
+		},
+	}
+}
+
+// newDetachingVolumesTransitioner returns a transitioner for a CycleNodeStatus in the DetachingVolumes phase with
+// the VolumeAttachments created in the cluster
+func newDetachingVolumesTransitioner(t *testing.T, cns *v1.CycleNodeStatus, nodegroup []*mock.Node, volumeAttachments ...*storagev1.VolumeAttachment) *Transitioner {
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	for _, volumeAttachment := range volumeAttachments {
+		_, err := fakeTransitioner.RawClient.StorageV1().VolumeAttachments().Create(context.TODO(), volumeAttachment, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	return fakeTransitioner
+}
+
+// Test that the instance is terminated straight away when there are no volumes attached to the node
+func TestDetachingVolumesWithoutVolumeAttachments(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 2)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusDetachingVolumes)
+	fakeTransitioner := newDetachingVolumesTransitioner(t, cns, nodegroup,
+		newVolumeAttachment("csi-1", nodegroup[1].Name),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusTerminatingNode, cns.Status.Phase)
+}
+
+// Test that the instance isn't terminated while volumes are still attached to the node
+func TestDetachingVolumesWaitsForVolumeAttachments(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusDetachingVolumes)
+	fakeTransitioner := newDetachingVolumesTransitioner(t, cns, nodegroup,
+		newVolumeAttachment("csi-1", nodegroup[0].Name),
+	)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, fakeTransitioner.options.DetachingVolumesRequeue, result.RequeueAfter)
+	assert.Equal(t, v1.CycleNodeStatusDetachingVolumes, cns.Status.Phase)
+	assert.NotNil(t, cns.Status.DetachingVolumesStarted)
+
+	// Continue once the volume has been detached
+	err = fakeTransitioner.RawClient.StorageV1().VolumeAttachments().Delete(context.TODO(), "csi-1", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusTerminatingNode, cns.Status.Phase)
+}
+
+// Test that the instance is terminated anyway once the volumeDetachTimeout is reached
+func TestDetachingVolumesAfterVolumeDetachTimeout(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusDetachingVolumes)
+	detachingVolumesStarted := metav1.NewTime(time.Now().Add(-6 * time.Minute))
+	cns.Status.DetachingVolumesStarted = &detachingVolumesStarted
+
+	fakeTransitioner := newDetachingVolumesTransitioner(t, cns, nodegroup,
+		newVolumeAttachment("csi-1", nodegroup[0].Name),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusTerminatingNode, cns.Status.Phase)
+}
+
+// Test that the volumeDetachTimeout of the CycleNodeStatus takes precedence over the controller default
+func TestDetachingVolumesWithinVolumeDetachTimeoutOverride(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusDetachingVolumes)
+	cns.Spec.CycleSettings.VolumeDetachTimeout = &metav1.Duration{Duration: 15 * time.Minute}
+	detachingVolumesStarted := metav1.NewTime(time.Now().Add(-6 * time.Minute))
+	cns.Status.DetachingVolumesStarted = &detachingVolumesStarted
+
+	fakeTransitioner := newDetachingVolumesTransitioner(t, cns, nodegroup,
+		newVolumeAttachment("csi-1", nodegroup[0].Name),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDetachingVolumes, cns.Status.Phase)
+}
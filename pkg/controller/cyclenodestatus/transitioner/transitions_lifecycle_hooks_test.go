@@ -0,0 +1,94 @@
+package transitioner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+)
+
+// Test that the CycleNodeStatus waits for a blocking PostTerminate hook to succeed without terminating the
+// instance again
+func TestTerminatingWaitsForBlockingLifecycleHook(t *testing.T) {
+	var calls int
+	statusCode := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusTerminatingNode)
+	cns.Spec.CycleSettings.LifecycleHooks = []v1.LifecycleHook{{
+		Name:     "cmdb",
+		Event:    v1.LifecycleHookPostTerminate,
+		Endpoint: server.URL,
+		Blocking: true,
+	}}
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+	cns.Status.CurrentNode.ProviderID = nodegroup[0].ProviderID
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, fakeTransitioner.options.LifecycleHookRequeue, result.RequeueAfter)
+	assert.Equal(t, v1.CycleNodeStatusTerminatingNode, cns.Status.Phase)
+	assert.Equal(t, 1, calls)
+	require.Len(t, cns.Status.LifecycleHooks, 1)
+	assert.Nil(t, cns.Status.LifecycleHooks[0].Succeeded)
+
+	// The instance has already been terminated, so only the hook is retried
+	existing, err := fakeTransitioner.CloudProvider.InstancesExist([]string{nodegroup[0].ProviderID})
+	require.NoError(t, err)
+	assert.Empty(t, existing)
+
+	statusCode = http.StatusOK
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusSuccessful, cns.Status.Phase)
+	assert.Equal(t, 2, calls)
+	assert.NotNil(t, cns.Status.LifecycleHooks[0].Succeeded)
+}
+
+// Test that a failing non-blocking PostDrain hook is only called once and doesn't stop the node being cycled
+func TestDrainingCallsNonBlockingLifecycleHook(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	require.NoError(t, err)
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusDrainingPods)
+	cns.Spec.CycleSettings.LifecycleHooks = []v1.LifecycleHook{{
+		Name:     "registry",
+		Event:    v1.LifecycleHookPostDrain,
+		Endpoint: server.URL,
+	}}
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusDeletingNode, cns.Status.Phase)
+	assert.Equal(t, 1, calls)
+	require.Len(t, cns.Status.LifecycleHooks, 1)
+	assert.NotEmpty(t, cns.Status.LifecycleHooks[0].Message)
+}
@@ -0,0 +1,213 @@
+package transitioner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newDrainCNS returns a CycleNodeStatus using the Drain method for the node
+func newDrainCNS(nodeName string, phase v1.CycleNodeStatusPhase) *v1.CycleNodeStatus {
+	timeout := metav1.NewTime(time.Now().Add(time.Hour))
+	return &v1.CycleNodeStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cns-test",
+			Namespace: "kube-system",
+		},
+		Spec: v1.CycleNodeStatusSpec{
+			NodeName: nodeName,
+			CycleSettings: v1.CycleSettings{
+				Method: v1.CycleNodeRequestMethodDrain,
+			},
+		},
+		Status: v1.CycleNodeStatusStatus{
+			Phase:            phase,
+			CurrentNode:      v1.CycleNodeRequestNode{Name: nodeName},
+			TimeoutTimestamp: &timeout,
+		},
+	}
+}
+
+// newDoNotDrainPod returns a running pod on the node which has asked not to be drained
+func newDoNotDrainPod(nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "training-job",
+			Namespace:   "default",
+			Annotations: map[string]string{"cyclops.atlassian.com/do-not-drain": "true"},
+		},
+		Spec:   corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// Test that the Drain method waits for pods which have asked not to be drained before draining the node
+func TestPendingDrainWaitsForDoNotDrainPods(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusPending)
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(newDoNotDrainPod(nodegroup[0].Name)),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusWaitingPods, cns.Status.Phase)
+}
+
+// Test that the Drain method goes straight to draining when no pods have asked not to be drained
+func TestPendingDrainWithoutDoNotDrainPods(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	pod := newDoNotDrainPod(nodegroup[0].Name)
+	pod.Annotations = nil
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusPending)
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(pod),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusRemovingLabelsFromPods, cns.Status.Phase)
+}
+
+// Test that the Drain method doesn't wait for pods with the do-not-disrupt annotation, which is only honoured by
+// the Wait method
+func TestPendingDrainIgnoresDoNotDisruptPods(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	pod := newDoNotDrainPod(nodegroup[0].Name)
+	pod.Annotations = map[string]string{"cyclops.atlassian.com/do-not-disrupt": "true"}
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusPending)
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(pod),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusRemovingLabelsFromPods, cns.Status.Phase)
+}
+
+// Test that the Drain method keeps waiting for pods which have asked not to be drained within the doNotDisruptTimeout
+func TestWaitingPodsDrainWithinDoNotDisruptTimeout(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusWaitingPods)
+	cns.Spec.CycleSettings.DoNotDisruptTimeout = &metav1.Duration{Duration: time.Hour}
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(newDoNotDrainPod(nodegroup[0].Name)),
+	)
+
+	result, err := fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, fakeTransitioner.options.WaitingPodsRequeue, result.RequeueAfter)
+	assert.Equal(t, v1.CycleNodeStatusWaitingPods, cns.Status.Phase)
+	assert.NotNil(t, cns.Status.WaitingPodsStarted)
+}
+
+// Test that the Drain method drains the node anyway once the doNotDisruptTimeout is reached
+func TestWaitingPodsDrainAfterDoNotDisruptTimeout(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusWaitingPods)
+	cns.Spec.CycleSettings.DoNotDisruptTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+	waitingPodsStarted := metav1.NewTime(time.Now().Add(-31 * time.Minute))
+	cns.Status.WaitingPodsStarted = &waitingPodsStarted
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(newDoNotDrainPod(nodegroup[0].Name)),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusRemovingLabelsFromPods, cns.Status.Phase)
+}
+
+// Test that the Drain method drains the node anyway once the controller's default doNotDisruptTimeout is reached
+// when the CycleNodeStatus doesn't set one
+func TestWaitingPodsDrainAfterDefaultDoNotDisruptTimeout(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusWaitingPods)
+	waitingPodsStarted := metav1.NewTime(time.Now().Add(-61 * time.Minute))
+	cns.Status.WaitingPodsStarted = &waitingPodsStarted
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(newDoNotDrainPod(nodegroup[0].Name)),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusRemovingLabelsFromPods, cns.Status.Phase)
+}
+
+// Test that the Drain method continues once the do-not-drain annotation is removed from the pod
+func TestWaitingPodsDrainAfterAnnotationRemoved(t *testing.T) {
+	nodegroup, err := mock.NewNodegroup("ng-1", 1)
+	if err != nil {
+		assert.NoError(t, err)
+	}
+
+	pod := newDoNotDrainPod(nodegroup[0].Name)
+	cns := newDrainCNS(nodegroup[0].Name, v1.CycleNodeStatusWaitingPods)
+
+	fakeTransitioner := NewFakeTransitioner(cns,
+		WithKubeNodes(nodegroup),
+		WithCloudProviderInstances(nodegroup),
+		WithExtraKubeObject(pod),
+	)
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusWaitingPods, cns.Status.Phase)
+
+	pod.Annotations = nil
+	assert.NoError(t, fakeTransitioner.K8sClient.Update(context.TODO(), pod))
+
+	_, err = fakeTransitioner.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, v1.CycleNodeStatusRemovingLabelsFromPods, cns.Status.Phase)
+}
@@ -4,11 +4,19 @@ import (
 	"time"
 
 	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/cloudprovider"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // transitionToFailed transitions the current cycleNodeStatus to failed
 func (t *CycleNodeStatusTransitioner) transitionToFailed(err error) (reconcile.Result, error) {
+	if t.cycleNodeStatus.Status.Phase != v1.CycleNodeStatusFailed {
+		t.cycleNodeStatus.Status.FailedPhase = t.cycleNodeStatus.Status.Phase
+	}
 	t.cycleNodeStatus.Status.Phase = v1.CycleNodeStatusFailed
 	t.cycleNodeStatus.Status.Message = err.Error()
 	if err := t.rm.UpdateObject(t.cycleNodeStatus); err != nil {
@@ -17,6 +25,13 @@ func (t *CycleNodeStatusTransitioner) transitionToFailed(err error) (reconcile.R
 	return reconcile.Result{}, err
 }
 
+// transitionToRetryableFailed transitions the current cycleNodeStatus to failed, marking that the
+// failure was caused by a transient error and the node can be retried by the CycleNodeRequest
+func (t *CycleNodeStatusTransitioner) transitionToRetryableFailed(err error) (reconcile.Result, error) {
+	t.cycleNodeStatus.Status.Retryable = true
+	return t.transitionToFailed(err)
+}
+
 // transitionToSuccessful transitions the current cycleNodeStatus to successful
 func (t *CycleNodeStatusTransitioner) transitionToSuccessful() (reconcile.Result, error) {
 	t.rm.LogEvent(t.cycleNodeStatus, "Successful", "Successfully cycled node")
@@ -36,6 +51,165 @@ func (t *CycleNodeStatusTransitioner) transitionObject(desiredPhase v1.CycleNode
 	}, nil
 }
 
+// doNotDisruptTimeout returns how long the Drain method waits for pods which have asked not to be drained,
+// preferring the doNotDisruptTimeout of the CycleNodeStatus over the controller default
+func (t *CycleNodeStatusTransitioner) doNotDisruptTimeout() time.Duration {
+	if timeout := t.cycleNodeStatus.Spec.CycleSettings.DoNotDisruptTimeout; timeout != nil {
+		return timeout.Duration
+	}
+	return t.options.DoNotDisruptTimeout
+}
+
+// doNotDisruptTimedOut returns true if the Drain method has waited longer than the doNotDisruptTimeout for pods
+// which have asked not to be drained to finish
+func (t *CycleNodeStatusTransitioner) doNotDisruptTimedOut() bool {
+	timeout := t.doNotDisruptTimeout()
+	if t.cycleNodeStatus.Spec.CycleSettings.Method != v1.CycleNodeRequestMethodDrain || timeout <= 0 {
+		return false
+	}
+	if t.cycleNodeStatus.Status.WaitingPodsStarted == nil {
+		return false
+	}
+	return time.Since(t.cycleNodeStatus.Status.WaitingPodsStarted.Time) > timeout
+}
+
+// addNoExecuteDrainTaint adds the drainTaint to the drained node if it has the NoExecute effect. The CycleNodeRequest
+// only taints the node NoSchedule when it is cordoned so the pods are evicted by the drain, respecting
+// PodDisruptionBudgets, and the NoExecute taint evicts the pods left afterwards. A node which has already been
+// removed is skipped.
+func (t *CycleNodeStatusTransitioner) addNoExecuteDrainTaint() error {
+	drainTaint := t.cycleNodeStatus.Spec.CycleSettings.DrainTaint
+	if drainTaint == nil || corev1.TaintEffect(drainTaint.Effect) != corev1.TaintEffectNoExecute {
+		return nil
+	}
+
+	nodeName := t.cycleNodeStatus.Status.CurrentNode.Name
+	t.rm.LogEvent(t.cycleNodeStatus, "TaintingNode", "Adding taint %s:%s to node %s", drainTaint.Key, drainTaint.Effect, nodeName)
+
+	err := k8s.AddTaintToNode(nodeName, corev1.Taint{
+		Key:    drainTaint.Key,
+		Value:  drainTaint.Value,
+		Effect: corev1.TaintEffectNoExecute,
+	}, t.rm.RawClient)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// removedNodePrivateIP returns the private IP of a node which has already been removed from Kube, as recorded in
+// the nodesToTerminate of the CycleNodeRequest which owns the CycleNodeStatus. The lifecycle hooks are called with
+// an empty node IP if it can't be found.
+func (t *CycleNodeStatusTransitioner) removedNodePrivateIP() string {
+	cnr, err := t.getOwnerCycleNodeRequest()
+	if err != nil {
+		t.rm.Logger.Error(err, "unable to get the private IP of the removed node", "nodeName", t.cycleNodeStatus.Spec.NodeName)
+		return ""
+	}
+
+	for _, node := range cnr.Status.NodesToTerminate {
+		if node.Name == t.cycleNodeStatus.Spec.NodeName {
+			return node.PrivateIP
+		}
+	}
+	return ""
+}
+
+// defaultDeregistrationTimeout is how long to wait for a node to be deregistered from load balancers when the
+// loadBalancerDeregistration doesn't set a timeout
+const defaultDeregistrationTimeout = 10 * time.Minute
+
+// loadBalancerDeregistered returns true once the drainingDelay has passed since the node was excluded from load
+// balancers and, with waitForTargets, the cloud provider reports the instance has been deregistered
+func (t *CycleNodeStatusTransitioner) loadBalancerDeregistered() bool {
+	deregistration := t.cycleNodeStatus.Spec.CycleSettings.LoadBalancerDeregistration
+	if deregistration == nil || t.cycleNodeStatus.Status.DeregisteringStarted == nil {
+		return true
+	}
+
+	if deregistration.DrainingDelay != nil &&
+		time.Since(t.cycleNodeStatus.Status.DeregisteringStarted.Time) < deregistration.DrainingDelay.Duration {
+		return false
+	}
+
+	if !deregistration.WaitForTargets {
+		return true
+	}
+
+	checker, ok := t.rm.CloudProvider.(cloudprovider.LoadBalancerDeregistrationChecker)
+	if !ok {
+		// Only warn the first time, the status records that the targets weren't checked
+		if !t.cycleNodeStatus.Status.TargetsUnchecked {
+			t.rm.LogWarningEvent(t.cycleNodeStatus, "DeregisteringNode",
+				"Cloud provider %s can't check load balancer registration, not waiting for targets", t.rm.CloudProvider.Name())
+			t.cycleNodeStatus.Status.TargetsUnchecked = true
+		}
+		return true
+	}
+
+	deregistered, err := checker.InstanceDeregisteredFromLoadBalancers(t.cycleNodeStatus.Status.CurrentNode.ProviderID)
+	if err != nil {
+		// Keep waiting until the timeout in case the error is transient
+		t.rm.Logger.Error(err, "unable to check load balancer registration", "providerID", t.cycleNodeStatus.Status.CurrentNode.ProviderID)
+		return false
+	}
+
+	return deregistered
+}
+
+// deregistrationTimeout returns how long to wait for the node to be deregistered from load balancers
+func (t *CycleNodeStatusTransitioner) deregistrationTimeout() time.Duration {
+	if deregistration := t.cycleNodeStatus.Spec.CycleSettings.LoadBalancerDeregistration; deregistration != nil && deregistration.Timeout != nil {
+		return deregistration.Timeout.Duration
+	}
+	return defaultDeregistrationTimeout
+}
+
+// deregistrationTimedOut returns true if the CycleNodeStatus has waited longer than the deregistration timeout
+// for the node to be removed from load balancers
+func (t *CycleNodeStatusTransitioner) deregistrationTimedOut() bool {
+	if t.cycleNodeStatus.Status.DeregisteringStarted == nil {
+		return false
+	}
+	return time.Since(t.cycleNodeStatus.Status.DeregisteringStarted.Time) > t.deregistrationTimeout()
+}
+
+// volumeDetachTimeout returns how long to wait for volumes to be detached from the node, preferring the
+// volumeDetachTimeout of the CycleNodeStatus over the controller default
+func (t *CycleNodeStatusTransitioner) volumeDetachTimeout() time.Duration {
+	if timeout := t.cycleNodeStatus.Spec.CycleSettings.VolumeDetachTimeout; timeout != nil {
+		return timeout.Duration
+	}
+	return t.options.VolumeDetachTimeout
+}
+
+// volumeDetachTimedOut returns true if the CycleNodeStatus has waited longer than the volumeDetachTimeout for
+// volumes to be detached from the node
+func (t *CycleNodeStatusTransitioner) volumeDetachTimedOut() bool {
+	if t.cycleNodeStatus.Status.DetachingVolumesStarted == nil {
+		return false
+	}
+	return time.Since(t.cycleNodeStatus.Status.DetachingVolumesStarted.Time) > t.volumeDetachTimeout()
+}
+
+// callLifecycleHooks calls the lifecycle hooks registered for the event on the current node, and returns whether
+// all the blocking hooks have succeeded
+func (t *CycleNodeStatusTransitioner) callLifecycleHooks(event v1.LifecycleHookEvent) (bool, error) {
+	hooks, finished, err := t.rm.CallLifecycleHooks(
+		t.cycleNodeStatus,
+		event,
+		t.cycleNodeStatus.Spec.CycleSettings.LifecycleHooks,
+		t.cycleNodeStatus.Status.LifecycleHooks,
+		controller.LifecycleHookRequest{
+			CycleNodeRequest: t.cycleNodeStatus.Labels["name"],
+			Namespace:        t.cycleNodeStatus.Namespace,
+			Node:             t.cycleNodeStatus.Status.CurrentNode,
+		},
+	)
+	t.cycleNodeStatus.Status.LifecycleHooks = hooks
+	return finished, err
+}
+
 // timedOut returns true if the processing of this CycleNodeStatus has been going longer
 // than the calculated timeout timestamp
 func (t *CycleNodeStatusTransitioner) timedOut() bool {
@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// ListDaemonSets lists the DaemonSets in all namespaces
+func (rm *ResourceManager) ListDaemonSets() ([]appsv1.DaemonSet, error) {
+	var daemonSetList appsv1.DaemonSetList
+	if err := rm.Client.List(context.TODO(), &daemonSetList); err != nil {
+		return nil, err
+	}
+	return daemonSetList.Items, nil
+}
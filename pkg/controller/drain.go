@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// ForceDeletedUnhealthy is the reason for forcibly deleting a pod which refused eviction while unhealthy
+	ForceDeletedUnhealthy = "Unhealthy"
+
+	// ForceDeletedEvictionTimeout is the reason for forcibly deleting an evicted pod which took too long to
+	// terminate
+	ForceDeletedEvictionTimeout = "EvictionTimeout"
+)
+
+// ForceDeletedPod is a pod which was forcibly deleted while draining a node, rather than evicted
+type ForceDeletedPod struct {
+	Pod     v1.Pod
+	Reason  string
+	Message string
+}
+
+// drainWave is the position of a pod in the order pods are evicted from a node
+type drainWave struct {
+	wave     int
+	priority int32
+}
+
+// before returns whether the wave is evicted before the other wave
+func (d drainWave) before(other drainWave) bool {
+	if d.wave != other.wave {
+		return d.wave < other.wave
+	}
+	return d.priority < other.priority
+}
+
+// nextDrainWave returns the pods which are evicted next from a node. These are the pods in the earliest wave of
+// the drain order, so later waves are only evicted once the earlier waves have left the node. Returns all of the
+// pods if there is no drain order.
+func nextDrainWave(pods []v1.Pod, order *atlassianv1.DrainOrder) ([]v1.Pod, error) {
+	if order == nil || len(pods) == 0 {
+		return pods, nil
+	}
+
+	selectors := make([]labels.Selector, 0, len(order.Waves))
+	for i := range order.Waves {
+		selector, err := metav1.LabelSelectorAsSelector(&order.Waves[i])
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+
+	waves := make([]drainWave, 0, len(pods))
+	for _, pod := range pods {
+		var wave drainWave
+
+		// Pods which don't match any of the waves are evicted first
+		for i, selector := range selectors {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				wave.wave = i + 1
+				break
+			}
+		}
+
+		if order.ByPriority && pod.Spec.Priority != nil {
+			wave.priority = *pod.Spec.Priority
+		}
+
+		waves = append(waves, wave)
+	}
+
+	next := waves[0]
+	for _, wave := range waves[1:] {
+		if wave.before(next) {
+			next = wave
+		}
+	}
+
+	var nextPods []v1.Pod
+	for i, pod := range pods {
+		if waves[i] == next {
+			nextPods = append(nextPods, pod)
+		}
+	}
+
+	return nextPods, nil
+}
+
+// evictionDeleteOptions returns the delete options to evict pods with. Returns nil to use the defaults if there
+// are no eviction options.
+func evictionDeleteOptions(options *atlassianv1.EvictionOptions) *metav1.DeleteOptions {
+	if options == nil {
+		return nil
+	}
+
+	deleteOptions := &metav1.DeleteOptions{GracePeriodSeconds: options.GracePeriodSeconds}
+	if options.PropagationPolicy != "" {
+		propagationPolicy := metav1.DeletionPropagation(options.PropagationPolicy)
+		deleteOptions.PropagationPolicy = &propagationPolicy
+	}
+	return deleteOptions
+}
+
+// evictionTimedOut returns whether the pod has been terminating for longer than the podEvictionTimeout since it
+// was evicted
+func evictionTimedOut(pod v1.Pod, options *atlassianv1.EvictionOptions, now time.Time) bool {
+	if options == nil || options.PodEvictionTimeout == nil || pod.DeletionTimestamp == nil {
+		return false
+	}
+
+	// The deletion timestamp is when the grace period ends, so work back to when the pod was evicted
+	evictedAt := pod.DeletionTimestamp.Time
+	if pod.DeletionGracePeriodSeconds != nil {
+		evictedAt = evictedAt.Add(-time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+	}
+	return now.Sub(evictedAt) > options.PodEvictionTimeout.Duration
+}
+
+// ForceDeletionDisabled returns whether the unhealthy pod policy stops pods being forcibly deleted from the node
+// for any reason, including the drainBlockedTimeout and podEvictionTimeout
+func ForceDeletionDisabled(policy *atlassianv1.UnhealthyPodPolicy) bool {
+	return policy != nil && policy.Mode == atlassianv1.UnhealthyPodPolicyNever
+}
+
+// unhealthyPodForceDeleter returns whether pods refusing eviction can be forcibly deleted according to the
+// unhealthy pod policy. Without a policy, pods which have been unhealthy for longer than unhealthyAfter are
+// forcibly deleted.
+func unhealthyPodForceDeleter(policy *atlassianv1.UnhealthyPodPolicy, unhealthyAfter time.Duration, now time.Time) (k8s.ForceDeleteFunc, error) {
+	if policy == nil {
+		return k8s.ForceDeleteLongtermUnhealthy(unhealthyAfter, now), nil
+	}
+
+	if policy.UnhealthyAfter != nil {
+		unhealthyAfter = policy.UnhealthyAfter.Duration
+	}
+
+	switch policy.Mode {
+	case atlassianv1.UnhealthyPodPolicyNever:
+		return nil, nil
+	case atlassianv1.UnhealthyPodPolicyAlways, "":
+		return k8s.ForceDeleteLongtermUnhealthy(unhealthyAfter, now), nil
+	case atlassianv1.UnhealthyPodPolicySelected:
+		// Handled below
+	default:
+		return nil, fmt.Errorf("unknown unhealthy pod policy mode %q", policy.Mode)
+	}
+
+	selector := labels.Nothing()
+	if policy.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(policy.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	namespaces := make(map[string]bool, len(policy.Namespaces))
+	for _, namespace := range policy.Namespaces {
+		namespaces[namespace] = true
+	}
+
+	return func(pod *v1.Pod) bool {
+		if !namespaces[pod.Namespace] && !selector.Matches(labels.Set(pod.Labels)) {
+			return false
+		}
+		return k8s.PodIsLongtermUnhealthy(pod.Status, unhealthyAfter, now)
+	}, nil
+}
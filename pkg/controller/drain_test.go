@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+// buildDrainPod returns a pod labelled app=<app> with the given priority
+func buildDrainPod(name, app string, priority *int32) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{Priority: priority},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func podNames(pods []corev1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestNextDrainWave(t *testing.T) {
+	pods := []corev1.Pod{
+		buildDrainPod("web-1", "web", int32Ptr(100)),
+		buildDrainPod("batch-1", "batch", nil),
+		buildDrainPod("ingress-1", "ingress", int32Ptr(1000)),
+		buildDrainPod("proxy-1", "proxy", int32Ptr(2000)),
+		buildDrainPod("web-2", "web", int32Ptr(100)),
+	}
+
+	ingressLast := []metav1.LabelSelector{
+		{MatchLabels: map[string]string{"app": "ingress"}},
+		{MatchLabels: map[string]string{"app": "proxy"}},
+	}
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		order    *atlassianv1.DrainOrder
+		expected []string
+	}{
+		{
+			"no drain order evicts all pods",
+			pods,
+			nil,
+			[]string{"web-1", "batch-1", "ingress-1", "proxy-1", "web-2"},
+		},
+		{
+			"lowest priority first",
+			pods,
+			&atlassianv1.DrainOrder{ByPriority: true},
+			[]string{"batch-1"},
+		},
+		{
+			"next priority once lower priorities have left",
+			pods[2:],
+			&atlassianv1.DrainOrder{ByPriority: true},
+			[]string{"web-2"},
+		},
+		{
+			"pods not in a wave first",
+			pods,
+			&atlassianv1.DrainOrder{Waves: ingressLast},
+			[]string{"web-1", "batch-1", "web-2"},
+		},
+		{
+			"waves in order",
+			[]corev1.Pod{pods[3], pods[2]},
+			&atlassianv1.DrainOrder{Waves: ingressLast},
+			[]string{"ingress-1"},
+		},
+		{
+			"priority within waves",
+			pods,
+			&atlassianv1.DrainOrder{Waves: ingressLast, ByPriority: true},
+			[]string{"batch-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := nextDrainWave(tt.pods, tt.order)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, podNames(next))
+		})
+	}
+}
+
+func TestNextDrainWaveInvalidSelector(t *testing.T) {
+	order := &atlassianv1.DrainOrder{
+		Waves: []metav1.LabelSelector{
+			{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}},
+		},
+	}
+
+	_, err := nextDrainWave([]corev1.Pod{buildDrainPod("web-1", "web", nil)}, order)
+	assert.Error(t, err)
+}
+
+func TestEvictionDeleteOptions(t *testing.T) {
+	assert.Nil(t, evictionDeleteOptions(nil))
+
+	gracePeriodSeconds := int64(30)
+	deleteOptions := evictionDeleteOptions(&atlassianv1.EvictionOptions{
+		GracePeriodSeconds: &gracePeriodSeconds,
+		PropagationPolicy:  "Foreground",
+	})
+	require.NotNil(t, deleteOptions)
+	assert.Equal(t, &gracePeriodSeconds, deleteOptions.GracePeriodSeconds)
+	require.NotNil(t, deleteOptions.PropagationPolicy)
+	assert.Equal(t, metav1.DeletePropagationForeground, *deleteOptions.PropagationPolicy)
+
+	deleteOptions = evictionDeleteOptions(&atlassianv1.EvictionOptions{})
+	require.NotNil(t, deleteOptions)
+	assert.Nil(t, deleteOptions.GracePeriodSeconds)
+	assert.Nil(t, deleteOptions.PropagationPolicy)
+}
+
+func TestEvictionTimedOut(t *testing.T) {
+	now := time.Unix(960579585, 0)
+	options := &atlassianv1.EvictionOptions{PodEvictionTimeout: &metav1.Duration{Duration: 5 * time.Minute}}
+
+	// terminatingPod returns a pod which was evicted at the given time with a 60 second grace period
+	terminatingPod := func(evictedAt time.Time) corev1.Pod {
+		pod := buildDrainPod("web-1", "web", nil)
+		gracePeriodSeconds := int64(60)
+		deletionTimestamp := metav1.NewTime(evictedAt.Add(time.Minute))
+		pod.DeletionTimestamp = &deletionTimestamp
+		pod.DeletionGracePeriodSeconds = &gracePeriodSeconds
+		return pod
+	}
+
+	assert.False(t, evictionTimedOut(buildDrainPod("web-1", "web", nil), options, now), "pod hasn't been evicted")
+	assert.False(t, evictionTimedOut(terminatingPod(now.Add(-10*time.Minute)), nil, now), "no eviction options")
+	assert.False(t, evictionTimedOut(terminatingPod(now.Add(-10*time.Minute)), &atlassianv1.EvictionOptions{}, now), "no timeout")
+	assert.False(t, evictionTimedOut(terminatingPod(now.Add(-4*time.Minute)), options, now), "within the timeout")
+	assert.True(t, evictionTimedOut(terminatingPod(now.Add(-6*time.Minute)), options, now), "past the timeout")
+}
+
+func TestUnhealthyPodForceDeleter(t *testing.T) {
+	now := time.Unix(960579585, 0)
+
+	// unhealthyPod returns a pod in the namespace which has not been ready for the given duration
+	unhealthyPod := func(name, namespace string, unhealthyFor time.Duration) *corev1.Pod {
+		pod := buildDrainPod(name, name, nil)
+		pod.Namespace = namespace
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(now.Add(-unhealthyFor)),
+		}}
+		return &pod
+	}
+
+	longUnhealthy := unhealthyPod("web", "default", 10*time.Minute)
+	shortUnhealthy := unhealthyPod("web", "default", 2*time.Minute)
+	systemUnhealthy := unhealthyPod("dns", "kube-system", 10*time.Minute)
+
+	t.Run("no policy uses the default threshold", func(t *testing.T) {
+		forceDelete, err := unhealthyPodForceDeleter(nil, 5*time.Minute, now)
+		require.NoError(t, err)
+		assert.True(t, forceDelete(longUnhealthy))
+		assert.False(t, forceDelete(shortUnhealthy))
+	})
+
+	t.Run("always overrides the threshold", func(t *testing.T) {
+		forceDelete, err := unhealthyPodForceDeleter(&atlassianv1.UnhealthyPodPolicy{
+			Mode:           atlassianv1.UnhealthyPodPolicyAlways,
+			UnhealthyAfter: &metav1.Duration{Duration: time.Minute},
+		}, 5*time.Minute, now)
+		require.NoError(t, err)
+		assert.True(t, forceDelete(shortUnhealthy))
+	})
+
+	t.Run("empty mode defaults to always", func(t *testing.T) {
+		forceDelete, err := unhealthyPodForceDeleter(&atlassianv1.UnhealthyPodPolicy{
+			UnhealthyAfter: &metav1.Duration{Duration: time.Minute},
+		}, 5*time.Minute, now)
+		require.NoError(t, err)
+		assert.True(t, forceDelete(shortUnhealthy))
+	})
+
+	t.Run("never", func(t *testing.T) {
+		forceDelete, err := unhealthyPodForceDeleter(&atlassianv1.UnhealthyPodPolicy{
+			Mode: atlassianv1.UnhealthyPodPolicyNever,
+		}, 5*time.Minute, now)
+		require.NoError(t, err)
+		assert.Nil(t, forceDelete)
+	})
+
+	t.Run("selected namespaces", func(t *testing.T) {
+		forceDelete, err := unhealthyPodForceDeleter(&atlassianv1.UnhealthyPodPolicy{
+			Mode:       atlassianv1.UnhealthyPodPolicySelected,
+			Namespaces: []string{"kube-system"},
+		}, 5*time.Minute, now)
+		require.NoError(t, err)
+		assert.True(t, forceDelete(systemUnhealthy))
+		assert.False(t, forceDelete(longUnhealthy))
+	})
+
+	t.Run("selected labels", func(t *testing.T) {
+		forceDelete, err := unhealthyPodForceDeleter(&atlassianv1.UnhealthyPodPolicy{
+			Mode:     atlassianv1.UnhealthyPodPolicySelected,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		}, 5*time.Minute, now)
+		require.NoError(t, err)
+		assert.True(t, forceDelete(longUnhealthy))
+		assert.False(t, forceDelete(shortUnhealthy), "selected pods must still be unhealthy for long enough")
+		assert.False(t, forceDelete(systemUnhealthy))
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		_, err := unhealthyPodForceDeleter(&atlassianv1.UnhealthyPodPolicy{Mode: "Sometimes"}, 5*time.Minute, now)
+		assert.Error(t, err)
+	})
+}
+
+func TestForceDeletionDisabled(t *testing.T) {
+	assert.False(t, ForceDeletionDisabled(nil))
+	assert.False(t, ForceDeletionDisabled(&atlassianv1.UnhealthyPodPolicy{}))
+	assert.False(t, ForceDeletionDisabled(&atlassianv1.UnhealthyPodPolicy{Mode: atlassianv1.UnhealthyPodPolicySelected}))
+	assert.True(t, ForceDeletionDisabled(&atlassianv1.UnhealthyPodPolicy{Mode: atlassianv1.UnhealthyPodPolicyNever}))
+}
@@ -1,19 +1,29 @@
-package transitioner
+package controller
 
 import (
 	"net"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
-// isRetryableError determines if an error should trigger a requeue instead of moving to Healing phase
-// Retryable errors typically include network timeouts and transient AWS service errors
-func isRetryableError(err error) bool {
+// IsRetryableError determines if an error is transient and the operation should be retried instead of failed
+// Retryable errors typically include network timeouts, transient Kubernetes API and AWS service errors
+func IsRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	// Check for transient Kubernetes API errors
+	if apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+
 	// Check for network errors (timeouts, connection refused, etc.)
 	if netErr, ok := err.(net.Error); ok {
 		if netErr.Timeout() {
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect bool
+	}{
+		{"nil", nil, false},
+		{"generic error", errors.New("something went wrong"), false},
+		{"network timeout", errors.New("dial tcp 10.0.0.1:443: i/o timeout"), true},
+		{"aws throttling", awserr.New("Throttling", "Rate exceeded", nil), true},
+		{"aws validation", awserr.New("ValidationError", "bad request", nil), false},
+		{"kube server timeout", apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "evict", 1), true},
+		{"kube service unavailable", apierrors.NewServiceUnavailable("unavailable"), true},
+		{"kube internal error", apierrors.NewInternalError(fmt.Errorf("etcd")), true},
+		{"kube not found", apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "pod"), false},
+		{"kube forbidden", apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "pod", fmt.Errorf("denied")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, IsRetryableError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultRootCAKey is the key of the root CA in a referenced Secret or ConfigMap
+	defaultRootCAKey = "ca.crt"
+
+	// defaultCertificateKey is the key of the certificate in a referenced Secret
+	defaultCertificateKey = corev1.TLSCertKey
+
+	// defaultKeyKey is the key of the private key in a referenced Secret
+	defaultKeyKey = corev1.TLSPrivateKeyKey
+)
+
+// TLSMaterial is the pem encoded tls material configured by a TLSConfig
+type TLSMaterial struct {
+	RootCA      string
+	Certificate string
+	Key         string
+}
+
+// BuildHttpClient builds a http client which contains the root CA and certs configured as environment
+// variables, or in the Secret and ConfigMap referenced in the namespace.
+func (rm *ResourceManager) BuildHttpClient(namespace string, tlsConfig v1.TLSConfig) (*http.Client, error) {
+	config, err := rm.BuildTLSConfig(namespace, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the configured client and add the timeout from the "default" client
+	return &http.Client{
+		Timeout: rm.HttpClient.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: config,
+		},
+	}, nil
+}
+
+// BuildTLSConfig builds a tls config which contains the root CA and certs configured as environment variables,
+// or in the Secret and ConfigMap referenced in the namespace. The tls material is validated after it's resolved.
+func (rm *ResourceManager) BuildTLSConfig(namespace string, tlsConfig v1.TLSConfig) (*tls.Config, error) {
+	material, err := rm.resolveTLSMaterial(namespace, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateTLSMaterial(material); err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{}
+
+	if material.RootCA != "" {
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM([]byte(material.RootCA))
+		config.RootCAs = caCertPool
+	}
+
+	// Both will be either configured or missing
+	if material.Certificate != "" {
+		cert, err := tls.X509KeyPair([]byte(material.Certificate), []byte(material.Key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certs for client: %v", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// TLSConfigured returns true if any of the tls options are set. Protocols other than http use this to
+// decide whether to connect with tls.
+func TLSConfigured(tlsConfig v1.TLSConfig) bool {
+	return tlsConfig.RootCA != "" || tlsConfig.Certificate != "" || tlsConfig.Key != "" ||
+		tlsConfig.SecretRef != nil || tlsConfig.ConfigMapRef != nil
+}
+
+// EnvTLSMaterial returns the tls material held in the environment variables named by the tls config
+func EnvTLSMaterial(tlsConfig v1.TLSConfig) TLSMaterial {
+	return TLSMaterial{
+		RootCA:      os.Getenv(tlsConfig.RootCA),
+		Certificate: os.Getenv(tlsConfig.Certificate),
+		Key:         os.Getenv(tlsConfig.Key),
+	}
+}
+
+// ValidateTLSMaterial returns an error if the tls material is misconfigured
+// There are 3 valid modes:
+// No fields:   no TLS
+// CA only:     TLS
+// All fields:  mTLS
+func ValidateTLSMaterial(material TLSMaterial) error {
+	// Check that either both the the tls cert and private key are added or missing
+	// If they are both added, cyclops will forward them when making requests for mTLS
+	// If they are both missing, they will not be added. No mTLS.
+	if (material.Certificate == "" && material.Key != "") || (material.Certificate != "" && material.Key == "") {
+		return fmt.Errorf("cert or key missing, ensure neither are missing for mTLS")
+	}
+
+	// Check that if the certificate and key and both present, the root CA must also
+	// be present. At this point the certificate and key are either both present or
+	// missing so checking one or the other is the same thing.
+	if material.RootCA == "" && material.Certificate != "" {
+		return fmt.Errorf("the cert and key are both added but the root CA is missing, mTLS will fail")
+	}
+
+	return nil
+}
+
+// resolveTLSMaterial reads the tls material from the environment variables, then the referenced Secret and
+// ConfigMap which take precedence. They are read from the api server each time so rotated material is picked up.
+func (rm *ResourceManager) resolveTLSMaterial(namespace string, tlsConfig v1.TLSConfig) (TLSMaterial, error) {
+	material := EnvTLSMaterial(tlsConfig)
+
+	if ref := tlsConfig.SecretRef; ref != nil {
+		secret, err := rm.RawClient.CoreV1().Secrets(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return material, fmt.Errorf("failed to get tls secret %s/%s: %v", namespace, ref.Name, err)
+		}
+
+		data := make(map[string]string, len(secret.Data))
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+
+		rootCA, err := lookupTLSData(data, ref.RootCAKey, defaultRootCAKey)
+		if err != nil {
+			return material, fmt.Errorf("invalid tls secret %s/%s: %v", namespace, ref.Name, err)
+		}
+
+		certificate, err := lookupTLSData(data, ref.CertificateKey, defaultCertificateKey)
+		if err != nil {
+			return material, fmt.Errorf("invalid tls secret %s/%s: %v", namespace, ref.Name, err)
+		}
+
+		key, err := lookupTLSData(data, ref.KeyKey, defaultKeyKey)
+		if err != nil {
+			return material, fmt.Errorf("invalid tls secret %s/%s: %v", namespace, ref.Name, err)
+		}
+
+		if rootCA != "" {
+			material.RootCA = rootCA
+		}
+
+		// The certificate and key are only ever taken as a pair
+		if certificate != "" || key != "" {
+			material.Certificate = certificate
+			material.Key = key
+		}
+	}
+
+	if ref := tlsConfig.ConfigMapRef; ref != nil {
+		configMap, err := rm.RawClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return material, fmt.Errorf("failed to get tls configmap %s/%s: %v", namespace, ref.Name, err)
+		}
+
+		rootCA, err := lookupTLSData(configMap.Data, ref.RootCAKey, defaultRootCAKey)
+		if err != nil {
+			return material, fmt.Errorf("invalid tls configmap %s/%s: %v", namespace, ref.Name, err)
+		}
+
+		if rootCA != "" {
+			material.RootCA = rootCA
+		}
+	}
+
+	return material, nil
+}
+
+// lookupTLSData returns the value of the key in the data. A key which is configured must be present, while the
+// default key is optional.
+func lookupTLSData(data map[string]string, key, defaultKey string) (string, error) {
+	if key == "" {
+		return data[defaultKey], nil
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found", key)
+	}
+
+	return value, nil
+}
+
+// RenderNodeEndpoint renders an endpoint, replacing {{ .NodeIP }} with the node private IP. If this is not
+// present, then the endpoint returned will be identical to the input
+func RenderNodeEndpoint(node v1.CycleNodeRequestNode, endpoint string) (string, error) {
+	tmpl, err := template.New("endpoint").Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure other fields cannot be rendered to the filter
+	tmplStruct := struct {
+		NodeIP string
+	}{
+		NodeIP: node.PrivateIP,
+	}
+
+	var renderedEndpoint strings.Builder
+	if err = tmpl.Execute(&renderedEndpoint, tmplStruct); err != nil {
+		return "", err
+	}
+
+	return renderedEndpoint.String(), nil
+}
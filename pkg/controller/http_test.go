@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+func TestBuildHttpClientTLSReferences(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rootCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	rm := &ResourceManager{
+		HttpClient: http.DefaultClient,
+		Logger:     logr.Discard(),
+		RawClient: fake.NewSimpleClientset(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "root-ca", Namespace: "kube-system"},
+				Data:       map[string]string{"ca.crt": rootCA, "bundle.pem": rootCA},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert-only", Namespace: "kube-system"},
+				Data:       map[string][]byte{"ca.crt": []byte(rootCA), "tls.crt": []byte("crt")},
+			},
+		),
+	}
+
+	// The root CA is read from the ConfigMap with the default key or the configured one
+	for _, ref := range []atlassianv1.TLSConfigMapReference{{Name: "root-ca"}, {Name: "root-ca", RootCAKey: "bundle.pem"}} {
+		tlsConfig := atlassianv1.TLSConfig{ConfigMapRef: &ref}
+		assert.True(t, TLSConfigured(tlsConfig))
+
+		httpClient, err := rm.BuildHttpClient("kube-system", tlsConfig)
+		require.NoError(t, err)
+
+		resp, err := httpClient.Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// A configured key which doesn't exist is an error
+	_, err := rm.BuildHttpClient("kube-system", atlassianv1.TLSConfig{
+		ConfigMapRef: &atlassianv1.TLSConfigMapReference{Name: "root-ca", RootCAKey: "missing.pem"},
+	})
+	assert.Error(t, err)
+
+	// The referenced object must exist in the namespace
+	_, err = rm.BuildHttpClient("default", atlassianv1.TLSConfig{
+		ConfigMapRef: &atlassianv1.TLSConfigMapReference{Name: "root-ca"},
+	})
+	assert.Error(t, err)
+
+	// The material from the Secret is validated, a cert without a key is not valid for mTLS
+	_, err = rm.BuildHttpClient("kube-system", atlassianv1.TLSConfig{
+		SecretRef: &atlassianv1.TLSSecretReference{Name: "cert-only"},
+	})
+	assert.Error(t, err)
+}
+
+func TestValidateTLSMaterial(t *testing.T) {
+	tests := []struct {
+		name     string
+		material TLSMaterial
+		valid    bool
+	}{
+		{"no tls", TLSMaterial{}, true},
+		{"root CA only", TLSMaterial{RootCA: "ca"}, true},
+		{"mTLS", TLSMaterial{RootCA: "ca", Certificate: "crt", Key: "key"}, true},
+		{"cert without key", TLSMaterial{RootCA: "ca", Certificate: "crt"}, false},
+		{"key without cert", TLSMaterial{RootCA: "ca", Key: "key"}, false},
+		{"mTLS without root CA", TLSMaterial{Certificate: "crt", Key: "key"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTLSMaterial(tc.material)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
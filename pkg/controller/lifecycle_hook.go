@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// defaultLifecycleHookTimeout is how long a blocking lifecycle hook is retried if it doesn't set a timeout
+	defaultLifecycleHookTimeout = 10 * time.Minute
+)
+
+// LifecycleHookRequest is the json body sent to a lifecycle hook endpoint
+type LifecycleHookRequest struct {
+	// Hook is the name of the hook being called
+	Hook string `json:"hook"`
+
+	// Event is the point in cycling the node at which the hook is called
+	Event v1.LifecycleHookEvent `json:"event"`
+
+	// CycleNodeRequest is the name of the CycleNodeRequest cycling the node
+	CycleNodeRequest string `json:"cycleNodeRequest"`
+
+	// Namespace is the namespace of the CycleNodeRequest
+	Namespace string `json:"namespace"`
+
+	// Node is the node being cycled
+	Node v1.CycleNodeRequestNode `json:"node"`
+}
+
+// CallLifecycleHooks calls the lifecycle hooks registered for the event, tracking each of them in the statuses.
+// Hooks which have already succeeded aren't called again, and non-blocking hooks are only ever called once with
+// any failure reported as a warning event on obj. It returns the updated statuses and whether all the blocking
+// hooks have succeeded. An error is returned if a blocking hook hasn't succeeded within its timeout.
+func (rm *ResourceManager) CallLifecycleHooks(
+	obj runtime.Object,
+	event v1.LifecycleHookEvent,
+	hooks []v1.LifecycleHook,
+	statuses []v1.LifecycleHookStatus,
+	request LifecycleHookRequest,
+) ([]v1.LifecycleHookStatus, bool, error) {
+	finished := true
+
+	for _, hook := range hooks {
+		if hook.Event != event {
+			continue
+		}
+
+		i := lifecycleHookStatusIndex(statuses, hook.Name)
+		if i < 0 {
+			statuses = append(statuses, v1.LifecycleHookStatus{Name: hook.Name})
+			i = len(statuses) - 1
+		}
+		status := &statuses[i]
+
+		// The hook has already succeeded, or was a notification which has already been sent
+		if status.Succeeded != nil || (!hook.Blocking && status.Attempts > 0) {
+			continue
+		}
+
+		now := metav1.Now()
+		if status.FirstAttempt == nil {
+			status.FirstAttempt = &now
+		}
+		status.Attempts++
+
+		request.Hook = hook.Name
+		request.Event = event
+		err := rm.callLifecycleHook(hook, request)
+		if err == nil {
+			rm.Logger.Info("Lifecycle hook succeeded", "hook", hook.Name, "event", event, "node", request.Node.Name)
+			status.Succeeded = &now
+			status.Message = ""
+			continue
+		}
+
+		status.Message = err.Error()
+		rm.Logger.Error(err, "Lifecycle hook failed", "hook", hook.Name, "event", event, "node", request.Node.Name)
+
+		// Failing to call a non-blocking hook is reported but doesn't stop the node being cycled
+		if !hook.Blocking {
+			rm.LogWarningEvent(obj, "LifecycleHookFailed",
+				"%s lifecycle hook %s failed for node %s: %v", event, hook.Name, request.Node.Name, err)
+			continue
+		}
+
+		if lifecycleHookTimedOut(hook, status) {
+			return statuses, false, fmt.Errorf("%s lifecycle hook %s did not succeed within %s: %v",
+				event, hook.Name, lifecycleHookTimeout(hook), err)
+		}
+
+		finished = false
+	}
+
+	return statuses, finished, nil
+}
+
+// LifecycleHooksStarted returns true if any of the lifecycle hooks registered for the event have been called
+func LifecycleHooksStarted(event v1.LifecycleHookEvent, hooks []v1.LifecycleHook, statuses []v1.LifecycleHookStatus) bool {
+	for _, hook := range hooks {
+		if hook.Event != event {
+			continue
+		}
+
+		if i := lifecycleHookStatusIndex(statuses, hook.Name); i >= 0 && statuses[i].Attempts > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// callLifecycleHook sends a POST request describing the event to the hook endpoint and checks it returned
+// one of the valid status codes
+func (rm *ResourceManager) callLifecycleHook(hook v1.LifecycleHook, request LifecycleHookRequest) error {
+	endpoint, err := RenderNodeEndpoint(request.Node, hook.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to build lifecycle hook endpoint: %v", err)
+	}
+
+	httpClient, err := rm.BuildHttpClient(request.Namespace, hook.TLSConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build http client: %v", err)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	res, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	validStatusCodes := hook.ValidStatusCodes
+	if len(validStatusCodes) == 0 {
+		validStatusCodes = []uint{http.StatusOK}
+	}
+
+	for _, validStatusCode := range validStatusCodes {
+		if uint(resp.StatusCode) == validStatusCode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("got unexpected status code from %s: %d, resp: %s", endpoint, resp.StatusCode, res)
+}
+
+// lifecycleHookStatusIndex returns the index of the status for the named hook, or -1 if it hasn't been called
+func lifecycleHookStatusIndex(statuses []v1.LifecycleHookStatus, name string) int {
+	for i, status := range statuses {
+		if status.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// lifecycleHookTimeout returns how long a blocking lifecycle hook is retried for
+func lifecycleHookTimeout(hook v1.LifecycleHook) time.Duration {
+	if hook.Timeout == nil || hook.Timeout.Duration <= 0 {
+		return defaultLifecycleHookTimeout
+	}
+
+	return hook.Timeout.Duration
+}
+
+// lifecycleHookTimedOut returns true if a blocking lifecycle hook has been retried for longer than its timeout
+func lifecycleHookTimedOut(hook v1.LifecycleHook, status *v1.LifecycleHookStatus) bool {
+	if status.FirstAttempt == nil {
+		return false
+	}
+
+	return time.Since(status.FirstAttempt.Time) > lifecycleHookTimeout(hook)
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+func TestCallLifecycleHooks(t *testing.T) {
+	var requests []LifecycleHookRequest
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var request LifecycleHookRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	rm := &ResourceManager{HttpClient: http.DefaultClient, Logger: logr.Discard()}
+	obj := &atlassianv1.CycleNodeStatus{}
+	request := LifecycleHookRequest{
+		CycleNodeRequest: "cnr-1",
+		Namespace:        "kube-system",
+		Node:             atlassianv1.CycleNodeRequestNode{Name: "node-1", PrivateIP: "10.0.0.1"},
+	}
+	hooks := []atlassianv1.LifecycleHook{
+		{Name: "registry", Event: atlassianv1.LifecycleHookPostDrain, Endpoint: server.URL, Blocking: true},
+		{Name: "cmdb", Event: atlassianv1.LifecycleHookPostDrain, Endpoint: server.URL},
+		{Name: "terminated", Event: atlassianv1.LifecycleHookPostTerminate, Endpoint: server.URL},
+	}
+
+	// Keep retrying the blocking hook while it fails, but only notify the non-blocking hook once
+	statusCode = http.StatusServiceUnavailable
+	statuses, finished, err := rm.CallLifecycleHooks(obj, atlassianv1.LifecycleHookPostDrain, hooks, nil, request)
+	require.NoError(t, err)
+	assert.False(t, finished)
+	require.Len(t, statuses, 2)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "registry", requests[0].Hook)
+	assert.Equal(t, atlassianv1.LifecycleHookPostDrain, requests[0].Event)
+	assert.Equal(t, request.Node, requests[0].Node)
+	assert.NotEmpty(t, statuses[0].Message)
+	assert.Nil(t, statuses[0].Succeeded)
+	assert.True(t, LifecycleHooksStarted(atlassianv1.LifecycleHookPostDrain, hooks, statuses))
+	assert.False(t, LifecycleHooksStarted(atlassianv1.LifecycleHookPostTerminate, hooks, statuses))
+
+	statusCode = http.StatusOK
+	statuses, finished, err = rm.CallLifecycleHooks(obj, atlassianv1.LifecycleHookPostDrain, hooks, statuses, request)
+	require.NoError(t, err)
+	assert.True(t, finished)
+	assert.Len(t, requests, 3)
+	assert.Equal(t, int64(2), statuses[0].Attempts)
+	assert.NotNil(t, statuses[0].Succeeded)
+	assert.Equal(t, int64(1), statuses[1].Attempts)
+	assert.Nil(t, statuses[1].Succeeded)
+
+	// Hooks which have succeeded aren't called again
+	_, finished, err = rm.CallLifecycleHooks(obj, atlassianv1.LifecycleHookPostDrain, hooks, statuses, request)
+	require.NoError(t, err)
+	assert.True(t, finished)
+	assert.Len(t, requests, 3)
+}
+
+func TestCallLifecycleHooksTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	rm := &ResourceManager{HttpClient: http.DefaultClient, Logger: logr.Discard()}
+	hooks := []atlassianv1.LifecycleHook{{
+		Name:     "registry",
+		Event:    atlassianv1.LifecycleHookPreCordon,
+		Endpoint: server.URL,
+		Blocking: true,
+		Timeout:  &metav1.Duration{Duration: time.Minute},
+	}}
+	firstAttempt := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	statuses := []atlassianv1.LifecycleHookStatus{{Name: "registry", Attempts: 3, FirstAttempt: &firstAttempt}}
+
+	_, _, err := rm.CallLifecycleHooks(&atlassianv1.CycleNodeRequest{}, atlassianv1.LifecycleHookPreCordon, hooks, statuses, LifecycleHookRequest{})
+	assert.Error(t, err)
+
+	// 202 is accepted once it's a valid status code
+	hooks[0].ValidStatusCodes = []uint{http.StatusAccepted}
+	statuses, finished, err := rm.CallLifecycleHooks(&atlassianv1.CycleNodeRequest{}, atlassianv1.LifecycleHookPreCordon, hooks, statuses, LifecycleHookRequest{})
+	assert.NoError(t, err)
+	assert.True(t, finished)
+	assert.Equal(t, int64(5), statuses[0].Attempts)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
 	"github.com/atlassian-labs/cyclops/pkg/k8s"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -75,27 +76,74 @@ func (rm *ResourceManager) DeleteNode(name string) error {
 	return err
 }
 
-// DrainPods drains the pods off the named node.
-func (rm *ResourceManager) DrainPods(nodeName string, unhealthyAfter time.Duration) (finished bool, errs []error) {
+// DrainPods drains the pods off the named node using the cycle settings. Returns the pods which refused eviction
+// and the pods which were forcibly deleted instead. If a drain order is given, only the pods in the earliest wave
+// still on the node are evicted. Pods refusing eviction are forcibly deleted according to the unhealthy pod
+// policy, which defaults to forcibly deleting pods which have been unhealthy for longer than unhealthyAfter.
+func (rm *ResourceManager) DrainPods(nodeName string, unhealthyAfter time.Duration, settings atlassianv1.CycleSettings) (finished bool, blocked []v1.Pod, forced []ForceDeletedPod, errs []error) {
 	// Get drainable pods and drain them
 	drainablePods, err := rm.GetDrainablePodsOnNode(nodeName)
 	if err != nil {
-		return false, []error{err}
+		return false, nil, nil, []error{err}
 	}
 
 	// No pods to drain, finish early
 	if len(drainablePods) == 0 {
-		return true, errs
+		return true, nil, nil, errs
 	}
 	rm.Logger.Info("found drainable pods", "numPods", len(drainablePods), "nodeName", nodeName)
 
-	// Convert to pointers
+	// Wait for the earlier waves to leave the node before evicting later waves
+	numDrainablePods := len(drainablePods)
+	drainablePods, err = nextDrainWave(drainablePods, settings.DrainOrder)
+	if err != nil {
+		return false, nil, nil, []error{err}
+	}
+	if len(drainablePods) < numDrainablePods {
+		rm.Logger.Info("draining next wave of pods", "numPods", len(drainablePods), "nodeName", nodeName)
+	}
+
+	now := time.Now()
+	forceDelete, err := unhealthyPodForceDeleter(settings.UnhealthyPodPolicy, unhealthyAfter, now)
+	if err != nil {
+		return false, nil, nil, []error{err}
+	}
+
+	// Convert to pointers, forcibly removing evicted pods which have taken too long to terminate unless the unhealthy
+	// pod policy never allows pods to be forcibly deleted
 	var pods []*v1.Pod
 	for i := range drainablePods {
-		pods = append(pods, &drainablePods[i])
+		pod := &drainablePods[i]
+		if !ForceDeletionDisabled(settings.UnhealthyPodPolicy) && evictionTimedOut(*pod, settings.EvictionOptions, now) {
+			rm.Logger.Info("evicted pod has not terminated within the pod eviction timeout",
+				"podName", pod.Name, "podNamespace", pod.Namespace, "nodeName", nodeName)
+			if err := k8s.ForciblyDeletePod(pod.Name, pod.Namespace, nodeName, rm.RawClient); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+				continue
+			}
+			forced = append(forced, ForceDeletedPod{
+				Pod:     *pod,
+				Reason:  ForceDeletedEvictionTimeout,
+				Message: fmt.Sprintf("pod did not terminate within %s of being evicted", settings.EvictionOptions.PodEvictionTimeout.Duration),
+			})
+			continue
+		}
+		pods = append(pods, pod)
 	}
 
-	return false, k8s.DrainPods(pods, rm.RawClient, unhealthyAfter)
+	blockedPods, forcedPods, evictionErrs := k8s.DrainPods(pods, rm.RawClient, forceDelete, evictionDeleteOptions(settings.EvictionOptions))
+	errs = append(errs, evictionErrs...)
+	for _, pod := range blockedPods {
+		blocked = append(blocked, *pod)
+	}
+	for _, pod := range forcedPods {
+		forced = append(forced, ForceDeletedPod{
+			Pod:     *pod,
+			Reason:  ForceDeletedUnhealthy,
+			Message: "pod refused eviction while unhealthy",
+		})
+	}
+	return false, blocked, forced, errs
 }
 
 func (rm *ResourceManager) AddNodegroupAnnotationToNode(nodeName, nodegroupName string) error {
@@ -5,7 +5,10 @@ import (
 
 	"github.com/atlassian-labs/cyclops/pkg/k8s"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -46,6 +49,23 @@ func (rm *ResourceManager) GetUndisruptablePods(nodeName string) (pods []v1.Pod,
 	return getUndisruptablePods(allPods), nil
 }
 
+// GetUndrainablePods gets a list of running pods on a named node that the Drain method waits for before
+// draining the node.
+func (rm *ResourceManager) GetUndrainablePods(nodeName string) (pods []v1.Pod, err error) {
+	allPods, err := rm.GetPodsOnNode(nodeName)
+	if err != nil {
+		return pods, err
+	}
+
+	for _, pod := range allPods {
+		if k8s.PodCannotBeDrained(&pod) && pod.Status.Phase == v1.PodRunning {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
 // GetDrainablePodsOnNode gets a list of pods on a named node that we can evict or delete from the node.
 func (rm *ResourceManager) GetDrainablePodsOnNode(nodeName string) (pods []v1.Pod, err error) {
 	allPods, err := rm.GetPodsOnNode(nodeName)
@@ -54,10 +74,40 @@ func (rm *ResourceManager) GetDrainablePodsOnNode(nodeName string) (pods []v1.Po
 	}
 
 	for _, pod := range allPods {
-		if !k8s.PodIsDaemonSet(&pod) && !k8s.PodIsStatic(&pod) && pod.Status.Phase == v1.PodRunning {
+		if k8s.PodIsDrainable(&pod) {
 			pods = append(pods, pod)
 		}
 	}
 
 	return pods, nil
 }
+
+// ListPodDisruptionBudgets lists the PodDisruptionBudgets in all namespaces
+func (rm *ResourceManager) ListPodDisruptionBudgets() ([]policyv1.PodDisruptionBudget, error) {
+	var pdbList policyv1.PodDisruptionBudgetList
+	if err := rm.Client.List(context.TODO(), &pdbList); err != nil {
+		return nil, err
+	}
+	return pdbList.Items, nil
+}
+
+// PodOwner returns a reference to the controller of the pod to record events against. Falls back to the pod
+// itself if it isn't controlled by anything.
+func PodOwner(pod *v1.Pod) runtime.Object {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return pod
+	}
+
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      owner.Name,
+			Namespace: pod.Namespace,
+			UID:       owner.UID,
+		},
+	}
+}
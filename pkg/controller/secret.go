@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetSecretValue reads the value of the key referenced in a Secret in the namespace. Secrets are read from the
+// api server rather than the cache so that Cyclops doesn't need to watch every Secret in the cluster.
+func (rm *ResourceManager) GetSecretValue(namespace string, ref v1.SecretKeyReference) (string, error) {
+	secret, err := rm.RawClient.CoreV1().Secrets(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s/%s: %v", namespace, ref.Name, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, namespace, ref.Name)
+	}
+
+	return string(value), nil
+}
+
+// ValidateCheckSecretReferences returns an error if the requests of the checks read header values or bearer tokens
+// from a Secret which isn't one of the allowed Secrets the operator has opted in to
+func ValidateCheckSecretReferences(allowed []string, healthChecks, clusterHealthChecks []v1.HealthCheck, preTerminationChecks []v1.PreTerminationCheck) error {
+	var requests []v1.HTTPRequest
+
+	for _, healthCheck := range append(append([]v1.HealthCheck{}, healthChecks...), clusterHealthChecks...) {
+		requests = append(requests, healthCheck.HTTPRequest)
+	}
+
+	for _, preTerminationCheck := range preTerminationChecks {
+		requests = append(requests, preTerminationCheck.HTTPRequest, preTerminationCheck.HealthCheck.HTTPRequest)
+	}
+
+	for _, request := range requests {
+		for _, ref := range checkRequestSecretReferences(request) {
+			if err := ValidateCheckSecretReference(allowed, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateCheckSecretReference returns an error if the Secret referenced isn't one of the allowed Secrets
+func ValidateCheckSecretReference(allowed []string, ref v1.SecretKeyReference) error {
+	if !slices.Contains(allowed, ref.Name) {
+		return fmt.Errorf("secret %s can't be read by checks, it must be one of %v", ref.Name, allowed)
+	}
+	return nil
+}
+
+// checkRequestSecretReferences returns the Secrets the request reads header values and its bearer token from
+func checkRequestSecretReferences(request v1.HTTPRequest) []v1.SecretKeyReference {
+	var refs []v1.SecretKeyReference
+	for _, header := range request.Headers {
+		if header.ValueFrom != nil {
+			refs = append(refs, *header.ValueFrom)
+		}
+	}
+	if request.BearerTokenSecretRef != nil {
+		refs = append(refs, *request.BearerTokenSecretRef)
+	}
+	return refs
+}
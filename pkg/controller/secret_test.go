@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+func TestValidateCheckSecretReferences(t *testing.T) {
+	allowed := []string{"node-agent-auth"}
+	allowedRef := &v1.SecretKeyReference{Name: "node-agent-auth", Key: "token"}
+	otherRef := &v1.SecretKeyReference{Name: "cloud-credentials", Key: "token"}
+
+	// Checks without Secret references are always valid
+	assert.NoError(t, ValidateCheckSecretReferences(nil, []v1.HealthCheck{{Endpoint: "http://example.com"}}, nil, nil))
+
+	assert.NoError(t, ValidateCheckSecretReferences(allowed,
+		[]v1.HealthCheck{{HTTPRequest: v1.HTTPRequest{BearerTokenSecretRef: allowedRef}}}, nil, nil))
+
+	assert.Error(t, ValidateCheckSecretReferences(allowed,
+		nil, []v1.HealthCheck{{HTTPRequest: v1.HTTPRequest{BearerTokenSecretRef: otherRef}}}, nil))
+
+	assert.Error(t, ValidateCheckSecretReferences(allowed, nil, nil, []v1.PreTerminationCheck{{
+		HTTPRequest: v1.HTTPRequest{Headers: []v1.HTTPHeader{{Name: "X-Api-Key", ValueFrom: otherRef}}},
+	}}))
+
+	assert.Error(t, ValidateCheckSecretReferences(allowed, nil, nil, []v1.PreTerminationCheck{{
+		HealthCheck: v1.HealthCheck{HTTPRequest: v1.HTTPRequest{BearerTokenSecretRef: otherRef}},
+	}}))
+}
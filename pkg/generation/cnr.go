@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/atlassian-labs/cyclops/pkg/controller/cyclenoderequest"
 
@@ -39,13 +40,90 @@ func ApplyCNR(c client.Client, drymode bool, cnr atlassianv1.CycleNodeRequest) e
 	return c.Create(context.TODO(), &cnr, createOptions)
 }
 
+// RetryCNR annotates a Failed cnr so the controller resumes cycling the nodes which have
+// not yet been cycled. Optionally uses dry mode in the patch request
+func RetryCNR(c client.Client, drymode bool, cnr *atlassianv1.CycleNodeRequest) error {
+	if cnr.Status.Phase != atlassianv1.CycleNodeRequestFailed {
+		return fmt.Errorf("cannot retry %s in phase %s, only %s CycleNodeRequests can be retried",
+			cnr.Name, cnr.Status.Phase, atlassianv1.CycleNodeRequestFailed)
+	}
+
+	var dryruns []string
+	if drymode {
+		dryruns = []string{"All"}
+	}
+
+	patch := client.MergeFrom(cnr.DeepCopy())
+	if cnr.Annotations == nil {
+		cnr.Annotations = map[string]string{}
+	}
+	cnr.Annotations[atlassianv1.CycleNodeRequestRetryAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	return c.Patch(context.TODO(), cnr, patch, &client.PatchOptions{DryRun: dryruns})
+}
+
+// PreflightCNR finds the PodDisruptionBudgets which will never allow the nodes selected by the cnr to be drained
+func PreflightCNR(c client.Client, cnr atlassianv1.CycleNodeRequest) ([]k8s.BlockingPDB, error) {
+	// Pods aren't evicted when waiting for them to leave the node
+	if cnr.Spec.CycleSettings.Method == atlassianv1.CycleNodeRequestMethodWait {
+		return nil, nil
+	}
+
+	selector, err := cnr.NodeLabelSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := NewOneShotNodeLister(c).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	namedNodes := make(map[string]bool, len(cnr.Spec.NodeNames))
+	for _, nodeName := range cnr.Spec.NodeNames {
+		namedNodes[nodeName] = true
+	}
+
+	var nodeNames []string
+	for _, node := range nodes {
+		if len(namedNodes) == 0 || namedNodes[node.Name] {
+			nodeNames = append(nodeNames, node.Name)
+		}
+	}
+
+	return k8s.FindBlockingPDBsForNodes(c, nodeNames)
+}
+
 // ValidateCNR determines if a cnr should be applied to the cluster or not, and if so why not
 func ValidateCNR(nodeLister k8s.NodeLister, cnr atlassianv1.CycleNodeRequest) (bool, string) {
+	if ok, reason := ValidateCNRSpec(cnr); !ok {
+		return ok, reason
+	}
+
+	if cnr.Spec.CycleSettings.Concurrency == 0 {
+		return false, concurrencyEqualsZeroMessage
+	}
+
+	// validate against nodes in api
+	selector, err := cnr.NodeLabelSelector()
+	if err != nil {
+		return false, fmt.Sprint("failed to parse node label selectors: ", err.Error())
+	}
+
+	return validateSelectorWithNodes(nodeLister, selector, cnr.Spec.NodeNames)
+}
+
+// ValidateCNRSpec validates the parts of a cnr which don't depend on the nodes in the cluster, so it's also used
+// to validate cnrs as they're admitted. A concurrency of 0 is valid here, the controller defaults it.
+func ValidateCNRSpec(cnr atlassianv1.CycleNodeRequest) (bool, string) {
 	if ok, reason := validateMetadata(cnr.ObjectMeta); !ok {
 		return ok, reason
 	}
 
-	if ok, reason := validateCycleSettings(cnr.Spec.CycleSettings); !ok {
+	if ok, reason := validateCycleSettingsSpec(cnr.Spec.CycleSettings); !ok {
+		return ok, reason
+	}
+
+	if ok, reason := validateMethod(cnr.Spec.CycleSettings.Method); !ok {
 		return ok, reason
 	}
 
@@ -58,13 +136,19 @@ func ValidateCNR(nodeLister k8s.NodeLister, cnr atlassianv1.CycleNodeRequest) (b
 		return false, strings.Join(validationErrors, ",")
 	}
 
-	// validate against nodes in api
-	selector, err := cnr.NodeLabelSelector()
-	if err != nil {
+	if _, err := cnr.NodeLabelSelector(); err != nil {
 		return false, fmt.Sprint("failed to parse node label selectors: ", err.Error())
 	}
 
-	return validateSelectorWithNodes(nodeLister, selector, cnr.Spec.NodeNames)
+	if ok, reason := validateHealthChecks(cnr.Spec.HealthChecks, false); !ok {
+		return ok, reason
+	}
+
+	if ok, reason := validateHealthChecks(cnr.Spec.ClusterHealthChecks, true); !ok {
+		return ok, reason
+	}
+
+	return validatePreTerminationChecks(cnr.Spec.PreTerminationChecks)
 }
 
 // GiveReason adds a reason annotation to the cnr
@@ -97,6 +181,8 @@ func GenerateCNR(nodeGroup atlassianv1.NodeGroup, nodes []string, name, namespac
 		}
 	}
 
+	// The checks only reference the environment variables, Secrets and ConfigMaps holding their tls material,
+	// which is resolved by the controller when the checks are made, so no key material is copied into the CNR
 	return atlassianv1.CycleNodeRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      finalName,
@@ -109,6 +195,7 @@ func GenerateCNR(nodeGroup atlassianv1.NodeGroup, nodes []string, name, namespac
 			NodeNames:                nodes,
 			CycleSettings:            nodeGroup.Spec.CycleSettings,
 			HealthChecks:             nodeGroup.Spec.HealthChecks,
+			ClusterHealthChecks:      nodeGroup.Spec.ClusterHealthChecks,
 			PreTerminationChecks:     nodeGroup.Spec.PreTerminationChecks,
 			SkipInitialHealthChecks:  nodeGroup.Spec.SkipInitialHealthChecks,
 			SkipPreTerminationChecks: nodeGroup.Spec.SkipPreTerminationChecks,
@@ -1,6 +1,7 @@
 package generation
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -8,10 +9,17 @@ import (
 	"github.com/atlassian-labs/cyclops/pkg/test"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestGiveReason(t *testing.T) {
@@ -35,6 +43,50 @@ func TestUseGenerateNameCNR(t *testing.T) {
 	assert.Equal(t, "test-", cnr.GenerateName)
 }
 
+func TestRetryCNR(t *testing.T) {
+	scheme, err := atlassianv1.SchemeBuilder.Build()
+	require.NoError(t, err)
+
+	newCNR := func(name string, phase atlassianv1.CycleNodeRequestPhase) *atlassianv1.CycleNodeRequest {
+		return &atlassianv1.CycleNodeRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+			Status:     atlassianv1.CycleNodeRequestStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		testName  string
+		phase     atlassianv1.CycleNodeRequestPhase
+		drymode   bool
+		expectErr bool
+		annotated bool
+	}{
+		{"failed cnr is annotated", atlassianv1.CycleNodeRequestFailed, false, false, true},
+		{"failed cnr in dry mode is not annotated", atlassianv1.CycleNodeRequestFailed, true, false, false},
+		{"healing cnr cannot be retried", atlassianv1.CycleNodeRequestHealing, false, true, false},
+		{"successful cnr cannot be retried", atlassianv1.CycleNodeRequestSuccessful, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			cnr := newCNR("test", tt.phase)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cnr.DeepCopy()).Build()
+
+			err := RetryCNR(c, tt.drymode, cnr)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			var got atlassianv1.CycleNodeRequest
+			require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(cnr), &got))
+			_, ok := got.Annotations[atlassianv1.CycleNodeRequestRetryAnnotation]
+			assert.Equal(t, tt.annotated, ok)
+		})
+	}
+}
+
 func TestApplyCNR(t *testing.T) {
 	selector, _ := metav1.ParseToLabelSelector("test=me")
 
@@ -230,3 +282,76 @@ func TestValidateCNR(t *testing.T) {
 		})
 	}
 }
+
+func TestPreflightCNR(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, atlassianv1.SchemeBuilder.AddToScheme(scheme))
+
+	newNode := func(name, group string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"group": group}}}
+	}
+	newPod := func(name, nodeName string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": name}},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	maxUnavailable := intstr.FromInt32(0)
+	newPDB := func(app string) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: app, Namespace: "default"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+				MaxUnavailable: &maxUnavailable,
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 1},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newNode("system-1", "system"),
+		newNode("system-2", "system"),
+		newNode("default-1", "default"),
+		newPod("web", "system-1"),
+		newPod("api", "system-2"),
+		newPod("db", "default-1"),
+		newPDB("web"),
+		newPDB("api"),
+		newPDB("db"),
+	).Build()
+
+	newCNR := func(method atlassianv1.CycleNodeRequestMethod, nodeNames ...string) atlassianv1.CycleNodeRequest {
+		return atlassianv1.CycleNodeRequest{
+			Spec: atlassianv1.CycleNodeRequestSpec{
+				Selector:      metav1.LabelSelector{MatchLabels: map[string]string{"group": "system"}},
+				NodeNames:     nodeNames,
+				CycleSettings: atlassianv1.CycleSettings{Method: method},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		cnr      atlassianv1.CycleNodeRequest
+		expected []string
+	}{
+		{"all nodes in the nodegroup", newCNR(atlassianv1.CycleNodeRequestMethodDrain), []string{"api", "web"}},
+		{"named nodes only", newCNR(atlassianv1.CycleNodeRequestMethodDrain, "system-2"), []string{"api"}},
+		{"wait method doesn't evict", newCNR(atlassianv1.CycleNodeRequestMethodWait), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocking, err := PreflightCNR(c, tt.cnr)
+			require.NoError(t, err)
+
+			var names []string
+			for _, pdb := range blocking {
+				names = append(names, pdb.Name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
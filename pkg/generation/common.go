@@ -3,13 +3,17 @@ package generation
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/jsonpath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	atlassianv1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
@@ -24,6 +28,33 @@ const (
 	cnrNameLabelKey                   = "name"
 	cnrReasonAnnotationKey            = "reason"
 	cyclingTimeoutLessThanZeroMessage = "cyclingTimeout cannot be less than 0 seconds"
+	maxFailedNodesInvalidMessage      = "maxFailedNodes must be a non-negative integer or percentage"
+	retryMaxAttemptsInvalidMessage    = "retryPolicy maxAttempts must be at least 1"
+	retryBackoffLessThanZeroMessage   = "retryPolicy backoff cannot be less than 0 seconds"
+	drainOrderInvalidWaveMessage      = "drainOrder waves must be valid label selectors"
+	drainTaintInvalidKeyMessage       = "drainTaint key must be a valid qualified name"
+	drainTaintInvalidValueMessage     = "drainTaint value must be a valid label value"
+	drainTaintInvalidEffectMessage    = "drainTaint effect must be NoSchedule or NoExecute"
+	deregistrationLessThanZeroMessage = "loadBalancerDeregistration drainingDelay and timeout cannot be less than 0 seconds"
+	evictionLessThanZeroMessage       = "evictionOptions gracePeriodSeconds and podEvictionTimeout cannot be less than 0 seconds"
+	evictionPropagationInvalidMessage = "evictionOptions propagationPolicy must be Orphan, Background or Foreground"
+	unhealthyPodPolicyModeMessage     = "unhealthyPodPolicy mode must be Always, Never or Selected"
+	unhealthyPodLessThanZeroMessage   = "unhealthyPodPolicy unhealthyAfter cannot be less than 0 seconds"
+	unhealthyPodPolicySelectorMessage = "unhealthyPodPolicy selector must be a valid label selector"
+	unhealthyPodSelectedEmptyMessage  = "unhealthyPodPolicy Selected mode requires namespaces or a selector"
+	lifecycleHookInvalidMessage       = "lifecycleHooks must have a unique name, an endpoint and an event of PreCordon, PostDrain or PostTerminate"
+	lifecycleHookLessThanZeroMessage  = "lifecycleHooks timeout cannot be less than 0 seconds"
+	methodInvalidMessage              = "method must be Drain or Wait"
+	healthCheckWaitPeriodMessage      = "health checks must have a waitPeriod of at least 0 seconds"
+	healthCheckTypeInvalidMessage     = "health checks must be of type HTTP, GRPC, TCP, Job, NodeReadiness or Prometheus"
+	healthCheckEndpointMissingMessage = "health checks of type HTTP, GRPC, TCP and Prometheus must have an endpoint"
+	healthCheckJobInvalidMessage      = "health checks of type Job must have a job with an image"
+	healthCheckNodeReadinessMessage   = "health checks of type NodeReadiness must have a nodeReadiness requirement"
+	healthCheckPrometheusMessage      = "health checks of type Prometheus must have a query, a numeric threshold and an operator of LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual or Equal"
+	healthCheckRegexInvalidMessage    = "health checks regexMatch must be a valid regular expression"
+	healthCheckJSONPathInvalidMessage = "health checks jsonPathMatch path must be a valid JSONPath"
+	clusterHealthCheckTypeMessage     = "cluster health checks can't be of type Job or NodeReadiness because they aren't performed against a node"
+	preTerminationEndpointMessage     = "preTerminationChecks must have a triggerEndpoint"
 )
 
 // onceShotNodeLister creates a node lister that lists nodes with the controller client.Client as a Get/List
@@ -51,19 +82,231 @@ func (o *onceShotNodeLister) List(selector labels.Selector) ([]*corev1.Node, err
 
 // validateCycleSettings returns if the cycle settings are valid for cycling and why not
 func validateCycleSettings(settings atlassianv1.CycleSettings) (bool, string) {
-	if settings.Concurrency < 0 {
-		return false, concurrencyLessThanZeroMessage
-	}
-
 	if settings.Concurrency == 0 {
 		return false, concurrencyEqualsZeroMessage
 	}
 
+	return validateCycleSettingsSpec(settings)
+}
+
+// validateCycleSettingsSpec returns if the cycle settings are valid and why not. Unlike validateCycleSettings it
+// allows a concurrency of 0, which the controller defaults to the number of nodes being cycled.
+func validateCycleSettingsSpec(settings atlassianv1.CycleSettings) (bool, string) {
+	if settings.Concurrency < 0 {
+		return false, concurrencyLessThanZeroMessage
+	}
+
 	// CyclingTimeout flag is optional, only validate if not empty
 	if settings.CyclingTimeout != nil && settings.CyclingTimeout.Duration < 0*time.Second {
 		return false, cyclingTimeoutLessThanZeroMessage
 	}
 
+	// MaxFailedNodes is optional, only validate if set
+	if settings.MaxFailedNodes != nil {
+		maxFailedNodes, err := intstr.GetScaledValueFromIntOrPercent(settings.MaxFailedNodes, 100, false)
+		if err != nil || maxFailedNodes < 0 {
+			return false, maxFailedNodesInvalidMessage
+		}
+	}
+
+	// RetryPolicy is optional, only validate if set
+	if settings.RetryPolicy != nil {
+		if settings.RetryPolicy.MaxAttempts < 1 {
+			return false, retryMaxAttemptsInvalidMessage
+		}
+
+		if settings.RetryPolicy.Backoff != nil && settings.RetryPolicy.Backoff.Duration < 0*time.Second {
+			return false, retryBackoffLessThanZeroMessage
+		}
+	}
+
+	// DrainOrder is optional, only validate if set
+	if settings.DrainOrder != nil {
+		for i := range settings.DrainOrder.Waves {
+			if _, err := metav1.LabelSelectorAsSelector(&settings.DrainOrder.Waves[i]); err != nil {
+				return false, drainOrderInvalidWaveMessage
+			}
+		}
+	}
+
+	// DrainTaint is optional, only validate if set
+	if settings.DrainTaint != nil {
+		if errStrs := validation.IsQualifiedName(settings.DrainTaint.Key); len(errStrs) > 0 {
+			return false, drainTaintInvalidKeyMessage
+		}
+
+		if errStrs := validation.IsValidLabelValue(settings.DrainTaint.Value); len(errStrs) > 0 {
+			return false, drainTaintInvalidValueMessage
+		}
+
+		switch corev1.TaintEffect(settings.DrainTaint.Effect) {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return false, drainTaintInvalidEffectMessage
+		}
+	}
+
+	// EvictionOptions is optional, only validate if set
+	if options := settings.EvictionOptions; options != nil {
+		if (options.GracePeriodSeconds != nil && *options.GracePeriodSeconds < 0) ||
+			(options.PodEvictionTimeout != nil && options.PodEvictionTimeout.Duration < 0) {
+			return false, evictionLessThanZeroMessage
+		}
+
+		switch metav1.DeletionPropagation(options.PropagationPolicy) {
+		case "", metav1.DeletePropagationOrphan, metav1.DeletePropagationBackground, metav1.DeletePropagationForeground:
+		default:
+			return false, evictionPropagationInvalidMessage
+		}
+	}
+
+	// UnhealthyPodPolicy is optional, only validate if set
+	if policy := settings.UnhealthyPodPolicy; policy != nil {
+		switch policy.Mode {
+		case "", atlassianv1.UnhealthyPodPolicyAlways, atlassianv1.UnhealthyPodPolicyNever, atlassianv1.UnhealthyPodPolicySelected:
+		default:
+			return false, unhealthyPodPolicyModeMessage
+		}
+
+		if policy.UnhealthyAfter != nil && policy.UnhealthyAfter.Duration < 0 {
+			return false, unhealthyPodLessThanZeroMessage
+		}
+
+		if policy.Selector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(policy.Selector); err != nil {
+				return false, unhealthyPodPolicySelectorMessage
+			}
+		}
+
+		if policy.Mode == atlassianv1.UnhealthyPodPolicySelected && len(policy.Namespaces) == 0 && policy.Selector == nil {
+			return false, unhealthyPodSelectedEmptyMessage
+		}
+	}
+
+	// LoadBalancerDeregistration is optional, only validate if set
+	if deregistration := settings.LoadBalancerDeregistration; deregistration != nil {
+		if (deregistration.DrainingDelay != nil && deregistration.DrainingDelay.Duration < 0) ||
+			(deregistration.Timeout != nil && deregistration.Timeout.Duration < 0) {
+			return false, deregistrationLessThanZeroMessage
+		}
+	}
+
+	// LifecycleHooks are optional, only validate if set
+	hookNames := make(map[string]bool, len(settings.LifecycleHooks))
+	for _, hook := range settings.LifecycleHooks {
+		if hook.Name == "" || hook.Endpoint == "" || hookNames[hook.Name] {
+			return false, lifecycleHookInvalidMessage
+		}
+		hookNames[hook.Name] = true
+
+		switch hook.Event {
+		case atlassianv1.LifecycleHookPreCordon, atlassianv1.LifecycleHookPostDrain, atlassianv1.LifecycleHookPostTerminate:
+		default:
+			return false, lifecycleHookInvalidMessage
+		}
+
+		if hook.Timeout != nil && hook.Timeout.Duration < 0 {
+			return false, lifecycleHookLessThanZeroMessage
+		}
+	}
+
+	return true, ""
+}
+
+// validateMethod returns if the cycle method is one the controller knows how to use. An empty method is
+// treated as Drain by the controller.
+func validateMethod(method atlassianv1.CycleNodeRequestMethod) (bool, string) {
+	switch method {
+	case "", atlassianv1.CycleNodeRequestMethodDrain, atlassianv1.CycleNodeRequestMethodWait:
+		return true, ""
+	default:
+		return false, methodInvalidMessage
+	}
+}
+
+// validateHealthChecks returns if the health checks are configured well enough to be performed and why not.
+// Cluster health checks aren't performed against a node, so can't be a type which needs one.
+func validateHealthChecks(healthChecks []atlassianv1.HealthCheck, cluster bool) (bool, string) {
+	for _, healthCheck := range healthChecks {
+		if cluster && (healthCheck.Type == atlassianv1.HealthCheckJob || healthCheck.Type == atlassianv1.HealthCheckNodeReadiness) {
+			return false, clusterHealthCheckTypeMessage
+		}
+
+		if ok, reason := validateHealthCheck(healthCheck); !ok {
+			return ok, reason
+		}
+	}
+
+	return true, ""
+}
+
+// validateHealthCheck returns if a single health check is configured well enough to be performed and why not
+func validateHealthCheck(healthCheck atlassianv1.HealthCheck) (bool, string) {
+	if healthCheck.WaitPeriod == nil || healthCheck.WaitPeriod.Duration < 0 {
+		return false, healthCheckWaitPeriodMessage
+	}
+
+	switch healthCheck.Type {
+	case "", atlassianv1.HealthCheckHTTP, atlassianv1.HealthCheckGRPC, atlassianv1.HealthCheckTCP, atlassianv1.HealthCheckPrometheus:
+		if healthCheck.Endpoint == "" {
+			return false, healthCheckEndpointMissingMessage
+		}
+	case atlassianv1.HealthCheckJob:
+		if healthCheck.Job == nil || healthCheck.Job.Image == "" {
+			return false, healthCheckJobInvalidMessage
+		}
+	case atlassianv1.HealthCheckNodeReadiness:
+		if healthCheck.NodeReadiness == nil {
+			return false, healthCheckNodeReadinessMessage
+		}
+	default:
+		return false, healthCheckTypeInvalidMessage
+	}
+
+	if healthCheck.Type == atlassianv1.HealthCheckPrometheus {
+		prometheus := healthCheck.Prometheus
+		if prometheus == nil || prometheus.Query == "" {
+			return false, healthCheckPrometheusMessage
+		}
+
+		if _, err := strconv.ParseFloat(prometheus.Threshold, 64); err != nil {
+			return false, healthCheckPrometheusMessage
+		}
+
+		switch prometheus.Operator {
+		case atlassianv1.PrometheusLessThan, atlassianv1.PrometheusLessThanOrEqual, atlassianv1.PrometheusGreaterThan,
+			atlassianv1.PrometheusGreaterThanOrEqual, atlassianv1.PrometheusEqual:
+		default:
+			return false, healthCheckPrometheusMessage
+		}
+	}
+
+	if _, err := regexp.Compile(healthCheck.RegexMatch); err != nil {
+		return false, healthCheckRegexInvalidMessage
+	}
+
+	if healthCheck.JSONPathMatch != nil {
+		if err := jsonpath.New("jsonPathMatch").Parse(healthCheck.JSONPathMatch.Path); err != nil {
+			return false, healthCheckJSONPathInvalidMessage
+		}
+	}
+
+	return true, ""
+}
+
+// validatePreTerminationChecks returns if the pre-termination checks are configured well enough to be performed
+// and why not
+func validatePreTerminationChecks(preTerminationChecks []atlassianv1.PreTerminationCheck) (bool, string) {
+	for _, preTerminationCheck := range preTerminationChecks {
+		if preTerminationCheck.Endpoint == "" {
+			return false, preTerminationEndpointMessage
+		}
+
+		if ok, reason := validateHealthCheck(preTerminationCheck.HealthCheck); !ok {
+			return ok, reason
+		}
+	}
+
 	return true, ""
 }
 
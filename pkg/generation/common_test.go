@@ -11,8 +11,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+func intstrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
+
 func TestGetName(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -97,6 +102,8 @@ func TestGetNameExample(t *testing.T) {
 }
 
 func TestValidateCycleSettings(t *testing.T) {
+	gracePeriodSeconds, negativeGracePeriodSeconds := int64(30), int64(-1)
+
 	tests := []struct {
 		name          string
 		cycleSettings atlassianv1.CycleSettings
@@ -157,6 +164,171 @@ func TestValidateCycleSettings(t *testing.T) {
 			false,
 			cyclingTimeoutLessThanZeroMessage,
 		},
+		{
+			"test maxFailedNodes count",
+			atlassianv1.CycleSettings{MaxFailedNodes: intstrPtr(intstr.FromInt32(2)), Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test maxFailedNodes percentage",
+			atlassianv1.CycleSettings{MaxFailedNodes: intstrPtr(intstr.FromString("10%")), Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test maxFailedNodes negative",
+			atlassianv1.CycleSettings{MaxFailedNodes: intstrPtr(intstr.FromInt32(-1)), Concurrency: 1},
+			false,
+			maxFailedNodesInvalidMessage,
+		},
+		{
+			"test maxFailedNodes invalid string",
+			atlassianv1.CycleSettings{MaxFailedNodes: intstrPtr(intstr.FromString("ten")), Concurrency: 1},
+			false,
+			maxFailedNodesInvalidMessage,
+		},
+		{
+			"test retryPolicy valid",
+			atlassianv1.CycleSettings{RetryPolicy: &atlassianv1.RetryPolicy{MaxAttempts: 3, Backoff: &metav1.Duration{Duration: time.Minute}}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test retryPolicy maxAttempts 0",
+			atlassianv1.CycleSettings{RetryPolicy: &atlassianv1.RetryPolicy{MaxAttempts: 0}, Concurrency: 1},
+			false,
+			retryMaxAttemptsInvalidMessage,
+		},
+		{
+			"test retryPolicy backoff negative",
+			atlassianv1.CycleSettings{RetryPolicy: &atlassianv1.RetryPolicy{MaxAttempts: 2, Backoff: &metav1.Duration{Duration: -time.Second}}, Concurrency: 1},
+			false,
+			retryBackoffLessThanZeroMessage,
+		},
+		{
+			"test drainOrder valid waves",
+			atlassianv1.CycleSettings{DrainOrder: &atlassianv1.DrainOrder{Waves: []metav1.LabelSelector{{MatchLabels: map[string]string{"app": "ingress"}}}}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test drainOrder invalid wave",
+			atlassianv1.CycleSettings{DrainOrder: &atlassianv1.DrainOrder{Waves: []metav1.LabelSelector{{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}}}}, Concurrency: 1},
+			false,
+			drainOrderInvalidWaveMessage,
+		},
+		{
+			"test drainTaint valid",
+			atlassianv1.CycleSettings{DrainTaint: &atlassianv1.DrainTaint{Key: "cyclops.atlassian.com/cycling", Value: "true", Effect: "NoExecute"}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test drainTaint empty key",
+			atlassianv1.CycleSettings{DrainTaint: &atlassianv1.DrainTaint{Effect: "NoSchedule"}, Concurrency: 1},
+			false,
+			drainTaintInvalidKeyMessage,
+		},
+		{
+			"test drainTaint invalid value",
+			atlassianv1.CycleSettings{DrainTaint: &atlassianv1.DrainTaint{Key: "cycling", Value: "not valid!", Effect: "NoSchedule"}, Concurrency: 1},
+			false,
+			drainTaintInvalidValueMessage,
+		},
+		{
+			"test drainTaint PreferNoSchedule effect",
+			atlassianv1.CycleSettings{DrainTaint: &atlassianv1.DrainTaint{Key: "cycling", Effect: "PreferNoSchedule"}, Concurrency: 1},
+			false,
+			drainTaintInvalidEffectMessage,
+		},
+		{
+			"test loadBalancerDeregistration valid",
+			atlassianv1.CycleSettings{LoadBalancerDeregistration: &atlassianv1.LoadBalancerDeregistration{DrainingDelay: &metav1.Duration{Duration: time.Minute}, WaitForTargets: true}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test loadBalancerDeregistration negative timeout",
+			atlassianv1.CycleSettings{LoadBalancerDeregistration: &atlassianv1.LoadBalancerDeregistration{Timeout: &metav1.Duration{Duration: -time.Minute}}, Concurrency: 1},
+			false,
+			deregistrationLessThanZeroMessage,
+		},
+		{
+			"test evictionOptions valid",
+			atlassianv1.CycleSettings{EvictionOptions: &atlassianv1.EvictionOptions{GracePeriodSeconds: &gracePeriodSeconds, PropagationPolicy: "Background"}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test evictionOptions negative gracePeriodSeconds",
+			atlassianv1.CycleSettings{EvictionOptions: &atlassianv1.EvictionOptions{GracePeriodSeconds: &negativeGracePeriodSeconds}, Concurrency: 1},
+			false,
+			evictionLessThanZeroMessage,
+		},
+		{
+			"test evictionOptions invalid propagationPolicy",
+			atlassianv1.CycleSettings{EvictionOptions: &atlassianv1.EvictionOptions{PropagationPolicy: "Sideways"}, Concurrency: 1},
+			false,
+			evictionPropagationInvalidMessage,
+		},
+		{
+			"test unhealthyPodPolicy valid",
+			atlassianv1.CycleSettings{UnhealthyPodPolicy: &atlassianv1.UnhealthyPodPolicy{Mode: atlassianv1.UnhealthyPodPolicySelected, Namespaces: []string{"kube-system"}}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test unhealthyPodPolicy invalid mode",
+			atlassianv1.CycleSettings{UnhealthyPodPolicy: &atlassianv1.UnhealthyPodPolicy{Mode: "Sometimes"}, Concurrency: 1},
+			false,
+			unhealthyPodPolicyModeMessage,
+		},
+		{
+			"test unhealthyPodPolicy negative unhealthyAfter",
+			atlassianv1.CycleSettings{UnhealthyPodPolicy: &atlassianv1.UnhealthyPodPolicy{Mode: atlassianv1.UnhealthyPodPolicyAlways, UnhealthyAfter: &metav1.Duration{Duration: -time.Second}}, Concurrency: 1},
+			false,
+			unhealthyPodLessThanZeroMessage,
+		},
+		{
+			"test unhealthyPodPolicy invalid selector",
+			atlassianv1.CycleSettings{UnhealthyPodPolicy: &atlassianv1.UnhealthyPodPolicy{Mode: atlassianv1.UnhealthyPodPolicySelected, Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}}}, Concurrency: 1},
+			false,
+			unhealthyPodPolicySelectorMessage,
+		},
+		{
+			"test unhealthyPodPolicy selected without namespaces or selector",
+			atlassianv1.CycleSettings{UnhealthyPodPolicy: &atlassianv1.UnhealthyPodPolicy{Mode: atlassianv1.UnhealthyPodPolicySelected}, Concurrency: 1},
+			false,
+			unhealthyPodSelectedEmptyMessage,
+		},
+		{
+			"test lifecycleHooks valid",
+			atlassianv1.CycleSettings{LifecycleHooks: []atlassianv1.LifecycleHook{{Name: "registry", Event: atlassianv1.LifecycleHookPreCordon, Endpoint: "http://registry"}}, Concurrency: 1},
+			true,
+			"",
+		},
+		{
+			"test lifecycleHooks invalid event",
+			atlassianv1.CycleSettings{LifecycleHooks: []atlassianv1.LifecycleHook{{Name: "registry", Event: "PreDrain", Endpoint: "http://registry"}}, Concurrency: 1},
+			false,
+			lifecycleHookInvalidMessage,
+		},
+		{
+			"test lifecycleHooks duplicate names",
+			atlassianv1.CycleSettings{LifecycleHooks: []atlassianv1.LifecycleHook{
+				{Name: "registry", Event: atlassianv1.LifecycleHookPreCordon, Endpoint: "http://registry"},
+				{Name: "registry", Event: atlassianv1.LifecycleHookPostTerminate, Endpoint: "http://registry"},
+			}, Concurrency: 1},
+			false,
+			lifecycleHookInvalidMessage,
+		},
+		{
+			"test lifecycleHooks negative timeout",
+			atlassianv1.CycleSettings{LifecycleHooks: []atlassianv1.LifecycleHook{{Name: "registry", Event: atlassianv1.LifecycleHookPostDrain, Endpoint: "http://registry", Timeout: &metav1.Duration{Duration: -time.Second}}}, Concurrency: 1},
+			false,
+			lifecycleHookLessThanZeroMessage,
+		},
 	}
 
 	for _, tt := range tests {
@@ -168,6 +340,118 @@ func TestValidateCycleSettings(t *testing.T) {
 	}
 }
 
+func TestValidateHealthChecks(t *testing.T) {
+	waitPeriod := &metav1.Duration{Duration: time.Minute}
+
+	tests := []struct {
+		name        string
+		healthCheck atlassianv1.HealthCheck
+		cluster     bool
+		ok          bool
+		reason      string
+	}{
+		{
+			"test http health check",
+			atlassianv1.HealthCheck{Endpoint: "http://{{ .NodeIP }}:8080/ready", WaitPeriod: waitPeriod},
+			false,
+			true,
+			"",
+		},
+		{
+			"test missing wait period",
+			atlassianv1.HealthCheck{Endpoint: "http://{{ .NodeIP }}:8080/ready"},
+			false,
+			false,
+			healthCheckWaitPeriodMessage,
+		},
+		{
+			"test missing endpoint",
+			atlassianv1.HealthCheck{Type: atlassianv1.HealthCheckTCP, WaitPeriod: waitPeriod},
+			false,
+			false,
+			healthCheckEndpointMissingMessage,
+		},
+		{
+			"test unknown type",
+			atlassianv1.HealthCheck{Type: "UDP", Endpoint: "{{ .NodeIP }}:53", WaitPeriod: waitPeriod},
+			false,
+			false,
+			healthCheckTypeInvalidMessage,
+		},
+		{
+			"test job without image",
+			atlassianv1.HealthCheck{Type: atlassianv1.HealthCheckJob, Job: &atlassianv1.JobHealthCheck{}, WaitPeriod: waitPeriod},
+			false,
+			false,
+			healthCheckJobInvalidMessage,
+		},
+		{
+			"test node readiness without requirements",
+			atlassianv1.HealthCheck{Type: atlassianv1.HealthCheckNodeReadiness, WaitPeriod: waitPeriod},
+			false,
+			false,
+			healthCheckNodeReadinessMessage,
+		},
+		{
+			"test prometheus",
+			atlassianv1.HealthCheck{
+				Type:       atlassianv1.HealthCheckPrometheus,
+				Endpoint:   "http://prometheus:9090",
+				Prometheus: &atlassianv1.PrometheusHealthCheck{Query: "up", Operator: atlassianv1.PrometheusEqual, Threshold: "1"},
+				WaitPeriod: waitPeriod,
+			},
+			true,
+			true,
+			"",
+		},
+		{
+			"test prometheus threshold not a number",
+			atlassianv1.HealthCheck{
+				Type:       atlassianv1.HealthCheckPrometheus,
+				Endpoint:   "http://prometheus:9090",
+				Prometheus: &atlassianv1.PrometheusHealthCheck{Query: "up", Operator: atlassianv1.PrometheusEqual, Threshold: "one"},
+				WaitPeriod: waitPeriod,
+			},
+			true,
+			false,
+			healthCheckPrometheusMessage,
+		},
+		{
+			"test invalid regex",
+			atlassianv1.HealthCheck{Endpoint: "http://{{ .NodeIP }}:8080/ready", RegexMatch: "(", WaitPeriod: waitPeriod},
+			false,
+			false,
+			healthCheckRegexInvalidMessage,
+		},
+		{
+			"test invalid json path",
+			atlassianv1.HealthCheck{
+				Endpoint:      "http://{{ .NodeIP }}:8080/ready",
+				JSONPathMatch: &atlassianv1.JSONPathAssertion{Path: "{.status"},
+				WaitPeriod:    waitPeriod,
+			},
+			false,
+			false,
+			healthCheckJSONPathInvalidMessage,
+		},
+		{
+			"test cluster health check against a node",
+			atlassianv1.HealthCheck{Type: atlassianv1.HealthCheckJob, Job: &atlassianv1.JobHealthCheck{Image: "busybox"}, WaitPeriod: waitPeriod},
+			true,
+			false,
+			clusterHealthCheckTypeMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := validateHealthChecks([]atlassianv1.HealthCheck{tt.healthCheck}, tt.cluster)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
 func TestValidateMetadata(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -41,12 +41,12 @@ func GetNodeGroups(c client.Client, names ...string) (*atlassianv1.NodeGroupList
 
 // ValidateNodeGroup determines if a nodegroup should be considered for rotation to or not, and if so why not
 func ValidateNodeGroup(nodeLister k8s.NodeLister, nodegroup atlassianv1.NodeGroup) (bool, string) {
-	if ok, reason := validateMetadata(nodegroup.ObjectMeta); !ok {
+	if ok, reason := ValidateNodeGroupSpec(nodegroup); !ok {
 		return ok, reason
 	}
 
-	if ok, reason := validateCycleSettings(nodegroup.Spec.CycleSettings); !ok {
-		return ok, reason
+	if nodegroup.Spec.CycleSettings.Concurrency == 0 {
+		return false, concurrencyEqualsZeroMessage
 	}
 
 	// validate against nodes in api
@@ -57,3 +57,33 @@ func ValidateNodeGroup(nodeLister k8s.NodeLister, nodegroup atlassianv1.NodeGrou
 
 	return validateSelectorWithNodes(nodeLister, selector, nil)
 }
+
+// ValidateNodeGroupSpec validates the parts of a nodegroup which don't depend on the nodes in the cluster, so it's
+// also used to validate nodegroups as they're admitted
+func ValidateNodeGroupSpec(nodegroup atlassianv1.NodeGroup) (bool, string) {
+	if ok, reason := validateMetadata(nodegroup.ObjectMeta); !ok {
+		return ok, reason
+	}
+
+	if ok, reason := validateCycleSettingsSpec(nodegroup.Spec.CycleSettings); !ok {
+		return ok, reason
+	}
+
+	if ok, reason := validateMethod(nodegroup.Spec.CycleSettings.Method); !ok {
+		return ok, reason
+	}
+
+	if _, err := metav1.LabelSelectorAsSelector(&nodegroup.Spec.NodeSelector); err != nil {
+		return false, fmt.Sprint("failed to parse node label selectors: ", err.Error())
+	}
+
+	if ok, reason := validateHealthChecks(nodegroup.Spec.HealthChecks, false); !ok {
+		return ok, reason
+	}
+
+	if ok, reason := validateHealthChecks(nodegroup.Spec.ClusterHealthChecks, true); !ok {
+		return ok, reason
+	}
+
+	return validatePreTerminationChecks(nodegroup.Spec.PreTerminationChecks)
+}
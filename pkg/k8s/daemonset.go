@@ -1,11 +1,147 @@
 package k8s
 
 import (
+	"slices"
+
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/tools/cache"
 )
 
+// daemonSetTolerations are the tolerations the DaemonSet controller adds to every DaemonSet pod
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// daemonSetHostNetworkToleration is added to DaemonSet pods which use the host network
+var daemonSetHostNetworkToleration = corev1.Toleration{
+	Key: corev1.TaintNodeNetworkUnavailable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule,
+}
+
+// nodeSelectorOperators maps node selector operators to their label selector equivalent
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// DaemonSetShouldRunOnNode returns true if the DaemonSet schedules a pod onto the node. This follows the
+// DaemonSet controller: the node must match the pod's node selector and required node affinity, and the pod
+// must tolerate all of the NoSchedule and NoExecute taints on the node.
+func DaemonSetShouldRunOnNode(daemonSet *v1.DaemonSet, node *corev1.Node) bool {
+	podSpec := daemonSet.Spec.Template.Spec
+
+	if !labels.SelectorFromSet(podSpec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+
+	if podSpec.Affinity != nil && podSpec.Affinity.NodeAffinity != nil {
+		required := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if required != nil && !nodeMatchesNodeSelectorTerms(node, required.NodeSelectorTerms) {
+			return false
+		}
+	}
+
+	tolerations := slices.Concat(podSpec.Tolerations, daemonSetTolerations)
+	if podSpec.HostNetwork {
+		tolerations = append(tolerations, daemonSetHostNetworkToleration)
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+
+		if !taintTolerated(&taint, tolerations) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nodeMatchesNodeSelectorTerms returns true if the node matches any of the terms
+func nodeMatchesNodeSelectorTerms(node *corev1.Node, terms []corev1.NodeSelectorTerm) bool {
+	for _, term := range terms {
+		// An empty term matches no nodes
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		if nodeMatchesExpressions(node, term.MatchExpressions) && nodeMatchesFields(node, term.MatchFields) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nodeMatchesExpressions returns true if the node labels match all of the requirements
+func nodeMatchesExpressions(node *corev1.Node, requirements []corev1.NodeSelectorRequirement) bool {
+	selector := labels.NewSelector()
+	for _, requirement := range requirements {
+		operator, ok := nodeSelectorOperators[requirement.Operator]
+		if !ok {
+			return false
+		}
+
+		labelRequirement, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil {
+			return false
+		}
+
+		selector = selector.Add(*labelRequirement)
+	}
+
+	return selector.Matches(labels.Set(node.Labels))
+}
+
+// nodeMatchesFields returns true if the node matches all of the field requirements. metadata.name is the only
+// field supported by node affinity.
+func nodeMatchesFields(node *corev1.Node, requirements []corev1.NodeSelectorRequirement) bool {
+	for _, requirement := range requirements {
+		if requirement.Key != "metadata.name" || len(requirement.Values) != 1 {
+			return false
+		}
+
+		switch requirement.Operator {
+		case corev1.NodeSelectorOpIn:
+			if node.Name != requirement.Values[0] {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if node.Name == requirement.Values[0] {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// taintTolerated returns true if any of the tolerations tolerate the taint
+func taintTolerated(taint *corev1.Taint, tolerations []corev1.Toleration) bool {
+	for _, toleration := range tolerations {
+		if toleration.ToleratesTaint(taint) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // DaemonSetLister defines a type that can list DaemonSets with a selector
 type DaemonSetLister interface {
 	List(labels.Selector) ([]*v1.DaemonSet, error)
@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func buildTestDaemonSet(podSpec corev1.PodSpec) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{Spec: podSpec},
+		},
+	}
+}
+
+func requiredNodeAffinity(terms ...corev1.NodeSelectorTerm) *corev1.Affinity {
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		},
+	}
+}
+
+func TestDaemonSetShouldRunOnNode(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				"kubernetes.io/os": "linux",
+				"gpu-count":        "4",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints: []corev1.Taint{
+				{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute},
+				{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule},
+				{Key: "soft", Effect: corev1.TaintEffectPreferNoSchedule},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		podSpec  corev1.PodSpec
+		node     *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "no constraints",
+			expected: true,
+		},
+		{
+			name:     "matching node selector",
+			podSpec:  corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}},
+			expected: true,
+		},
+		{
+			name:     "node selector not matching",
+			podSpec:  corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "windows"}},
+			expected: false,
+		},
+		{
+			name: "matching node affinity",
+			podSpec: corev1.PodSpec{Affinity: requiredNodeAffinity(
+				corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "kubernetes.io/os", Operator: corev1.NodeSelectorOpIn, Values: []string{"windows"}},
+				}},
+				corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "gpu-count", Operator: corev1.NodeSelectorOpGt, Values: []string{"2"}},
+					{Key: "spot", Operator: corev1.NodeSelectorOpDoesNotExist},
+				}},
+			)},
+			expected: true,
+		},
+		{
+			name: "node affinity not matching",
+			podSpec: corev1.PodSpec{Affinity: requiredNodeAffinity(
+				corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "gpu-count", Operator: corev1.NodeSelectorOpLt, Values: []string{"2"}},
+				}},
+			)},
+			expected: false,
+		},
+		{
+			name: "matching node affinity fields",
+			podSpec: corev1.PodSpec{Affinity: requiredNodeAffinity(
+				corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+					{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}},
+				}},
+			)},
+			expected: true,
+		},
+		{
+			name: "node affinity fields not matching",
+			podSpec: corev1.PodSpec{Affinity: requiredNodeAffinity(
+				corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{
+					{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-2"}},
+				}},
+			)},
+			expected: false,
+		},
+		{
+			name:     "empty node affinity term",
+			podSpec:  corev1.PodSpec{Affinity: requiredNodeAffinity(corev1.NodeSelectorTerm{})},
+			expected: false,
+		},
+		{
+			name: "untolerated taint",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "tolerated taint",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+			podSpec: corev1.PodSpec{Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+			}},
+			expected: true,
+		},
+		{
+			name: "network unavailable taint tolerated with host network",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{{Key: corev1.TaintNodeNetworkUnavailable, Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+			podSpec:  corev1.PodSpec{HostNetwork: true},
+			expected: true,
+		},
+		{
+			name: "network unavailable taint not tolerated without host network",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{{Key: corev1.TaintNodeNetworkUnavailable, Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testNode := node
+			if test.node != nil {
+				testNode = test.node
+			}
+
+			assert.Equal(t, test.expected, DaemonSetShouldRunOnNode(buildTestDaemonSet(test.podSpec), testNode))
+		})
+	}
+}
@@ -2,9 +2,9 @@ package k8s
 
 import (
 	"fmt"
-	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -15,19 +15,22 @@ const (
 
 // DrainPods attempts to delete or evict pods so that the node can be terminated.
 // Will prioritise using Evict if the API server supports it.
-// Pods that have been unhealthy for longer than the given duration will be forcibly removed to prevent stalling.
-func DrainPods(pods []*v1.Pod, client kubernetes.Interface, unhealthyAfter time.Duration) []error {
+// Pods refusing eviction which forceDelete allows, e.g. because they have been unhealthy for too long, will be
+// forcibly removed to prevent stalling. The delete options are used for the evictions, nil uses the defaults.
+// Returns the pods which refused eviction, usually because of a PodDisruptionBudget, and the pods which were
+// forcibly removed.
+func DrainPods(pods []*v1.Pod, client kubernetes.Interface, forceDelete ForceDeleteFunc, deleteOptions *metaV1.DeleteOptions) (blocked []*v1.Pod, forced []*v1.Pod, errs []error) {
 	// Determine whether we are able to delete or evict pods
 	apiVersion, err := SupportEviction(client)
 	if err != nil {
-		return []error{err}
+		return nil, nil, []error{err}
 	}
 
 	// If we are able to evict
 	if len(apiVersion) == 0 {
-		return []error{fmt.Errorf("apiVersion does not support pod eviction API")}
+		return nil, nil, []error{fmt.Errorf("apiVersion does not support pod eviction API")}
 	}
-	return EvictPods(pods, apiVersion, client, unhealthyAfter, time.Now())
+	return evictPods(pods, apiVersion, deleteOptions, client, forceDelete)
 }
 
 // SupportEviction uses Discovery API to find out if the API server supports the eviction subresource
@@ -22,6 +22,10 @@ const (
 
 	// ClusterAutoscalerScaleDownDisabledAnnotation prevents Cluster Autoscaler from scaling down a node.
 	ClusterAutoscalerScaleDownDisabledAnnotation = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+
+	// ExcludedFromLoadBalancersAnnotation marks nodes where Cyclops added the label excluding them from
+	// external load balancers.
+	ExcludedFromLoadBalancersAnnotation = "cyclops.atlassian.com/excluded-from-load-balancers"
 )
 
 // CordonNode performs a patch operation on a node to mark it as unschedulable
@@ -57,6 +61,61 @@ func IsCordoned(name string, client kubernetes.Interface) (bool, error) {
 	return node.Spec.Unschedulable, nil
 }
 
+// AddTaintToNode adds a taint to a node, replacing any existing taint with the same key and effect
+func AddTaintToNode(nodeName string, taint v1.Taint, client kubernetes.Interface) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		// NoExecute taints need the time they were added for pods which tolerate them for a period
+		if taint.Effect == v1.TaintEffectNoExecute && taint.TimeAdded == nil {
+			now := metav1.Now()
+			taint.TimeAdded = &now
+		}
+
+		node.Spec.Taints = append(removeTaint(node.Spec.Taints, taint.Key, taint.Effect), taint)
+		_, err = client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// RemoveTaintFromNode removes the taint with the key and effect from a node. Missing nodes or taints are
+// treated as success.
+func RemoveTaintFromNode(nodeName string, key string, effect v1.TaintEffect, client kubernetes.Interface) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		taints := removeTaint(node.Spec.Taints, key, effect)
+		if len(taints) == len(node.Spec.Taints) {
+			return nil
+		}
+
+		node.Spec.Taints = taints
+		_, err = client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// removeTaint returns the taints without the taint with the key and effect
+func removeTaint(taints []v1.Taint, key string, effect v1.TaintEffect) []v1.Taint {
+	filtered := make([]v1.Taint, 0, len(taints))
+	for _, taint := range taints {
+		if taint.Key == key && taint.Effect == effect {
+			continue
+		}
+		filtered = append(filtered, taint)
+	}
+	return filtered
+}
+
 // AddLabelToNode performs a patch operation on a node to add a label to the node
 func AddLabelToNode(nodeName string, labelName string, labelValue string, client kubernetes.Interface) error {
 	return MergePatchNode(nodeName, map[string]interface{}{
@@ -66,6 +125,60 @@ func AddLabelToNode(nodeName string, labelName string, labelValue string, client
 	}, client)
 }
 
+// RemoveLabelFromNode performs a merge patch on a node to remove a label. Removing a
+// label which doesn't exist is a no-op.
+func RemoveLabelFromNode(nodeName string, labelName string, client kubernetes.Interface) error {
+	return MergePatchNode(nodeName, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{labelName: nil},
+		},
+	}, client)
+}
+
+// ExcludeNodeFromLoadBalancers adds the node.kubernetes.io/exclude-from-external-load-balancers label to a node so
+// the service controller removes it from external load balancers. Nodes which already have the label are left
+// alone, otherwise the node is annotated so the label can be removed if the node is returned to service.
+func ExcludeNodeFromLoadBalancers(nodeName string, client kubernetes.Interface) error {
+	node, err := client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if _, ok := node.Labels[v1.LabelNodeExcludeBalancers]; ok {
+		return nil
+	}
+
+	return MergePatchNode(nodeName, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      map[string]string{v1.LabelNodeExcludeBalancers: "true"},
+			"annotations": map[string]string{ExcludedFromLoadBalancersAnnotation: "true"},
+		},
+	}, client)
+}
+
+// IncludeNodeInLoadBalancers removes the label excluding a node from external load balancers if it was added by
+// ExcludeNodeFromLoadBalancers. Missing nodes are treated as success.
+func IncludeNodeInLoadBalancers(nodeName string, client kubernetes.Interface) error {
+	node, err := client.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, ok := node.Annotations[ExcludedFromLoadBalancersAnnotation]; !ok {
+		return nil
+	}
+
+	return MergePatchNode(nodeName, map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      map[string]interface{}{v1.LabelNodeExcludeBalancers: nil},
+			"annotations": map[string]interface{}{ExcludedFromLoadBalancersAnnotation: nil},
+		},
+	}, client)
+}
+
 // AddAnnotationToNode performs a merge patch on a node to add an annotation.
 // A merge patch is used rather than a JSON Patch "add" operation because the
 // latter fails when the node's annotations map is nil (which can happen in
@@ -134,3 +134,111 @@ func TestAddLabelToNode_NodeNotFound(t *testing.T) {
 	err := AddLabelToNode("does-not-exist", "k", "v", client)
 	assert.Error(t, err)
 }
+
+// TestRemoveLabelFromNode verifies that RemoveLabelFromNode removes only the
+// given label and is a no-op when the label doesn't exist.
+func TestRemoveLabelFromNode(t *testing.T) {
+	node, client := newNodeForPatch("test-node",
+		nil,
+		map[string]string{"keep": "value", "cyclops.atlassian.com/terminate": "cnr"},
+	)
+
+	require.NoError(t, RemoveLabelFromNode(node.Name, "cyclops.atlassian.com/terminate", client))
+
+	got, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"keep": "value"}, got.Labels)
+
+	require.NoError(t, RemoveLabelFromNode(node.Name, "missing", client))
+}
+
+// TestAddTaintToNode verifies that AddTaintToNode keeps other taints, replaces a
+// taint with the same key and effect and sets the time added for NoExecute taints.
+func TestAddTaintToNode(t *testing.T) {
+	node, client := newNodeForPatch("test-node", nil, nil)
+	node.Spec.Taints = []corev1.Taint{
+		{Key: "other", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "cyclops.atlassian.com/cycling", Value: "old", Effect: corev1.TaintEffectNoExecute},
+	}
+	_, err := client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = AddTaintToNode(node.Name, corev1.Taint{
+		Key:    "cyclops.atlassian.com/cycling",
+		Value:  "new",
+		Effect: corev1.TaintEffectNoExecute,
+	}, client)
+	require.NoError(t, err)
+
+	got, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, got.Spec.Taints, 2)
+	assert.Equal(t, "other", got.Spec.Taints[0].Key)
+	assert.Equal(t, "new", got.Spec.Taints[1].Value)
+	assert.NotNil(t, got.Spec.Taints[1].TimeAdded)
+}
+
+// TestAddTaintToNode_NodeNotFound verifies that AddTaintToNode returns an error
+// when the node does not exist.
+func TestAddTaintToNode_NodeNotFound(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	err := AddTaintToNode("missing", corev1.Taint{Key: "key", Effect: corev1.TaintEffectNoSchedule}, client)
+	assert.Error(t, err)
+}
+
+// TestRemoveTaintFromNode verifies that RemoveTaintFromNode only removes the taint
+// with the matching key and effect, and treats missing nodes and taints as success.
+func TestRemoveTaintFromNode(t *testing.T) {
+	node, client := newNodeForPatch("test-node", nil, nil)
+	node.Spec.Taints = []corev1.Taint{
+		{Key: "cyclops.atlassian.com/cycling", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "cyclops.atlassian.com/cycling", Effect: corev1.TaintEffectNoExecute},
+	}
+	_, err := client.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, RemoveTaintFromNode(node.Name, "cyclops.atlassian.com/cycling", corev1.TaintEffectNoSchedule, client))
+
+	got, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []corev1.Taint{{Key: "cyclops.atlassian.com/cycling", Effect: corev1.TaintEffectNoExecute}}, got.Spec.Taints)
+
+	assert.NoError(t, RemoveTaintFromNode(node.Name, "missing", corev1.TaintEffectNoSchedule, client))
+	assert.NoError(t, RemoveTaintFromNode("missing", "cyclops.atlassian.com/cycling", corev1.TaintEffectNoSchedule, client))
+}
+
+// TestExcludeNodeFromLoadBalancers verifies that the node is labelled and
+// annotated, and that IncludeNodeInLoadBalancers reverses it.
+func TestExcludeNodeFromLoadBalancers(t *testing.T) {
+	node, client := newNodeForPatch("test-node", nil, map[string]string{"role": "worker"})
+
+	require.NoError(t, ExcludeNodeFromLoadBalancers(node.Name, client))
+
+	got, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", got.Labels[corev1.LabelNodeExcludeBalancers])
+	assert.Equal(t, "true", got.Annotations[ExcludedFromLoadBalancersAnnotation])
+
+	require.NoError(t, IncludeNodeInLoadBalancers(node.Name, client))
+
+	got, err = client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"role": "worker"}, got.Labels)
+	assert.Empty(t, got.Annotations)
+
+	assert.NoError(t, IncludeNodeInLoadBalancers("missing", client))
+}
+
+// TestIncludeNodeInLoadBalancers_NotExcludedByCyclops verifies that a label
+// added by someone else is left on the node.
+func TestIncludeNodeInLoadBalancers_NotExcludedByCyclops(t *testing.T) {
+	node, client := newNodeForPatch("test-node", nil, map[string]string{corev1.LabelNodeExcludeBalancers: ""})
+
+	require.NoError(t, ExcludeNodeFromLoadBalancers(node.Name, client))
+	require.NoError(t, IncludeNodeInLoadBalancers(node.Name, client))
+
+	got, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, got.Labels, corev1.LabelNodeExcludeBalancers)
+	assert.NotContains(t, got.Annotations, ExcludedFromLoadBalancersAnnotation)
+}
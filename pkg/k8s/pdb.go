@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BlockingPDB describes a PodDisruptionBudget which can never allow the pods it covers to be evicted
+type BlockingPDB struct {
+	Namespace string
+	Name      string
+	Reason    string
+
+	// Pods are the names of the pods covered by the PodDisruptionBudget which would be drained
+	Pods []string
+}
+
+// String describes the blocking PodDisruptionBudget and the pods it covers
+func (b BlockingPDB) String() string {
+	return fmt.Sprintf("%s/%s %s, blocking pods %v", b.Namespace, b.Name, b.Reason, b.Pods)
+}
+
+// FindBlockingPDBsForNodes finds the PodDisruptionBudgets which can never be satisfied for the drainable pods
+// on the named nodes. These would block draining the nodes until the pods are forcibly removed.
+func FindBlockingPDBsForNodes(c client.Client, nodeNames []string) ([]BlockingPDB, error) {
+	nodes := make(map[string]bool, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		nodes[nodeName] = true
+	}
+
+	var podList v1.PodList
+	if err := c.List(context.TODO(), &podList); err != nil {
+		return nil, err
+	}
+
+	var pods []v1.Pod
+	for _, pod := range podList.Items {
+		if nodes[pod.Spec.NodeName] && PodIsDrainable(&pod) {
+			pods = append(pods, pod)
+		}
+	}
+
+	if len(pods) == 0 {
+		return nil, nil
+	}
+
+	var pdbList policyv1.PodDisruptionBudgetList
+	if err := c.List(context.TODO(), &pdbList); err != nil {
+		return nil, err
+	}
+
+	return FindBlockingPDBs(pods, pdbList.Items), nil
+}
+
+// FindBlockingPDBs returns the PodDisruptionBudgets covering any of the pods which can never allow a
+// disruption, even when all the pods they cover are healthy. For example when maxUnavailable is 0,
+// minAvailable is equal to the number of replicas, or a single replica workload has minAvailable of 1.
+func FindBlockingPDBs(pods []v1.Pod, pdbs []policyv1.PodDisruptionBudget) []BlockingPDB {
+	var blocking []BlockingPDB
+
+	for _, pdb := range pdbs {
+		reason, ok := pdbNeverAllowsDisruption(pdb)
+		if !ok {
+			continue
+		}
+
+		selector, err := pdbSelector(pdb)
+		if err != nil {
+			continue
+		}
+
+		var coveredPods []string
+		for _, pod := range pods {
+			if pod.Namespace == pdb.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				coveredPods = append(coveredPods, pod.Name)
+			}
+		}
+
+		if len(coveredPods) == 0 {
+			continue
+		}
+
+		sort.Strings(coveredPods)
+		blocking = append(blocking, BlockingPDB{
+			Namespace: pdb.Namespace,
+			Name:      pdb.Name,
+			Reason:    reason,
+			Pods:      coveredPods,
+		})
+	}
+
+	sort.Slice(blocking, func(i, j int) bool {
+		if blocking[i].Namespace != blocking[j].Namespace {
+			return blocking[i].Namespace < blocking[j].Namespace
+		}
+		return blocking[i].Name < blocking[j].Name
+	})
+
+	return blocking
+}
+
+// PodDisruptionBudgetsForPod returns the names of the PodDisruptionBudgets which cover the pod
+func PodDisruptionBudgetsForPod(pod *v1.Pod, pdbs []policyv1.PodDisruptionBudget) []string {
+	var names []string
+	for _, pdb := range pdbs {
+		if pdb.Namespace != pod.Namespace {
+			continue
+		}
+
+		selector, err := pdbSelector(pdb)
+		if err != nil {
+			continue
+		}
+
+		if selector.Matches(labels.Set(pod.Labels)) {
+			names = append(names, pdb.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// pdbSelector returns the label selector of the PodDisruptionBudget. A nil selector matches no pods and an
+// empty selector matches all pods in the namespace.
+func pdbSelector(pdb policyv1.PodDisruptionBudget) (labels.Selector, error) {
+	if pdb.Spec.Selector == nil {
+		return labels.Nothing(), nil
+	}
+	return metaV1.LabelSelectorAsSelector(pdb.Spec.Selector)
+}
+
+// pdbNeverAllowsDisruption returns whether the PodDisruptionBudget requires every pod it expects to be
+// available, so it will never allow an eviction. Returns the reason if so.
+func pdbNeverAllowsDisruption(pdb policyv1.PodDisruptionBudget) (string, bool) {
+	expectedPods := int(pdb.Status.ExpectedPods)
+
+	switch {
+	case pdb.Spec.MaxUnavailable != nil:
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, expectedPods, true)
+		// Percentages can't be scaled until the disruption controller has counted the expected pods
+		if err != nil || maxUnavailable > 0 || (expectedPods == 0 && pdb.Spec.MaxUnavailable.Type == intstr.String) {
+			return "", false
+		}
+		return fmt.Sprintf("has maxUnavailable of %s", pdb.Spec.MaxUnavailable.String()), true
+
+	case pdb.Spec.MinAvailable != nil:
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, expectedPods, true)
+		if err != nil || expectedPods == 0 || minAvailable < expectedPods {
+			return "", false
+		}
+		return fmt.Sprintf("has minAvailable of %s with %d expected pods", pdb.Spec.MinAvailable.String(), expectedPods), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newPDB returns a PodDisruptionBudget selecting app=<app> with the expected pods counted by the disruption controller
+func newPDB(name, app string, minAvailable, maxUnavailable *intstr.IntOrString, expectedPods int32) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: expectedPods},
+	}
+}
+
+// newPDBPod returns a running pod labelled app=<app> on the node
+func newPDBPod(name, app, nodeName string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func intOrStringPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
+
+func TestFindBlockingPDBs(t *testing.T) {
+	pods := []corev1.Pod{newPDBPod("web-1", "web", "node-1")}
+
+	tests := []struct {
+		name     string
+		pdb      policyv1.PodDisruptionBudget
+		blocking bool
+	}{
+		{"maxUnavailable 0", newPDB("pdb", "web", nil, intOrStringPtr(intstr.FromInt32(0)), 3), true},
+		{"maxUnavailable 0%", newPDB("pdb", "web", nil, intOrStringPtr(intstr.FromString("0%")), 3), true},
+		{"maxUnavailable 1", newPDB("pdb", "web", nil, intOrStringPtr(intstr.FromInt32(1)), 3), false},
+		{"maxUnavailable 10% rounds up", newPDB("pdb", "web", nil, intOrStringPtr(intstr.FromString("10%")), 3), false},
+		{"minAvailable equal to replicas", newPDB("pdb", "web", intOrStringPtr(intstr.FromInt32(3)), nil, 3), true},
+		{"minAvailable 100%", newPDB("pdb", "web", intOrStringPtr(intstr.FromString("100%")), nil, 3), true},
+		{"minAvailable less than replicas", newPDB("pdb", "web", intOrStringPtr(intstr.FromInt32(2)), nil, 3), false},
+		{"single replica with minAvailable 1", newPDB("pdb", "web", intOrStringPtr(intstr.FromInt32(1)), nil, 1), true},
+		{"single replica with minAvailable 50%", newPDB("pdb", "web", intOrStringPtr(intstr.FromString("50%")), nil, 1), true},
+		{"expected pods not counted yet", newPDB("pdb", "web", intOrStringPtr(intstr.FromInt32(1)), nil, 0), false},
+		{"pdb doesn't cover the pods", newPDB("pdb", "api", nil, intOrStringPtr(intstr.FromInt32(0)), 3), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocking := FindBlockingPDBs(pods, []policyv1.PodDisruptionBudget{tt.pdb})
+			if !tt.blocking {
+				assert.Empty(t, blocking)
+				return
+			}
+
+			require.Len(t, blocking, 1)
+			assert.Equal(t, "default", blocking[0].Namespace)
+			assert.Equal(t, "pdb", blocking[0].Name)
+			assert.Equal(t, []string{"web-1"}, blocking[0].Pods)
+			assert.NotEmpty(t, blocking[0].Reason)
+		})
+	}
+}
+
+// TestFindBlockingPDBsForNodes verifies only the drainable pods on the named nodes are considered
+func TestFindBlockingPDBsForNodes(t *testing.T) {
+	webPDB := newPDB("web", "web", nil, intOrStringPtr(intstr.FromInt32(0)), 2)
+	apiPDB := newPDB("api", "api", nil, intOrStringPtr(intstr.FromInt32(0)), 1)
+	dsPDB := newPDB("ds", "ds", nil, intOrStringPtr(intstr.FromInt32(0)), 1)
+
+	web1 := newPDBPod("web-1", "web", "node-1")
+	web2 := newPDBPod("web-2", "web", "node-2")
+	api := newPDBPod("api-1", "api", "node-2")
+	ds := newPDBPod("ds-1", "ds", "node-1")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "ds"}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(&webPDB, &apiPDB, &dsPDB, &web1, &web2, &api, &ds).
+		Build()
+
+	blocking, err := FindBlockingPDBsForNodes(c, []string{"node-1"})
+	require.NoError(t, err)
+	require.Len(t, blocking, 1)
+	assert.Equal(t, "web", blocking[0].Name)
+	assert.Equal(t, []string{"web-1"}, blocking[0].Pods)
+
+	blocking, err = FindBlockingPDBsForNodes(c, []string{"node-3"})
+	require.NoError(t, err)
+	assert.Empty(t, blocking)
+}
+
+func TestPodDisruptionBudgetsForPod(t *testing.T) {
+	pod := newPDBPod("web-1", "web", "node-1")
+
+	otherNamespace := newPDB("other-namespace", "web", nil, intOrStringPtr(intstr.FromInt32(1)), 1)
+	otherNamespace.Namespace = "kube-system"
+
+	pdbs := []policyv1.PodDisruptionBudget{
+		newPDB("web-b", "web", nil, intOrStringPtr(intstr.FromInt32(1)), 3),
+		newPDB("web-a", "web", intOrStringPtr(intstr.FromInt32(1)), nil, 3),
+		newPDB("api", "api", nil, intOrStringPtr(intstr.FromInt32(1)), 3),
+		otherNamespace,
+	}
+
+	assert.Equal(t, []string{"web-a", "web-b"}, PodDisruptionBudgetsForPod(&pod, pdbs))
+	assert.Empty(t, PodDisruptionBudgetsForPod(&pod, nil))
+}
@@ -18,6 +18,8 @@ const (
 	podConditionTypeForUnhealthy        = v1.PodReady
 	doNotDisruptAnnotation              = "cyclops.atlassian.com/do-not-disrupt"
 	doNotDisruptAnnotationRequiredValue = "true"
+	doNotDrainAnnotation                = "cyclops.atlassian.com/do-not-drain"
+	doNotDrainAnnotationRequiredValue   = "true"
 )
 
 var log = logf.Log.WithName("k8s.pod.go")
@@ -35,6 +37,16 @@ func ForciblyDeletePod(podName, podNamespace, nodeName string, client kubernetes
 
 // EvictPod evicts a single pod from a Kubernetes node
 func EvictPod(pod *v1.Pod, apiVersion string, client kubernetes.Interface) error {
+	return EvictPodWithOptions(pod, apiVersion, nil, client)
+}
+
+// EvictPodWithOptions evicts a single pod from a Kubernetes node using the delete options, e.g. to override the
+// termination grace period of the pod. Nil delete options use the defaults.
+func EvictPodWithOptions(pod *v1.Pod, apiVersion string, deleteOptions *metaV1.DeleteOptions, client kubernetes.Interface) error {
+	if deleteOptions == nil {
+		deleteOptions = &metaV1.DeleteOptions{}
+	}
+
 	log.Info("Evicting pod", "podName", pod.Name, "podNamespace", pod.Namespace,
 		"nodeName", pod.Spec.NodeName, "apiVersion", apiVersion)
 	return client.CoreV1().Pods(pod.Namespace).Evict(context.TODO(), &v1beta1.Eviction{
@@ -43,40 +55,81 @@ func EvictPod(pod *v1.Pod, apiVersion string, client kubernetes.Interface) error
 			Kind:       evictionKind,
 		},
 		ObjectMeta:    pod.ObjectMeta,
-		DeleteOptions: &metaV1.DeleteOptions{},
+		DeleteOptions: deleteOptions,
 	})
 }
 
+// DeletePod deletes a pod using its termination grace period. Unlike an eviction this bypasses any
+// PodDisruptionBudget covering the pod.
+func DeletePod(pod *v1.Pod, client kubernetes.Interface) error {
+	log.Info("Deleting pod", "podName", pod.Name, "podNamespace", pod.Namespace, "nodeName", pod.Spec.NodeName)
+	return client.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metaV1.DeleteOptions{})
+}
+
+// ForceDeleteFunc decides whether a pod which is refusing eviction can be forcibly deleted from its node instead
+type ForceDeleteFunc func(pod *v1.Pod) bool
+
+// ForceDeleteLongtermUnhealthy returns a ForceDeleteFunc which allows pods which have been unhealthy for longer than
+// unhealthyAfter to be forcibly deleted
+func ForceDeleteLongtermUnhealthy(unhealthyAfter time.Duration, now time.Time) ForceDeleteFunc {
+	return func(pod *v1.Pod) bool {
+		return PodIsLongtermUnhealthy(pod.Status, unhealthyAfter, now)
+	}
+}
+
 // EvictOrForciblyDeletePod tries to evict a pod, and if that fails will then check if it can forcibly remove the pod instead.
 func EvictOrForciblyDeletePod(pod *v1.Pod, apiVersion string, client kubernetes.Interface, unhealthyAfter time.Duration, now time.Time) error {
-	err := EvictPod(pod, apiVersion, client)
+	_, _, err := evictOrForciblyDeletePod(pod, apiVersion, nil, client, ForceDeleteLongtermUnhealthy(unhealthyAfter, now))
+	return err
+}
+
+// evictOrForciblyDeletePod tries to evict a pod, and if that fails will then check if it can forcibly remove the pod
+// instead. Returns whether the pod refused eviction and was left on the node, or was forcibly deleted.
+func evictOrForciblyDeletePod(pod *v1.Pod, apiVersion string, deleteOptions *metaV1.DeleteOptions, client kubernetes.Interface, forceDelete ForceDeleteFunc) (blocked bool, forced bool, err error) {
+	err = EvictPodWithOptions(pod, apiVersion, deleteOptions, client)
 	if err != nil {
-		// If we couldn't drain the pod, double check if it's been unhealthy for too long and if it has then
-		// force it off the node so we can continue.
+		// If we couldn't drain the pod, double check if it's allowed to be forcibly removed, e.g. it has been
+		// unhealthy for too long, and if it is then force it off the node so we can continue.
 		if serr, ok := err.(*errors.StatusError); ok && errors.IsTooManyRequests(serr) {
-			if PodIsLongtermUnhealthy(pod.Status, unhealthyAfter, now) {
-				log.Info("Pod is un-evictable and is unhealthy for longer than the unhealthy threshold",
-					"podName", pod.Name, "podNamespace", pod.Namespace, "nodeName", pod.Spec.NodeName,
-					"unhealthyThreshold", unhealthyAfter)
-				return ForciblyDeletePod(pod.Name, pod.Namespace, pod.Spec.NodeName, client)
+			if forceDelete != nil && forceDelete(pod) {
+				log.Info("Pod is un-evictable and can be forcibly deleted",
+					"podName", pod.Name, "podNamespace", pod.Namespace, "nodeName", pod.Spec.NodeName)
+				if err := ForciblyDeletePod(pod.Name, pod.Namespace, pod.Spec.NodeName, client); err != nil {
+					return false, false, err
+				}
+				return false, true, nil
 			}
-		} else {
-			return err
+			return true, false, nil
 		}
+		return false, false, err
 	}
-	return nil
+	return false, false, nil
 }
 
 // EvictPods evicts multiple pods from a Kubernetes node. Forcibly removes a pod if it is old and unhealthy and
 // stopping the eviction as a result.
 func EvictPods(pods []*v1.Pod, apiVersion string, client kubernetes.Interface, unhealthyAfter time.Duration, now time.Time) (evictionErrors []error) {
+	_, _, evictionErrors = evictPods(pods, apiVersion, nil, client, ForceDeleteLongtermUnhealthy(unhealthyAfter, now))
+	return evictionErrors
+}
+
+// evictPods evicts multiple pods from a Kubernetes node using the delete options, returning the pods which refused
+// eviction, usually because of a PodDisruptionBudget, and the pods which were forcibly deleted instead, along with
+// any errors.
+func evictPods(pods []*v1.Pod, apiVersion string, deleteOptions *metaV1.DeleteOptions, client kubernetes.Interface, forceDelete ForceDeleteFunc) (blocked []*v1.Pod, forced []*v1.Pod, evictionErrors []error) {
 	for _, pod := range pods {
-		err := EvictOrForciblyDeletePod(pod, apiVersion, client, unhealthyAfter, now)
+		podBlocked, podForced, err := evictOrForciblyDeletePod(pod, apiVersion, deleteOptions, client, forceDelete)
 		if err != nil && !errors.IsNotFound(err) {
 			evictionErrors = append(evictionErrors, err)
 		}
+		if podBlocked {
+			blocked = append(blocked, pod)
+		}
+		if podForced {
+			forced = append(forced, pod)
+		}
 	}
-	return evictionErrors
+	return blocked, forced, evictionErrors
 }
 
 // PodIsDaemonSet returns true if the pod is a daemonset
@@ -102,6 +155,12 @@ func PodCannotBeDisrupted(pod *v1.Pod) bool {
 	return false
 }
 
+// PodCannotBeDrained returns true if the pod has asked for the Drain method to
+// wait for it to finish before draining the node.
+func PodCannotBeDrained(pod *v1.Pod) bool {
+	return pod.Annotations[doNotDrainAnnotation] == doNotDrainAnnotationRequiredValue
+}
+
 // PodIsLongtermUnhealthy returns true if the pod has had container startup or restarting issues
 // for a period of time
 func PodIsLongtermUnhealthy(podStatus v1.PodStatus, unhealthyAfter time.Duration, now time.Time) bool {
@@ -124,6 +183,11 @@ func PodIsStatic(pod *v1.Pod) bool {
 	return ok && configSource == "file"
 }
 
+// PodIsDrainable returns true if the pod will be evicted or deleted when draining its node
+func PodIsDrainable(pod *v1.Pod) bool {
+	return !PodIsDaemonSet(pod) && !PodIsStatic(pod) && pod.Status.Phase == v1.PodRunning
+}
+
 // PodLister defines a type that can list pods with a label selector
 type PodLister interface {
 	List(labels.Selector) ([]*v1.Pod, error)
@@ -55,6 +55,30 @@ func TestEvictPod(t *testing.T) {
 	assert.Equal(t, nil, EvictPod(pod, "core/v1", client))
 }
 
+func TestEvictPodWithOptions(t *testing.T) {
+	pod := test.BuildTestPod(test.PodOpts{
+		Name:      "test",
+		Namespace: "kube-system",
+		NodeName:  "test-node",
+	})
+	client, _ := test.BuildFakeClient(nil, []*corev1.Pod{pod})
+
+	gracePeriodSeconds := int64(10)
+	propagationPolicy := metav1.DeletePropagationForeground
+
+	client.AddReactor("create", "pods", func(action testingCore.Action) (bool, runtime.Object, error) {
+		p := action.(testingCore.CreateAction).GetObject().(*policyv1.Eviction)
+		assert.Equal(t, &gracePeriodSeconds, p.DeleteOptions.GracePeriodSeconds)
+		assert.Equal(t, &propagationPolicy, p.DeleteOptions.PropagationPolicy)
+		return true, nil, nil
+	})
+
+	assert.NoError(t, EvictPodWithOptions(pod, "core/v1", &metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriodSeconds,
+		PropagationPolicy:  &propagationPolicy,
+	}, client))
+}
+
 func TestEvictOrForciblyDeletePod(t *testing.T) {
 	pod := test.BuildTestPod(test.PodOpts{
 		Name:      "test",
@@ -210,3 +234,59 @@ func TestPodIsLongtermUnhealthy(t *testing.T) {
 	assert.Equal(t, true, PodIsLongtermUnhealthy(pod.Status, testUnhealthyAfter, timeNow()),
 		"pod condition is false and last transition time is a long time ago, so it should be unhealthy")
 }
+
+func TestEvictPodsReportsBlockedPods(t *testing.T) {
+	healthy := test.BuildTestPod(test.PodOpts{Name: "healthy", Namespace: "kube-system", NodeName: "test-node"})
+	blocked := test.BuildTestPod(test.PodOpts{Name: "blocked", Namespace: "kube-system", NodeName: "test-node"})
+	unhealthy := test.BuildTestPod(test.PodOpts{Name: "unhealthy", Namespace: "kube-system", NodeName: "test-node"})
+	unhealthy.Status.Conditions = []corev1.PodCondition{podUnhealthyCondition(false, timeNow().Add(-10*time.Minute))}
+	pods := []*corev1.Pod{healthy, blocked, unhealthy}
+
+	client, _ := test.BuildFakeClient(nil, pods)
+	client.AddReactor("create", "pods", func(action testingCore.Action) (bool, runtime.Object, error) {
+		p := action.(testingCore.CreateAction).GetObject().(*policyv1.Eviction)
+		if p.Name == healthy.Name {
+			return true, nil, nil
+		}
+		return true, nil, apiErrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+	})
+
+	// The unhealthy pod is forcibly removed, so only the healthy pod refusing eviction is blocked
+	blockedPods, forcedPods, errs := evictPods(pods, "core/v1", nil, client, ForceDeleteLongtermUnhealthy(testUnhealthyAfter, timeNow()))
+	assert.Empty(t, errs)
+	assert.Equal(t, []*corev1.Pod{blocked}, blockedPods)
+	assert.Equal(t, []*corev1.Pod{unhealthy}, forcedPods)
+
+	// Unhealthy pods are left blocking the drain if they can't be forcibly deleted
+	blockedPods, forcedPods, errs = evictPods(pods, "core/v1", nil, client, func(*corev1.Pod) bool { return false })
+	assert.Empty(t, errs)
+	assert.Equal(t, []*corev1.Pod{blocked, unhealthy}, blockedPods)
+	assert.Empty(t, forcedPods)
+}
+
+func TestDeletePod(t *testing.T) {
+	pod := test.BuildTestPod(test.PodOpts{Name: "test", Namespace: "kube-system", NodeName: "test-node"})
+	client, _ := test.BuildFakeClient(nil, []*corev1.Pod{pod})
+
+	var deleteOptions metav1.DeleteOptions
+	client.PrependReactor("delete", "pods", func(action testingCore.Action) (bool, runtime.Object, error) {
+		deleteOptions = action.(testingCore.DeleteActionImpl).DeleteOptions
+		return false, nil, nil
+	})
+
+	assert.NoError(t, DeletePod(pod, client))
+	assert.Nil(t, deleteOptions.GracePeriodSeconds, "pod should be deleted with its own termination grace period")
+}
+
+func TestPodCannotBeDrained(t *testing.T) {
+	pod := test.BuildTestPod(test.PodOpts{
+		Name: "test",
+	})
+	assert.Equal(t, false, PodCannotBeDrained(pod))
+	pod.Annotations = map[string]string{doNotDisruptAnnotation: "true"}
+	assert.Equal(t, false, PodCannotBeDrained(pod))
+	pod.Annotations[doNotDrainAnnotation] = "false"
+	assert.Equal(t, false, PodCannotBeDrained(pod))
+	pod.Annotations[doNotDrainAnnotation] = "true"
+	assert.Equal(t, true, PodCannotBeDrained(pod))
+}
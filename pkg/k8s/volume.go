@@ -0,0 +1,26 @@
+package k8s
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VolumeAttachmentsForNode returns the names of the CSI VolumeAttachments which still reference the node.
+// These are removed once the attach/detach controller has detached the volumes from the node.
+func VolumeAttachmentsForNode(nodeName string, client kubernetes.Interface) ([]string, error) {
+	volumeAttachments, err := client.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, volumeAttachment := range volumeAttachments.Items {
+		if volumeAttachment.Spec.NodeName == nodeName {
+			names = append(names, volumeAttachment.Name)
+		}
+	}
+
+	return names, nil
+}
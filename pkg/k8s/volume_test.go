@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newVolumeAttachment returns a CSI VolumeAttachment for the node
+func newVolumeAttachment(name, nodeName string) *storagev1.VolumeAttachment {
+	volumeName := name + "-pv"
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "ebs.csi.aws.com",
+			NodeName: nodeName,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &volumeName},
+		},
+	}
+}
+
+func TestVolumeAttachmentsForNode(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newVolumeAttachment("csi-1", "node-1"),
+		newVolumeAttachment("csi-2", "node-2"),
+		newVolumeAttachment("csi-3", "node-1"),
+	)
+
+	volumeAttachments, err := VolumeAttachmentsForNode("node-1", client)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"csi-1", "csi-3"}, volumeAttachments)
+
+	volumeAttachments, err = VolumeAttachmentsForNode("node-3", client)
+	require.NoError(t, err)
+	assert.Empty(t, volumeAttachments)
+}
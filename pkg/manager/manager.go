@@ -18,6 +18,7 @@ import (
 	nodecontroller "github.com/atlassian-labs/cyclops/pkg/controller/node"
 	"github.com/atlassian-labs/cyclops/pkg/metrics"
 	"github.com/atlassian-labs/cyclops/pkg/notifications"
+	"github.com/atlassian-labs/cyclops/pkg/webhook"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -45,10 +46,16 @@ type Dependencies struct {
 
 	// NodeOptions configures the node reconciler.
 	NodeOptions nodecontroller.Options
+
+	// EnableWebhooks registers the admission webhooks for CycleNodeRequests
+	// and NodeGroups with the webhook server of the manager. The server needs
+	// serving certificates, so this is opt-in.
+	EnableWebhooks bool
 }
 
-// Run registers the cyclops scheme, all three controllers, and metrics on
-// the given manager, then starts the manager. It blocks until ctx is
+// Run registers the cyclops scheme, all three controllers, metrics and
+// optionally the admission webhooks on the given manager, then starts the
+// manager. It blocks until ctx is
 // cancelled or the manager exits.
 //
 // This function is the single source of truth for controller wiring. Both
@@ -70,6 +77,11 @@ func Run(ctx context.Context, mgr manager.Manager, deps Dependencies) error {
 	if _, err := nodecontroller.NewReconciler(mgr, deps.Namespace, deps.NodeOptions); err != nil {
 		return fmt.Errorf("registering Node reconciler: %w", err)
 	}
+	if deps.EnableWebhooks {
+		if err := webhook.SetupWithManager(mgr, deps.CNROptions.HealthCheckCredentialSecrets); err != nil {
+			return fmt.Errorf("registering webhooks: %w", err)
+		}
+	}
 
 	return mgr.Start(ctx)
 }
@@ -138,6 +138,18 @@ var (
 		},
 		[]string{"result"},
 	)
+
+	// PodsForceDeleted tracks pods which were forcibly deleted while draining nodes rather than evicted.
+	// Labels:
+	//   namespace: namespace of the pod
+	//   reason:    why the pod was forcibly deleted, e.g. "Unhealthy" or "EvictionTimeout"
+	PodsForceDeleted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%v_pods_force_deleted_total", namespace),
+			Help: "Total number of pods forcibly deleted while draining nodes",
+		},
+		[]string{"namespace", "reason"},
+	)
 )
 
 // Register registers the custom metrics with prometheus
@@ -161,6 +173,7 @@ func Register(client client.Client, logger logr.Logger, namespace string) {
 		NodesWithAnnotation,
 		NodeCleanupAnnotationsRemoved,
 		NodeCleanupReconciles,
+		PodsForceDeleted,
 	)
 
 	go func() {
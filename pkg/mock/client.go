@@ -15,7 +15,9 @@ import (
 	fakerawclient "k8s.io/client-go/kubernetes/fake"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -77,7 +79,14 @@ func NewClient(kubeNodes []*Node, cloudProviderNodes []*Node, extraKubeObjects .
 	kubeObjects := clientNodes
 	kubeObjects = append(kubeObjects, extraKubeObjects...)
 
-	t.K8sClient = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(kubeObjects...).Build()
+	t.K8sClient = fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(kubeObjects...).
+		// Mirrors the indexer set up by the CycleNodeStatus controller to list the pods on a node
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(object client.Object) []string {
+			return []string{object.(*corev1.Pod).Spec.NodeName}
+		}).
+		Build()
 	t.RawClient = fakerawclient.NewSimpleClientset(runtimeNodes...)
 
 	cloudProviderInstances := generateFakeInstances(cloudProviderNodes)
@@ -106,6 +115,12 @@ func addCustomSchemes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(v1.SchemeGroupVersion, &v1.NodeGroupList{})
 	scheme.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{})
 	scheme.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.NodeList{})
+	scheme.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Pod{})
+	scheme.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.PodList{})
+	scheme.AddKnownTypes(policyv1.SchemeGroupVersion, &policyv1.PodDisruptionBudget{})
+	scheme.AddKnownTypes(policyv1.SchemeGroupVersion, &policyv1.PodDisruptionBudgetList{})
+	scheme.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.DaemonSet{})
+	scheme.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.DaemonSetList{})
 	return nil
 }
 
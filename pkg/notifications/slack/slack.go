@@ -22,14 +22,30 @@ var (
 	markdownType = "mrkdwn"
 
 	// Color of the attachment bar in the Slack status notification
-	blueColor  = "#3a72f4"
-	greenColor = "#1dd32c"
-	redColor   = "#e52023"
+	blueColor   = "#3a72f4"
+	greenColor  = "#1dd32c"
+	yellowColor = "#f2c744"
+	redColor    = "#e52023"
 
 	// Length of delay required to allow the reply message to enter the thread
 	timeDelay = 500 * time.Millisecond
+
+	// Maximum length of the text in a section field, slack rejects messages with longer fields
+	maxFieldTextLength = 2000
+
+	// Appended to text which has been cut short to fit in a field
+	truncatedSuffix = "\n..."
 )
 
+// truncateText shortens the text to at most length bytes so that it fits in a field
+func truncateText(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+
+	return strings.ToValidUTF8(text[:length-len(truncatedSuffix)], "") + truncatedSuffix
+}
+
 // Returns any newly selected nodes to prevent duplicate notifying
 func newSelectedNodeNames(cnr *v1.CycleNodeRequest) []string {
 	newSelectedNodesNames := []string{}
@@ -53,6 +69,8 @@ func (n *notifier) generateThreadMessage(cnr *v1.CycleNodeRequest) slackapi.Atta
 	switch cnr.Status.Phase {
 	case v1.CycleNodeRequestSuccessful:
 		statusColor = greenColor
+	case v1.CycleNodeRequestPartiallySuccessful:
+		statusColor = yellowColor
 	case v1.CycleNodeRequestFailed:
 		statusColor = redColor
 	default:
@@ -121,8 +139,8 @@ func (n *notifier) PhaseTransitioned(cnr *v1.CycleNodeRequest) error {
 		}
 	}
 
-	// If the cycling failed, update the cycle status notification and add the error message from the cycleNodeRequest
-	if cnr.Status.Phase == v1.CycleNodeRequestFailed {
+	// If the cycling failed or skipped nodes, update the cycle status notification and add the message from the cycleNodeRequest
+	if cnr.Status.Phase == v1.CycleNodeRequestFailed || cnr.Status.Phase == v1.CycleNodeRequestPartiallySuccessful {
 		message := n.generateThreadMessage(cnr)
 
 		if cnr.Status.Message != "" {
@@ -131,6 +149,17 @@ func (n *notifier) PhaseTransitioned(cnr *v1.CycleNodeRequest) error {
 			}...)
 		}
 
+		// Explain which health checks never passed on the new nodes, if that's what failed the cycling
+		if pendingHealthChecks := cnr.PendingHealthChecks(); len(pendingHealthChecks) > 0 {
+			message.Blocks.BlockSet = append(message.Blocks.BlockSet, []slackapi.Block{
+				slackapi.NewSectionBlock(nil, []*slackapi.TextBlockObject{
+					slackapi.NewTextBlockObject(markdownType, "Health checks which did not pass", false, false),
+					slackapi.NewTextBlockObject(markdownType, fmt.Sprintf("```%v```",
+						truncateText(strings.Join(pendingHealthChecks, "\n"), maxFieldTextLength-len("``````"))), false, false),
+				}, nil),
+			}...)
+		}
+
 		if _, _, _, err := n.client.UpdateMessage(n.channelID, cnr.Status.ThreadTimestamp, slackapi.MsgOptionAttachments(message)); err != nil {
 			return err
 		}
@@ -177,3 +206,32 @@ func (n *notifier) NodesSelected(cnr *v1.CycleNodeRequest) error {
 	_, _, _, err = n.client.UpdateMessage(n.channelID, cnr.Status.ThreadTimestamp, slackapi.MsgOptionAttachments(n.generateThreadMessage(cnr)))
 	return err
 }
+
+// DrainBlocked pushes a threaded notification showing which pods are refusing eviction from a node
+func (n *notifier) DrainBlocked(cnr *v1.CycleNodeRequest, cns *v1.CycleNodeStatus) error {
+	if cnr.Status.ThreadTimestamp == "" {
+		return fmt.Errorf("threadTimestamp not set in CycleNodeRequest")
+	}
+
+	var blockedPods []string
+	for _, pod := range cns.Status.BlockedPods {
+		line := fmt.Sprintf("%s/%s blocked for %s", pod.Namespace, pod.Name, pod.BlockedFor.Duration)
+		if len(pod.PodDisruptionBudgets) > 0 {
+			line = fmt.Sprintf("%s by PodDisruptionBudget %s", line, strings.Join(pod.PodDisruptionBudgets, ", "))
+		}
+		blockedPods = append(blockedPods, line)
+	}
+
+	messageParameters := slackapi.NewPostMessageParameters()
+	messageParameters.ThreadTimestamp = cnr.Status.ThreadTimestamp
+
+	blocks := []slackapi.Block{
+		slackapi.NewSectionBlock(nil, []*slackapi.TextBlockObject{
+			slackapi.NewTextBlockObject(markdownType, fmt.Sprintf("Pods are refusing eviction from *%s*", cns.Status.CurrentNode.Name), false, false),
+			slackapi.NewTextBlockObject(markdownType, fmt.Sprintf("```%v```", strings.Join(blockedPods, "\n")), false, false),
+		}, nil),
+	}
+
+	_, _, err := n.client.PostMessage(n.channelID, slackapi.MsgOptionPostMessageParameters(messageParameters), slackapi.MsgOptionBlocks(blocks...))
+	return err
+}
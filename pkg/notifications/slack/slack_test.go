@@ -0,0 +1,40 @@
+package slack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_truncateText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		expect string
+	}{
+		{
+			name:   "short text is left alone",
+			text:   "node-1 http://10.0.0.1/ready: 3 attempts",
+			expect: "node-1 http://10.0.0.1/ready: 3 attempts",
+		},
+		{
+			name:   "long text is cut short",
+			text:   strings.Repeat("a", maxFieldTextLength+1),
+			expect: strings.Repeat("a", maxFieldTextLength-len(truncatedSuffix)) + truncatedSuffix,
+		},
+		{
+			name:   "multi-byte characters aren't split",
+			text:   strings.Repeat("é", maxFieldTextLength),
+			expect: strings.Repeat("é", (maxFieldTextLength-len(truncatedSuffix))/2) + truncatedSuffix,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := truncateText(tt.text, maxFieldTextLength)
+			assert.Equal(t, tt.expect, text)
+			assert.LessOrEqual(t, len(text), maxFieldTextLength)
+		})
+	}
+}
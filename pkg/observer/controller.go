@@ -208,8 +208,8 @@ func (c *controller) validNodeGroups() v1.NodeGroupList {
 	return validNodeGroups
 }
 
-// inProgressCNRs lists the CNRs that are not in the phase CycleNodeRequestSuccessful
-// only successful CNRs are considered done. Failed is not done
+// inProgressCNRs lists the CNRs that are not in the phase CycleNodeRequestSuccessful or
+// CycleNodeRequestPartiallySuccessful. Only successful CNRs are considered done. Failed is not done
 func (c *controller) inProgressCNRs() v1.CycleNodeRequestList {
 	// List and check cnrs still in progress
 	options := &client.ListOptions{Namespace: c.Namespace}
@@ -220,7 +220,7 @@ func (c *controller) inProgressCNRs() v1.CycleNodeRequestList {
 
 	var inProgessCNRs v1.CycleNodeRequestList
 	for i, cnr := range allCNRs.Items {
-		if cnr.Status.Phase != v1.CycleNodeRequestSuccessful {
+		if !cnr.IsSuccessful() {
 			inProgessCNRs.Items = append(inProgessCNRs.Items, allCNRs.Items[i])
 		}
 	}
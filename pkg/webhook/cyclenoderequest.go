@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/generation"
+)
+
+// cycleNodeRequestDefaulter defaults the method and valid status codes of CycleNodeRequests as they're created
+type cycleNodeRequestDefaulter struct{}
+
+// Default implements admission.CustomDefaulter
+func (d *cycleNodeRequestDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	cnr, ok := obj.(*v1.CycleNodeRequest)
+	if !ok {
+		return fmt.Errorf("expected a CycleNodeRequest but got %T", obj)
+	}
+
+	defaultCycleSettings(&cnr.Spec.CycleSettings)
+	defaultValidStatusCodes(cnr.Spec.HealthChecks, cnr.Spec.ClusterHealthChecks, cnr.Spec.PreTerminationChecks)
+	return nil
+}
+
+// cycleNodeRequestValidator rejects CycleNodeRequests which can't be cycled or which target the same nodes as
+// another unfinished CycleNodeRequest, and changes to their spec once cycling has begun
+type cycleNodeRequestValidator struct {
+	client            client.Reader
+	credentialSecrets []string
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *cycleNodeRequestValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	cnr, ok := obj.(*v1.CycleNodeRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a CycleNodeRequest but got %T", obj)
+	}
+
+	if err := validateCycleNodeRequest(cnr, v.credentialSecrets); err != nil {
+		return nil, err
+	}
+
+	return nil, v.validateNoConflicts(ctx, cnr)
+}
+
+// ValidateUpdate implements admission.CustomValidator. The controller updates the whole CycleNodeRequest as it
+// progresses, so updates which don't change the spec are always allowed.
+func (v *cycleNodeRequestValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldCNR, ok := oldObj.(*v1.CycleNodeRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a CycleNodeRequest but got %T", oldObj)
+	}
+
+	newCNR, ok := newObj.(*v1.CycleNodeRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected a CycleNodeRequest but got %T", newObj)
+	}
+
+	if !cycleNodeRequestSpecChanged(oldCNR, newCNR) {
+		return nil, nil
+	}
+
+	switch oldCNR.Status.Phase {
+	case v1.CycleNodeRequestUndefined, v1.CycleNodeRequestPending:
+	default:
+		return nil, fmt.Errorf("the spec of a CycleNodeRequest can't be changed once it has left the Pending phase, it is %s", oldCNR.Status.Phase)
+	}
+
+	if err := validateCycleNodeRequest(newCNR, v.credentialSecrets); err != nil {
+		return nil, err
+	}
+
+	return nil, v.validateNoConflicts(ctx, newCNR)
+}
+
+// ValidateDelete implements admission.CustomValidator, CycleNodeRequests can always be deleted
+func (v *cycleNodeRequestValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateCycleNodeRequest returns why the CycleNodeRequest can't be cycled, if it can't be
+func validateCycleNodeRequest(cnr *v1.CycleNodeRequest, credentialSecrets []string) error {
+	if ok, reason := generation.ValidateCNRSpec(*cnr); !ok {
+		return fmt.Errorf("invalid CycleNodeRequest: %s", reason)
+	}
+
+	if err := validateTLS(cnr.Spec.HealthChecks, cnr.Spec.ClusterHealthChecks, cnr.Spec.PreTerminationChecks, cnr.Spec.CycleSettings.LifecycleHooks); err != nil {
+		return fmt.Errorf("invalid CycleNodeRequest: %v", err)
+	}
+
+	if err := controller.ValidateCheckSecretReferences(credentialSecrets, cnr.Spec.HealthChecks, cnr.Spec.ClusterHealthChecks, cnr.Spec.PreTerminationChecks); err != nil {
+		return fmt.Errorf("invalid CycleNodeRequest: %v", err)
+	}
+
+	return nil
+}
+
+// validateNoConflicts rejects the CycleNodeRequest if another unfinished CycleNodeRequest targets any of the same
+// nodes. The controller holds conflicting CycleNodeRequests in the Pending phase as well, this rejects them up front.
+func (v *cycleNodeRequestValidator) validateNoConflicts(ctx context.Context, cnr *v1.CycleNodeRequest) error {
+	conflicts, err := controller.FindConflictingCycleNodeRequests(ctx, v.client, cnr)
+	if err != nil {
+		return fmt.Errorf("unable to check for conflicting CycleNodeRequests: %w", err)
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		descriptions = append(descriptions, conflict.String())
+	}
+
+	return fmt.Errorf("conflicting CycleNodeRequest: other CycleNodeRequests are cycling the same nodes: %s", strings.Join(descriptions, "; "))
+}
+
+// cycleNodeRequestSpecChanged returns whether the spec has been changed, disregarding the changes the controller
+// makes by defaulting the concurrency and valid status codes
+func cycleNodeRequestSpecChanged(oldCNR, newCNR *v1.CycleNodeRequest) bool {
+	oldSpec := oldCNR.Spec.DeepCopy()
+	newSpec := newCNR.Spec.DeepCopy()
+
+	defaultValidStatusCodes(oldSpec.HealthChecks, oldSpec.ClusterHealthChecks, oldSpec.PreTerminationChecks)
+	defaultValidStatusCodes(newSpec.HealthChecks, newSpec.ClusterHealthChecks, newSpec.PreTerminationChecks)
+
+	if oldSpec.CycleSettings.Concurrency == 0 && newSpec.CycleSettings.Concurrency > 0 {
+		oldSpec.CycleSettings.Concurrency = newSpec.CycleSettings.Concurrency
+	}
+
+	return !equality.Semantic.DeepEqual(oldSpec, newSpec)
+}
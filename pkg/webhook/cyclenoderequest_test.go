@@ -0,0 +1,213 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/generation"
+	"github.com/atlassian-labs/cyclops/pkg/mock"
+)
+
+func newCycleNodeRequest() *v1.CycleNodeRequest {
+	return &v1.CycleNodeRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-system", Namespace: "kube-system"},
+		Spec: v1.CycleNodeRequestSpec{
+			NodeGroupName: "system",
+			Selector:      metav1.LabelSelector{MatchLabels: map[string]string{"customer": "kitt"}},
+			HealthChecks: []v1.HealthCheck{{
+				Endpoint:   "http://{{ .NodeIP }}:8080/ready",
+				WaitPeriod: &metav1.Duration{Duration: time.Minute},
+			}},
+		},
+	}
+}
+
+func TestCycleNodeRequestDefaulter(t *testing.T) {
+	defaulter := &cycleNodeRequestDefaulter{}
+
+	// The concurrency is left for the controller to default to the number of nodes being cycled
+	cnr := newCycleNodeRequest()
+	require.NoError(t, defaulter.Default(context.Background(), cnr))
+	assert.Equal(t, v1.CycleNodeRequestMethod(v1.CycleNodeRequestMethodDrain), cnr.Spec.CycleSettings.Method)
+	assert.Equal(t, int64(0), cnr.Spec.CycleSettings.Concurrency)
+	assert.Equal(t, []uint{200}, cnr.Spec.HealthChecks[0].ValidStatusCodes)
+
+	// Configured values are kept
+	cnr = newCycleNodeRequest()
+	cnr.Spec.CycleSettings = v1.CycleSettings{Method: v1.CycleNodeRequestMethodWait, Concurrency: 2}
+	require.NoError(t, defaulter.Default(context.Background(), cnr))
+	assert.Equal(t, v1.CycleNodeRequestMethod(v1.CycleNodeRequestMethodWait), cnr.Spec.CycleSettings.Method)
+	assert.Equal(t, int64(2), cnr.Spec.CycleSettings.Concurrency)
+}
+
+func TestCycleNodeRequestValidatorCreate(t *testing.T) {
+	validator := &cycleNodeRequestValidator{client: mock.NewClient(nil, nil).K8sClient, credentialSecrets: []string{"node-agent-auth"}}
+
+	tests := []struct {
+		name   string
+		modify func(cnr *v1.CycleNodeRequest)
+		valid  bool
+	}{
+		{"valid", func(cnr *v1.CycleNodeRequest) {}, true},
+		{"concurrency left to default", func(cnr *v1.CycleNodeRequest) { cnr.Spec.CycleSettings.Concurrency = 0 }, true},
+		{"negative concurrency", func(cnr *v1.CycleNodeRequest) { cnr.Spec.CycleSettings.Concurrency = -1 }, false},
+		{"unknown method", func(cnr *v1.CycleNodeRequest) { cnr.Spec.CycleSettings.Method = "Delete" }, false},
+		{"invalid selector", func(cnr *v1.CycleNodeRequest) {
+			cnr.Spec.Selector = metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "customer", Operator: "Bad"}}}
+		}, false},
+		{"health check without a wait period", func(cnr *v1.CycleNodeRequest) { cnr.Spec.HealthChecks[0].WaitPeriod = nil }, false},
+		{"cluster health check against a node", func(cnr *v1.CycleNodeRequest) {
+			cnr.Spec.ClusterHealthChecks = []v1.HealthCheck{{
+				Type:          v1.HealthCheckNodeReadiness,
+				NodeReadiness: &v1.NodeReadinessHealthCheck{DaemonSetPods: true},
+				WaitPeriod:    &metav1.Duration{Duration: time.Minute},
+			}}
+		}, false},
+		{"tls certificate without a key", func(cnr *v1.CycleNodeRequest) {
+			t.Setenv("ROOT_CA", "ca")
+			t.Setenv("CERT", "crt")
+			cnr.Spec.HealthChecks[0].TLSConfig = v1.TLSConfig{RootCA: "ROOT_CA", Certificate: "CERT"}
+		}, false},
+		{"allowed credential secret", func(cnr *v1.CycleNodeRequest) {
+			cnr.Spec.HealthChecks[0].BearerTokenSecretRef = &v1.SecretKeyReference{Name: "node-agent-auth", Key: "token"}
+		}, true},
+		{"credential secret not allowed", func(cnr *v1.CycleNodeRequest) {
+			cnr.Spec.HealthChecks[0].Headers = []v1.HTTPHeader{{
+				Name:      "X-Api-Key",
+				ValueFrom: &v1.SecretKeyReference{Name: "cloud-credentials", Key: "api-key"},
+			}}
+		}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cnr := newCycleNodeRequest()
+			cnr.Spec.CycleSettings = v1.CycleSettings{Method: v1.CycleNodeRequestMethodDrain, Concurrency: 1}
+			tc.modify(cnr)
+
+			_, err := validator.ValidateCreate(context.Background(), cnr)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCycleNodeRequestValidatorUpdate(t *testing.T) {
+	validator := &cycleNodeRequestValidator{client: mock.NewClient(nil, nil).K8sClient}
+
+	oldCNR := newCycleNodeRequest()
+	oldCNR.Spec.CycleSettings = v1.CycleSettings{Method: v1.CycleNodeRequestMethodDrain}
+
+	// The spec can be changed while the CycleNodeRequest is Pending
+	newCNR := oldCNR.DeepCopy()
+	newCNR.Spec.CycleSettings.Method = v1.CycleNodeRequestMethodWait
+	_, err := validator.ValidateUpdate(context.Background(), oldCNR, newCNR)
+	assert.NoError(t, err)
+
+	// Invalid changes are rejected while the CycleNodeRequest is Pending
+	newCNR = oldCNR.DeepCopy()
+	newCNR.Spec.CycleSettings.Concurrency = -1
+	_, err = validator.ValidateUpdate(context.Background(), oldCNR, newCNR)
+	assert.Error(t, err)
+
+	oldCNR.Status.Phase = v1.CycleNodeRequestInitialised
+
+	// The controller progressing the CycleNodeRequest and defaulting its spec is allowed
+	newCNR = oldCNR.DeepCopy()
+	newCNR.Status.Phase = v1.CycleNodeRequestScalingUp
+	newCNR.Spec.CycleSettings.Concurrency = 3
+	newCNR.Spec.HealthChecks[0].ValidStatusCodes = []uint{200}
+	_, err = validator.ValidateUpdate(context.Background(), oldCNR, newCNR)
+	assert.NoError(t, err)
+
+	// Changing the spec once cycling has begun isn't
+	newCNR = oldCNR.DeepCopy()
+	newCNR.Spec.NodeNames = []string{"system-node-0"}
+	_, err = validator.ValidateUpdate(context.Background(), oldCNR, newCNR)
+	assert.ErrorContains(t, err, "left the Pending phase")
+}
+
+func TestCycleNodeRequestValidatorConflicts(t *testing.T) {
+	nodes, err := mock.NewNodegroup("system", 3)
+	require.NoError(t, err)
+
+	nodeGroup := *newNodeGroup()
+	nodeGroup.Spec.CycleSettings = v1.CycleSettings{Method: v1.CycleNodeRequestMethodDrain, Concurrency: 1}
+
+	// The observer cycles the whole node group, using a generated name
+	observerCNR := generation.GenerateCNR(nodeGroup, nil, "observer", "kube-system")
+	generation.UseGenerateNameCNR(&observerCNR)
+
+	// The CLI can cycle just the named nodes of the node group
+	cliCNR := generation.GenerateCNR(nodeGroup, []string{nodes[0].Name}, "cli", "kube-system")
+
+	activeCNR := generation.GenerateCNR(nodeGroup, []string{nodes[0].Name, nodes[1].Name}, "active", "kube-system")
+	activeCNR.Status.Phase = v1.CycleNodeRequestScalingUp
+	activeCNR.Status.NodesToTerminate = []v1.CycleNodeRequestNode{{Name: nodes[0].Name}, {Name: nodes[1].Name}}
+
+	successfulCNR := activeCNR.DeepCopy()
+	successfulCNR.Name = "successful"
+	successfulCNR.Status.Phase = v1.CycleNodeRequestSuccessful
+
+	tests := []struct {
+		name     string
+		existing []*v1.CycleNodeRequest
+		cnr      v1.CycleNodeRequest
+		conflict string
+	}{
+		{"observer created without other requests", nil, observerCNR, ""},
+		{"cli created without other requests", nil, cliCNR, ""},
+		{"finished requests don't conflict", []*v1.CycleNodeRequest{successfulCNR}, observerCNR, ""},
+		{"observer created overlapping an active request", []*v1.CycleNodeRequest{&activeCNR}, observerCNR,
+			"active-system (ScalingUp) on nodes system-node-0, system-node-1"},
+		{"cli created overlapping an active request", []*v1.CycleNodeRequest{&activeCNR}, cliCNR,
+			"active-system (ScalingUp) on nodes system-node-0"},
+		{"cli created overlapping a pending observer created request", []*v1.CycleNodeRequest{func() *v1.CycleNodeRequest {
+			cnr := observerCNR.DeepCopy()
+			cnr.Name = "observer-system-abcde"
+			return cnr
+		}()}, cliCNR, "observer-system-abcde (Pending) on nodes system-node-0"},
+		{"cli created for nodes no one else is cycling", []*v1.CycleNodeRequest{&activeCNR}, func() v1.CycleNodeRequest {
+			cnr := cliCNR.DeepCopy()
+			cnr.Spec.NodeNames = []string{nodes[2].Name}
+			return *cnr
+		}(), ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var existing []client.Object
+			for _, cnr := range tc.existing {
+				existing = append(existing, cnr.DeepCopy())
+			}
+
+			validator := &cycleNodeRequestValidator{client: mock.NewClient(nodes, nil, existing...).K8sClient}
+
+			cnr := tc.cnr.DeepCopy()
+			_, err := validator.ValidateCreate(context.Background(), cnr)
+			if tc.conflict == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, "conflicting CycleNodeRequest")
+			assert.ErrorContains(t, err, tc.conflict)
+
+			// Changing the spec of a Pending request to overlap is rejected too
+			old := cnr.DeepCopy()
+			old.Spec.NodeNames = []string{"unknown-node"}
+			_, err = validator.ValidateUpdate(context.Background(), old, cnr)
+			assert.ErrorContains(t, err, tc.conflict)
+		})
+	}
+}
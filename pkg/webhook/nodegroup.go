@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+	"github.com/atlassian-labs/cyclops/pkg/generation"
+)
+
+// nodeGroupDefaulter defaults the method and valid status codes of NodeGroups as they're created
+type nodeGroupDefaulter struct{}
+
+// Default implements admission.CustomDefaulter
+func (d *nodeGroupDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	nodeGroup, ok := obj.(*v1.NodeGroup)
+	if !ok {
+		return fmt.Errorf("expected a NodeGroup but got %T", obj)
+	}
+
+	defaultCycleSettings(&nodeGroup.Spec.CycleSettings)
+	defaultValidStatusCodes(nodeGroup.Spec.HealthChecks, nodeGroup.Spec.ClusterHealthChecks, nodeGroup.Spec.PreTerminationChecks)
+	return nil
+}
+
+// nodeGroupValidator rejects NodeGroups which CycleNodeRequests can't be generated from
+type nodeGroupValidator struct {
+	credentialSecrets []string
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *nodeGroupValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	nodeGroup, ok := obj.(*v1.NodeGroup)
+	if !ok {
+		return nil, fmt.Errorf("expected a NodeGroup but got %T", obj)
+	}
+
+	return nil, validateNodeGroup(nodeGroup, v.credentialSecrets)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *nodeGroupValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	nodeGroup, ok := newObj.(*v1.NodeGroup)
+	if !ok {
+		return nil, fmt.Errorf("expected a NodeGroup but got %T", newObj)
+	}
+
+	return nil, validateNodeGroup(nodeGroup, v.credentialSecrets)
+}
+
+// ValidateDelete implements admission.CustomValidator, NodeGroups can always be deleted
+func (v *nodeGroupValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateNodeGroup returns why CycleNodeRequests can't be generated from the NodeGroup, if they can't be
+func validateNodeGroup(nodeGroup *v1.NodeGroup, credentialSecrets []string) error {
+	if ok, reason := generation.ValidateNodeGroupSpec(*nodeGroup); !ok {
+		return fmt.Errorf("invalid NodeGroup: %s", reason)
+	}
+
+	if err := validateTLS(nodeGroup.Spec.HealthChecks, nodeGroup.Spec.ClusterHealthChecks, nodeGroup.Spec.PreTerminationChecks, nodeGroup.Spec.CycleSettings.LifecycleHooks); err != nil {
+		return fmt.Errorf("invalid NodeGroup: %v", err)
+	}
+
+	if err := controller.ValidateCheckSecretReferences(credentialSecrets, nodeGroup.Spec.HealthChecks, nodeGroup.Spec.ClusterHealthChecks, nodeGroup.Spec.PreTerminationChecks); err != nil {
+		return fmt.Errorf("invalid NodeGroup: %v", err)
+	}
+
+	return nil
+}
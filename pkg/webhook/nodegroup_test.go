@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+)
+
+func newNodeGroup() *v1.NodeGroup {
+	return &v1.NodeGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "system"},
+		Spec: v1.NodeGroupSpec{
+			NodeGroupName: "system",
+			NodeSelector:  metav1.LabelSelector{MatchLabels: map[string]string{"customer": "kitt"}},
+		},
+	}
+}
+
+func TestNodeGroupDefaulter(t *testing.T) {
+	defaulter := &nodeGroupDefaulter{}
+
+	// A concurrency of 0 skips the NodeGroup, so it isn't defaulted
+	nodeGroup := newNodeGroup()
+	require.NoError(t, defaulter.Default(context.Background(), nodeGroup))
+	assert.Equal(t, v1.CycleNodeRequestMethod(v1.CycleNodeRequestMethodDrain), nodeGroup.Spec.CycleSettings.Method)
+	assert.Equal(t, int64(0), nodeGroup.Spec.CycleSettings.Concurrency)
+}
+
+func TestNodeGroupValidator(t *testing.T) {
+	validator := &nodeGroupValidator{}
+
+	nodeGroup := newNodeGroup()
+	_, err := validator.ValidateCreate(context.Background(), nodeGroup)
+	assert.NoError(t, err)
+
+	// Invalid changes are rejected on update as well as create
+	invalid := nodeGroup.DeepCopy()
+	invalid.Spec.PreTerminationChecks = []v1.PreTerminationCheck{{
+		HealthCheck: v1.HealthCheck{Endpoint: "http://{{ .NodeIP }}:8080/drained", WaitPeriod: &metav1.Duration{Duration: time.Minute}},
+	}}
+
+	_, err = validator.ValidateCreate(context.Background(), invalid)
+	assert.Error(t, err)
+
+	_, err = validator.ValidateUpdate(context.Background(), nodeGroup, invalid)
+	assert.ErrorContains(t, err, "triggerEndpoint")
+}
+
+func TestNodeGroupValidatorCredentialSecrets(t *testing.T) {
+	validator := &nodeGroupValidator{credentialSecrets: []string{"node-agent-auth"}}
+
+	nodeGroup := newNodeGroup()
+	nodeGroup.Spec.PreTerminationChecks = []v1.PreTerminationCheck{{
+		Endpoint:    "http://{{ .NodeIP }}:8080/drain",
+		HTTPRequest: v1.HTTPRequest{BearerTokenSecretRef: &v1.SecretKeyReference{Name: "node-agent-auth", Key: "token"}},
+		HealthCheck: v1.HealthCheck{Endpoint: "http://{{ .NodeIP }}:8080/drained", WaitPeriod: &metav1.Duration{Duration: time.Minute}},
+	}}
+	_, err := validator.ValidateCreate(context.Background(), nodeGroup)
+	assert.NoError(t, err)
+
+	// Secrets the operator hasn't allowed can't be referenced by the checks
+	nodeGroup.Spec.PreTerminationChecks[0].HealthCheck.BearerTokenSecretRef = &v1.SecretKeyReference{Name: "cloud-credentials", Key: "token"}
+	_, err = validator.ValidateCreate(context.Background(), nodeGroup)
+	assert.ErrorContains(t, err, "cloud-credentials")
+}
@@ -0,0 +1,101 @@
+// Package webhook provides the admission webhooks served by the manager, which default and validate
+// CycleNodeRequests and NodeGroups as they're applied rather than when they're reconciled, and the conversion
+// webhook which converts the resources between API versions.
+package webhook
+
+import (
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	"github.com/atlassian-labs/cyclops/pkg/controller"
+)
+
+// SetupWithManager registers the defaulting and validating webhooks for CycleNodeRequests and NodeGroups with the
+// webhook server of the manager. Registering a resource with more than one API version in the scheme of the manager
+// also serves the conversion webhook for it. Checks may only read request credentials from the credentialSecrets.
+func SetupWithManager(mgr manager.Manager, credentialSecrets []string) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.CycleNodeRequest{}).
+		WithDefaulter(&cycleNodeRequestDefaulter{}).
+		WithValidator(&cycleNodeRequestValidator{client: mgr.GetClient(), credentialSecrets: credentialSecrets}).
+		Complete(); err != nil {
+		return fmt.Errorf("registering CycleNodeRequest webhooks: %w", err)
+	}
+
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.NodeGroup{}).
+		WithDefaulter(&nodeGroupDefaulter{}).
+		WithValidator(&nodeGroupValidator{credentialSecrets: credentialSecrets}).
+		Complete(); err != nil {
+		return fmt.Errorf("registering NodeGroup webhooks: %w", err)
+	}
+
+	// CycleNodeStatuses are created by the controller, so they're only converted
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.CycleNodeStatus{}).
+		Complete(); err != nil {
+		return fmt.Errorf("registering CycleNodeStatus webhooks: %w", err)
+	}
+
+	return nil
+}
+
+// defaultCycleSettings sets the method to Drain if it isn't set. The concurrency isn't defaulted: the controller
+// defaults it to the number of nodes being cycled once cycling begins, and NodeGroups with a concurrency of 0 are
+// skipped when CycleNodeRequests are generated from them.
+func defaultCycleSettings(settings *v1.CycleSettings) {
+	if settings.Method == "" {
+		settings.Method = v1.CycleNodeRequestMethodDrain
+	}
+}
+
+// defaultValidStatusCodes sets the valid status codes of the checks to [200] if they aren't set, the same as the
+// controller does before performing them
+func defaultValidStatusCodes(healthChecks, clusterHealthChecks []v1.HealthCheck, preTerminationChecks []v1.PreTerminationCheck) {
+	for _, checks := range [][]v1.HealthCheck{healthChecks, clusterHealthChecks} {
+		for i := range checks {
+			if len(checks[i].ValidStatusCodes) == 0 {
+				checks[i].ValidStatusCodes = []uint{200}
+			}
+		}
+	}
+
+	for i := range preTerminationChecks {
+		if len(preTerminationChecks[i].ValidStatusCodes) == 0 {
+			preTerminationChecks[i].ValidStatusCodes = []uint{200}
+		}
+
+		if len(preTerminationChecks[i].HealthCheck.ValidStatusCodes) == 0 {
+			preTerminationChecks[i].HealthCheck.ValidStatusCodes = []uint{200}
+		}
+	}
+}
+
+// validateTLS validates the tls material held in the environment variables of the manager for each of the checks
+// and lifecycle hooks. Material referenced in Secrets and ConfigMaps is validated when the checks are performed.
+func validateTLS(healthChecks, clusterHealthChecks []v1.HealthCheck, preTerminationChecks []v1.PreTerminationCheck, lifecycleHooks []v1.LifecycleHook) error {
+	var tlsConfigs []v1.TLSConfig
+
+	for _, healthCheck := range append(append([]v1.HealthCheck{}, healthChecks...), clusterHealthChecks...) {
+		tlsConfigs = append(tlsConfigs, healthCheck.TLSConfig)
+	}
+
+	for _, preTerminationCheck := range preTerminationChecks {
+		tlsConfigs = append(tlsConfigs, preTerminationCheck.TLSConfig, preTerminationCheck.HealthCheck.TLSConfig)
+	}
+
+	for _, hook := range lifecycleHooks {
+		tlsConfigs = append(tlsConfigs, hook.TLSConfig)
+	}
+
+	for _, tlsConfig := range tlsConfigs {
+		if err := controller.ValidateTLSMaterial(controller.EnvTLSMaterial(tlsConfig)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+
+	"github.com/atlassian-labs/cyclops/pkg/apis"
+	v1 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v1"
+	v2 "github.com/atlassian-labs/cyclops/pkg/apis/atlassian/v2"
+)
+
+// buildWebhookCRDs builds the CRDs the same way as kubectl apply -k deploy/, which serves v2 through the conversion
+// webhook
+func buildWebhookCRDs(t *testing.T) []*apiextensionsv1.CustomResourceDefinition {
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).
+		Run(filesys.MakeFsOnDisk(), filepath.Join("..", "..", "deploy"))
+	require.NoError(t, err)
+
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	for _, resource := range resources.Resources() {
+		data, err := resource.AsYAML()
+		require.NoError(t, err)
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		require.NoError(t, yaml.Unmarshal(data, crd))
+		crds = append(crds, crd)
+	}
+
+	return crds
+}
+
+// readCRDs reads the CRDs applied by default, without the webhooks
+func readCRDs(t *testing.T) []*apiextensionsv1.CustomResourceDefinition {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "deploy", "crds", "*.yaml"))
+	require.NoError(t, err)
+
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		require.NoError(t, yaml.Unmarshal(data, crd))
+		crds = append(crds, crd)
+	}
+
+	return crds
+}
+
+// The default CRDs must work without the operator serving the conversion webhook, only the webhook CRDs serve v2
+func TestCRDVersions(t *testing.T) {
+	servedVersions := func(crd *apiextensionsv1.CustomResourceDefinition) []string {
+		var served []string
+		for _, version := range crd.Spec.Versions {
+			if version.Served {
+				served = append(served, version.Name)
+			}
+		}
+		return served
+	}
+
+	crds := readCRDs(t)
+	require.Len(t, crds, 3)
+	for _, crd := range crds {
+		assert.Equal(t, []string{"v1"}, servedVersions(crd), crd.Name)
+		assert.Nil(t, crd.Spec.Conversion, crd.Name)
+		assert.NotContains(t, crd.Annotations, "cert-manager.io/inject-ca-from", crd.Name)
+	}
+
+	webhookCRDs := buildWebhookCRDs(t)
+	require.Len(t, webhookCRDs, 3)
+	for _, crd := range webhookCRDs {
+		assert.Equal(t, []string{"v1", "v2"}, servedVersions(crd), crd.Name)
+		require.NotNil(t, crd.Spec.Conversion, crd.Name)
+		assert.Equal(t, apiextensionsv1.WebhookConverter, crd.Spec.Conversion.Strategy, crd.Name)
+		assert.Equal(t, "/convert", *crd.Spec.Conversion.Webhook.ClientConfig.Service.Path, crd.Name)
+		assert.Equal(t, "kube-system/cyclops-webhook", crd.Annotations["cert-manager.io/inject-ca-from"], crd.Name)
+	}
+}
+
+// TestWebhooksEnvtest applies CycleNodeRequests and NodeGroups to an apiserver which calls the webhooks configured
+// by the example deployment. It needs the envtest binaries, e.g. KUBEBUILDER_ASSETS="$(make -s setup-envtest)"
+func TestWebhooksEnvtest(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		// CI must run these tests, make test sets KUBEBUILDER_ASSETS
+		if os.Getenv("CI") != "" {
+			t.Fatal("KUBEBUILDER_ASSETS must be set in CI, run the tests with make test")
+		}
+		t.Skip("KUBEBUILDER_ASSETS isn't set, skipping the webhook tests against an apiserver")
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apis.AddToScheme(scheme))
+
+	// The scheme lets envtest point the conversion webhooks of the CRDs at the local webhook server
+	env := &envtest.Environment{
+		Scheme: scheme,
+		CRDs:   buildWebhookCRDs(t),
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "docs", "deployment", "cyclops-webhooks.yaml")},
+		},
+	}
+
+	cfg, err := env.Start()
+	require.NoError(t, err)
+	defer func() { _ = env.Stop() }()
+
+	webhookOptions := env.WebhookInstallOptions
+	mgr, err := manager.New(cfg, manager.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookOptions.LocalServingHost,
+			Port:    webhookOptions.LocalServingPort,
+			CertDir: webhookOptions.LocalServingCertDir,
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetupWithManager(mgr, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = mgr.Start(ctx) }()
+
+	// Wait for the webhook server to be serving before applying anything
+	address := net.JoinHostPort(webhookOptions.LocalServingHost, fmt.Sprint(webhookOptions.LocalServingPort))
+	require.Eventually(t, func() bool {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 10*time.Second, 100*time.Millisecond)
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+
+	t.Run("cycle node request is defaulted", func(t *testing.T) {
+		cnr := newCycleNodeRequest()
+		cnr.Name = "defaulted"
+		cnr.Namespace = metav1.NamespaceDefault
+		require.NoError(t, c.Create(ctx, cnr))
+
+		assert.Equal(t, v1.CycleNodeRequestMethod(v1.CycleNodeRequestMethodDrain), cnr.Spec.CycleSettings.Method)
+		assert.Equal(t, int64(0), cnr.Spec.CycleSettings.Concurrency)
+		assert.Equal(t, []uint{200}, cnr.Spec.HealthChecks[0].ValidStatusCodes)
+
+		// It would conflict with the CycleNodeRequests created below, which are validated against the cache
+		require.NoError(t, c.Delete(ctx, cnr))
+		require.Eventually(t, func() bool {
+			return apierrors.IsNotFound(mgr.GetCache().Get(ctx, client.ObjectKeyFromObject(cnr), &v1.CycleNodeRequest{}))
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("invalid cycle node request is rejected", func(t *testing.T) {
+		cnr := newCycleNodeRequest()
+		cnr.Name = "invalid"
+		cnr.Namespace = metav1.NamespaceDefault
+		cnr.Spec.HealthChecks[0].Endpoint = ""
+
+		err := c.Create(ctx, cnr)
+		assert.ErrorContains(t, err, "invalid CycleNodeRequest")
+	})
+
+	t.Run("cycle node request spec can't change after Pending", func(t *testing.T) {
+		cnr := newCycleNodeRequest()
+		cnr.Name = "immutable"
+		cnr.Namespace = metav1.NamespaceDefault
+		require.NoError(t, c.Create(ctx, cnr))
+
+		// Changing the spec is fine while Pending
+		cnr.Spec.CycleSettings.Method = v1.CycleNodeRequestMethodWait
+		cnr.Status.Phase = v1.CycleNodeRequestPending
+		require.NoError(t, c.Update(ctx, cnr))
+
+		// The controller moving it along is allowed
+		cnr.Status.Phase = v1.CycleNodeRequestInitialised
+		require.NoError(t, c.Update(ctx, cnr))
+
+		cnr.Spec.CycleSettings.Concurrency = 1
+		err := c.Update(ctx, cnr)
+		assert.ErrorContains(t, err, "left the Pending phase")
+	})
+
+	t.Run("cycle node request overlapping another is rejected", func(t *testing.T) {
+		cnr := newCycleNodeRequest()
+		cnr.Name = "overlapping"
+		cnr.Namespace = metav1.NamespaceDefault
+		cnr.Spec.NodeNames = []string{"system-node-1"}
+
+		require.Eventually(t, func() bool {
+			var immutable v1.CycleNodeRequest
+			err := mgr.GetCache().Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "immutable"}, &immutable)
+			return err == nil && immutable.Status.Phase == v1.CycleNodeRequestInitialised
+		}, 10*time.Second, 100*time.Millisecond)
+
+		err := c.Create(ctx, cnr)
+		assert.ErrorContains(t, err, "immutable (Initialised) on nodes system-node-1")
+	})
+
+	t.Run("invalid node group is rejected", func(t *testing.T) {
+		nodeGroup := newNodeGroup()
+		nodeGroup.Spec.ClusterHealthChecks = []v1.HealthCheck{{
+			Type:       v1.HealthCheckJob,
+			Job:        &v1.JobHealthCheck{Image: "busybox"},
+			WaitPeriod: &metav1.Duration{Duration: time.Minute},
+		}}
+
+		err := c.Create(ctx, nodeGroup)
+		assert.ErrorContains(t, err, "invalid NodeGroup")
+
+		nodeGroup.Spec.ClusterHealthChecks = nil
+		require.NoError(t, c.Create(ctx, nodeGroup))
+		assert.Equal(t, v1.CycleNodeRequestMethod(v1.CycleNodeRequestMethodDrain), nodeGroup.Spec.CycleSettings.Method)
+		assert.Equal(t, int64(0), nodeGroup.Spec.CycleSettings.Concurrency)
+	})
+
+	t.Run("node group is converted between versions", func(t *testing.T) {
+		var nodeGroup v2.NodeGroup
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "system"}, &nodeGroup))
+		assert.Equal(t, []string{"system"}, nodeGroup.Spec.NodeGroupNames)
+
+		nodeGroup.Spec.NodeGroupNames = []string{"system", "system-extra"}
+		require.NoError(t, c.Update(ctx, &nodeGroup))
+
+		var stored v1.NodeGroup
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "system"}, &stored))
+		assert.Equal(t, "system", stored.Spec.NodeGroupName)
+		assert.Equal(t, []string{"system-extra"}, stored.Spec.NodeGroupsList)
+	})
+}